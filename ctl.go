@@ -0,0 +1,132 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+
+	"httpbench/pkg/control"
+	"httpbench/pkg/reporter"
+)
+
+// runCtl 通过控制API调整正在运行的测试
+//
+//	httpbench ctl -addr 127.0.0.1:9091 concurrency 50
+func runCtl(args []string) error {
+	fs := flag.NewFlagSet("ctl", flag.ExitOnError)
+	addr := fs.String("addr", control.DefaultAddr, "控制服务地址")
+	format := fs.String("output", "console", "snapshot输出格式: console, json, csv, junit, markdown")
+	reportPath := fs.String("report", "", "snapshot报告输出文件")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "用法: httpbench ctl [选项] <命令> [参数]\n\n")
+		fmt.Fprintf(fs.Output(), "命令:\n")
+		fmt.Fprintf(fs.Output(), "  status             查看运行状态\n")
+		fmt.Fprintf(fs.Output(), "  pause | resume     暂停/恢复生成新请求\n")
+		fmt.Fprintf(fs.Output(), "  stop               优雅停止\n")
+		fmt.Fprintf(fs.Output(), "  next               进入下一阶段\n")
+		fmt.Fprintf(fs.Output(), "  concurrency <n>    调整并发数\n")
+		fmt.Fprintf(fs.Output(), "  rate <rps>         调整目标速率(0表示不限速)\n")
+		fmt.Fprintf(fs.Output(), "  snapshot           获取当前测试结果快照\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return fmt.Errorf("缺少命令")
+	}
+
+	// 命令之后同样允许出现选项
+	command := fs.Arg(0)
+	fs.Parse(fs.Args()[1:])
+
+	client := control.NewClient(*addr)
+
+	// intArg 解析整数参数
+	intArg := func() (int, error) {
+		if fs.NArg() < 1 {
+			return 0, fmt.Errorf("%s 需要一个整数参数", command)
+		}
+		n, err := strconv.Atoi(fs.Arg(0))
+		if err != nil {
+			return 0, fmt.Errorf("无效的参数 %q: %w", fs.Arg(0), err)
+		}
+		return n, nil
+	}
+
+	var status *control.Status
+	var err error
+
+	switch command {
+	case "status":
+		status, err = client.Status()
+	case "pause":
+		status, err = client.Pause()
+	case "resume":
+		status, err = client.Resume()
+	case "stop":
+		status, err = client.Stop()
+	case "next", "next-stage":
+		status, err = client.NextStage()
+	case "concurrency", "c":
+		var n int
+		if n, err = intArg(); err == nil {
+			status, err = client.SetConcurrency(n)
+		}
+	case "rate", "rps":
+		var n int
+		if n, err = intArg(); err == nil {
+			status, err = client.SetRate(n)
+		}
+	case "snapshot":
+		results, err := client.Snapshot()
+		if err != nil {
+			return err
+		}
+		rep := reporter.New(*format)
+		if err := rep.Generate(results, *reportPath); err != nil {
+			return fmt.Errorf("生成报告失败: %w", err)
+		}
+		if *format == "console" || *reportPath != "" {
+			printSummary(results)
+		}
+		return nil
+	default:
+		fs.Usage()
+		return fmt.Errorf("未知命令: %s", command)
+	}
+
+	if err != nil {
+		return err
+	}
+	printStatus(status)
+	return nil
+}
+
+// printStatus 打印运行状态
+func printStatus(status *control.Status) {
+	state := "运行中"
+	switch {
+	case status.Done:
+		state = "已结束"
+	case status.Paused:
+		state = "已暂停"
+	}
+
+	targetRate := "不限速"
+	if status.TargetRate > 0 {
+		targetRate = fmt.Sprintf("%.0f req/s", status.TargetRate)
+	}
+
+	elapsed := fmt.Sprintf("%.0fs", status.Elapsed)
+	if status.Planned > 0 {
+		elapsed += fmt.Sprintf(" / %.0fs", status.Planned)
+	}
+
+	fmt.Printf("端点:         %s\n", status.Endpoint)
+	fmt.Printf("状态:         %s (阶段: %s)\n", state, status.Stage)
+	fmt.Printf("已运行:       %s\n", elapsed)
+	fmt.Printf("并发:         %d (活跃 %d, 进行中请求 %d)\n", status.Concurrency, status.ActiveWorkers, status.InFlight)
+	fmt.Printf("目标速率:     %s\n", targetRate)
+	fmt.Printf("请求:         %d (成功 %d, 失败 %d)\n", status.TotalRequests, status.SuccessRequests, status.FailedRequests)
+}
@@ -7,12 +7,19 @@ import (
 	"log"
 	"os"
 	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
 	"httpbench/pkg/benchmark"
 	"httpbench/pkg/config"
+	"httpbench/pkg/control"
+	"httpbench/pkg/metrics"
 	"httpbench/pkg/reporter"
+	"httpbench/pkg/tui"
+	"httpbench/pkg/webui"
 )
 
 var (
@@ -24,14 +31,75 @@ var (
 	rps          = flag.Int("rps", 0, "每秒请求数限制(0表示无限制)")
 	http2        = flag.Bool("http2", false, "启用HTTP/2")
 	http3        = flag.Bool("http3", false, "启用HTTP/3 (QUIC)")
-	outputFormat = flag.String("output", "console", "输出格式: console, json, csv")
+	engine       = flag.String("engine", "", "请求引擎: std, raw (精简HTTP/1.1)")
+	stream       = flag.Bool("stream", false, "按事件读取流式响应(SSE、NDJSON)")
+	outputFormat = flag.String("output", "console", "输出格式: console, json, csv, junit, markdown")
 	reportFile   = flag.String("report", "", "报告输出文件")
 	distributed  = flag.Bool("distributed", false, "分布式模式")
 	masterAddr   = flag.String("master", "", "主节点地址(分布式模式)")
 	workerMode   = flag.Bool("worker", false, "作为工作节点运行")
+	metricsAddr  = flag.String("metrics-addr", "", "Prometheus指标监听地址(如 :9090)")
+	rawLogFile   = flag.String("raw-log", "", "逐请求原始结果文件(.csv, .ndjson 或 .bin)")
+	dashboard    = flag.Bool("tui", false, "全屏终端仪表盘")
+	uiAddr       = flag.String("ui", "", "浏览器实时仪表盘监听地址(如 :8089)")
+	controlAddr  = flag.String("control-addr", "", "运行控制API监听地址(如 127.0.0.1:9091)")
+	unixSocket   = flag.String("unix-socket", "", "通过Unix套接字连接目标")
+	proxyURL     = flag.String("proxy", "", "代理地址(http://, https:// 或 socks5://, 可含用户名密码)")
+	maxConns     = flag.Int("max-conns", 0, "连接总数上限(0表示不限制)")
+	maxConnsHost = flag.Int("max-conns-per-host", 0, "每个主机的连接数上限(0表示不限制)")
+	maxConnReqs  = flag.Int("max-requests-per-conn", 0, "每个连接的请求数上限, 达到后关闭(0表示不限制)")
+	maxConnAge   = flag.Duration("max-conn-age", 0, "连接存活时间上限, 达到后关闭(0表示不限制)")
+	balance      = flag.String("balance", "", "后端主机选择策略: round_robin, random, weighted, least_outstanding")
+	maxAttempts  = flag.Int("max-attempts", 0, "每个请求最多尝试次数, 含第一次(0或1表示不重试)")
+	retryBackoff = flag.Duration("retry-backoff", 0, "第一次重试前的等待时间, 之后按指数增长")
+
+	resolve     stringList
+	connectTo   stringList
+	sourceAddrs stringList
+	hosts       stringList
 )
 
+func init() {
+	flag.Var(&resolve, "resolve", "将 host:port 解析到指定地址(host:port:addr, 可重复)")
+	flag.Var(&connectTo, "connect-to", "将 host:port 的连接改发到其他地址(host:port:connect_host:connect_port, 可重复)")
+	flag.Var(&sourceAddrs, "source-addr", "绑定的本地源地址(可重复, 新连接轮流使用)")
+	flag.Var(&hosts, "host", "后端主机, 替换URL中的主机(host:port 或 scheme://host:port, 可加 =权重, 可重复)")
+}
+
+// stringList 可重复的字符串参数
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
 func main() {
+	// 子命令
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "report":
+			if err := runReport(os.Args[2:]); err != nil {
+				log.Fatalf("生成报告失败: %v", err)
+			}
+			return
+		case "ctl":
+			if err := runCtl(os.Args[2:]); err != nil {
+				log.Fatalf("控制命令失败: %v", err)
+			}
+			return
+		case "serve":
+			if err := runServe(os.Args[2:]); err != nil {
+				log.Fatalf("模拟目标服务失败: %v", err)
+			}
+			return
+		}
+	}
+
 	flag.Parse()
 
 	// 加载配置
@@ -45,20 +113,12 @@ func main() {
 		log.Fatalf("配置验证失败: %v", err)
 	}
 
-	// 创建上下文和信号处理
+	// 创建上下文 (信号处理在基准测试器创建后设置)
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
-	go func() {
-		<-sigChan
-		log.Println("收到中断信号,正在优雅退出...")
-		cancel()
-	}()
-
 	// 运行基准测试
-	if err := runBenchmark(ctx, cfg); err != nil {
+	if err := runBenchmark(ctx, cancel, cfg); err != nil {
 		log.Fatalf("基准测试执行失败: %v", err)
 	}
 }
@@ -98,6 +158,62 @@ func loadConfig() (*config.Config, error) {
 	if *http3 {
 		cfg.Protocol.HTTP3Enabled = true
 	}
+	if *engine != "" {
+		cfg.Protocol.Engine = config.Engine(*engine)
+	}
+	if *stream {
+		cfg.Protocol.Stream.Enabled = true
+	}
+	if *unixSocket != "" {
+		cfg.Target.UnixSocket = *unixSocket
+	}
+	if *proxyURL != "" {
+		cfg.Protocol.Proxy.URL = *proxyURL
+	}
+	if len(resolve) > 0 {
+		cfg.Target.Resolve = resolve
+	}
+	if len(connectTo) > 0 {
+		cfg.Target.ConnectTo = connectTo
+	}
+	if len(sourceAddrs) > 0 {
+		cfg.Target.SourceAddrs = sourceAddrs
+	}
+	if len(hosts) > 0 {
+		cfg.Target.Hosts = nil
+		for _, h := range hosts {
+			host := config.TargetHost{Address: h}
+			if i := strings.LastIndexByte(h, '='); i >= 0 {
+				weight, err := strconv.Atoi(h[i+1:])
+				if err != nil {
+					log.Fatalf("无效的主机权重: %s", h)
+				}
+				host = config.TargetHost{Address: h[:i], Weight: weight}
+			}
+			cfg.Target.Hosts = append(cfg.Target.Hosts, host)
+		}
+	}
+	if *maxConns > 0 {
+		cfg.Protocol.Pool.MaxConnections = *maxConns
+	}
+	if *maxConnsHost > 0 {
+		cfg.Protocol.Pool.MaxConnectionsPerHost = *maxConnsHost
+	}
+	if *maxConnReqs > 0 {
+		cfg.Protocol.Pool.MaxRequestsPerConnection = *maxConnReqs
+	}
+	if *maxConnAge > 0 {
+		cfg.Protocol.Pool.MaxConnectionAge = *maxConnAge
+	}
+	if *balance != "" {
+		cfg.Target.Balance = config.BalanceStrategy(*balance)
+	}
+	if *maxAttempts > 0 {
+		cfg.Request.Retry.MaxAttempts = *maxAttempts
+	}
+	if *retryBackoff > 0 {
+		cfg.Request.Retry.Backoff = *retryBackoff
+	}
 	if *outputFormat != "" {
 		cfg.Output.Format = *outputFormat
 	}
@@ -113,11 +229,26 @@ func loadConfig() (*config.Config, error) {
 	if *workerMode {
 		cfg.Distributed.WorkerMode = true
 	}
+	if *metricsAddr != "" {
+		cfg.Output.MetricsAddr = *metricsAddr
+	}
+	if *rawLogFile != "" {
+		cfg.Output.RawLog.File = *rawLogFile
+	}
+	if *dashboard {
+		cfg.Output.Dashboard = true
+	}
+	if *uiAddr != "" {
+		cfg.Output.UIAddr = *uiAddr
+	}
+	if *controlAddr != "" {
+		cfg.Output.ControlAddr = *controlAddr
+	}
 
 	return cfg, nil
 }
 
-func runBenchmark(ctx context.Context, cfg *config.Config) error {
+func runBenchmark(ctx context.Context, cancel context.CancelFunc, cfg *config.Config) error {
 	fmt.Printf("🚀 HTTP 基准测试工具 v1.0\n")
 	fmt.Printf("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
 	fmt.Printf("目标: %s\n", cfg.Target.URL)
@@ -139,11 +270,65 @@ func runBenchmark(ctx context.Context, cfg *config.Config) error {
 	}
 	defer bench.Close()
 
+	// Prometheus指标
+	if cfg.Output.MetricsAddr != "" {
+		metricsServer := metrics.NewServer(cfg.Output.MetricsAddr, bench)
+		if err := metricsServer.Start(); err != nil {
+			return err
+		}
+		defer metricsServer.Close()
+		fmt.Printf("📡 Prometheus指标: http://%s/metrics\n", metricsServer.Addr())
+	}
+
+	// 浏览器实时仪表盘
+	if cfg.Output.UIAddr != "" {
+		uiServer := webui.NewServer(cfg.Output.UIAddr, bench)
+		if err := uiServer.Start(); err != nil {
+			return err
+		}
+		defer uiServer.Close()
+		fmt.Printf("🖥️  实时仪表盘: http://%s/\n", uiServer.Addr())
+	}
+
+	// 运行控制API
+	if cfg.Output.ControlAddr != "" {
+		controlServer := control.NewServer(cfg.Output.ControlAddr, bench)
+		if err := controlServer.Start(); err != nil {
+			return err
+		}
+		defer controlServer.Close()
+		fmt.Printf("🎛️  控制API: http://%s/v1/status (httpbench ctl -addr %s ...)\n",
+			controlServer.Addr(), controlServer.Addr())
+	}
+
+	// 终端仪表盘
+	var dash *tui.Dashboard
+	if cfg.Output.Dashboard {
+		if tui.IsTerminal(os.Stdout) {
+			cfg.Output.RealtimeMonitor = false
+			dash = tui.New(bench, cfg, tui.Options{OnForceQuit: cancel})
+		} else {
+			fmt.Println("⚠️  标准输出不是终端, 使用逐行实时监控")
+			cfg.Output.RealtimeMonitor = true
+		}
+	}
+
+	// 信号处理
+	handleSignals(bench, dash)
+
 	// 执行测试
 	fmt.Println("⏳ 开始测试...")
 	startTime := time.Now()
 
+	if dash != nil {
+		if err := dash.Start(); err != nil {
+			return err
+		}
+	}
 	results, err := bench.Run(ctx)
+	if dash != nil {
+		dash.Close()
+	}
 	if err != nil {
 		return fmt.Errorf("执行测试失败: %w", err)
 	}
@@ -165,9 +350,62 @@ func runBenchmark(ctx context.Context, cfg *config.Config) error {
 	return nil
 }
 
+// handleSignals 第一次中断停止生成新请求并排空进行中的请求,第二次立即退出; SIGUSR1 输出中间快照
+func handleSignals(bench *benchmark.Benchmark, dash *tui.Dashboard) {
+	sigChan := make(chan os.Signal, 2)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sigChan
+		log.Println("收到中断信号, 停止发送新请求并等待进行中的请求完成 (再次中断立即退出)...")
+		bench.Stop()
+
+		<-sigChan
+		if dash != nil {
+			dash.Close()
+		}
+		log.Println("再次收到中断信号, 立即退出")
+		// 退出前写完原始结果文件并推送已缓冲的区间指标
+		if err := bench.CloseOutputs(); err != nil {
+			log.Printf("关闭输出失败: %v", err)
+		}
+		os.Exit(130)
+	}()
+
+	if len(snapshotSignals) == 0 {
+		return
+	}
+	snapChan := make(chan os.Signal, 1)
+	signal.Notify(snapChan, snapshotSignals...)
+	go func() {
+		for range snapChan {
+			// 仪表盘独占终端时保存为快照文件
+			if dash != nil {
+				dash.SaveSnapshot()
+				continue
+			}
+			fmt.Printf("\n📸 中间快照\n")
+			printSummary(bench.Snapshot())
+			fmt.Println()
+		}
+	}()
+}
+
 func printSummary(results *benchmark.Results) {
 	fmt.Printf("📊 测试结果摘要\n")
 	fmt.Printf("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+	if results.Partial {
+		fmt.Printf("⚠️  部分结果: 截至 %v, 测试未完整运行", results.Duration.Round(time.Millisecond))
+		if results.Canceled > 0 {
+			fmt.Printf(", %d 个进行中的请求被取消且不计入统计", results.Canceled)
+		}
+		fmt.Printf("\n")
+	}
+	if results.Client != nil && results.Client.Saturated {
+		fmt.Printf("🚨 压测客户端可能是瓶颈, 以下结果可能反映压测机而非目标服务:\n")
+		for _, warning := range results.Client.Warnings {
+			fmt.Printf("   - %s\n", warning)
+		}
+	}
 	fmt.Printf("总请求数:     %d\n", results.TotalRequests)
 	fmt.Printf("成功请求:     %d\n", results.SuccessRequests)
 	fmt.Printf("失败请求:     %d\n", results.FailedRequests)
@@ -202,6 +440,14 @@ func printSummary(results *benchmark.Results) {
 	}
 	fmt.Printf("接收速率:     %s/s\n", formatBytes(receiveRate))
 
+	if len(results.ProtocolStats) > 0 {
+		printProtocolStats(results.Protocol, results.ProtocolStats)
+	}
+
+	if results.Client != nil {
+		printClientStats(results.Client)
+	}
+
 	if len(results.ErrorsByType) > 0 {
 		fmt.Printf("\n")
 		fmt.Printf("❌ 错误统计\n")
@@ -212,6 +458,43 @@ func printSummary(results *benchmark.Results) {
 	}
 }
 
+// printProtocolStats 打印协议特定的统计
+func printProtocolStats(protocol string, protocolStats map[string]float64) {
+	fmt.Printf("\n")
+	fmt.Printf("🔌 协议统计 (%s)\n", protocol)
+	fmt.Printf("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+	names := make([]string, 0, len(protocolStats))
+	for name := range protocolStats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Printf("%-20s: %g\n", name, protocolStats[name])
+	}
+}
+
+// printClientStats 打印压测客户端自身资源使用
+func printClientStats(cs *benchmark.ClientStats) {
+	fmt.Printf("\n")
+	fmt.Printf("🖥️  客户端资源\n")
+	fmt.Printf("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+	if cs.CPUPercent >= 0 {
+		fmt.Printf("CPU:          %.1f%% (峰值 %.1f%%, GOMAXPROCS=%d)\n", cs.CPUPercent, cs.PeakCPUPercent, cs.GOMAXPROCS)
+	}
+	fmt.Printf("GC:           %d 次, 暂停共 %v (最长 %v)\n", cs.NumGC,
+		cs.GCPauseTotal.Round(time.Microsecond), cs.GCPauseMax.Round(time.Microsecond))
+	fmt.Printf("协程峰值:     %d\n", cs.PeakGoroutines)
+	if cs.PeakOpenFDs >= 0 {
+		fmt.Printf("文件描述符:   %d (上限 %d)\n", cs.PeakOpenFDs, cs.FDLimit)
+	}
+	if cs.SchedSamples > 0 {
+		fmt.Printf("调度延迟:     平均 %v, 最大 %v, 迟发 %d/%d\n", cs.SchedLagMean.Round(time.Microsecond),
+			cs.SchedLagMax.Round(time.Microsecond), cs.LateRequests, cs.SchedSamples)
+		fmt.Printf("排队等待:     平均 %v, 最大 %v\n", cs.QueueWaitMean.Round(time.Microsecond),
+			cs.QueueWaitMax.Round(time.Microsecond))
+	}
+}
+
 func formatBytes(bytes int64) string {
 	const unit = 1024
 	if bytes < unit {
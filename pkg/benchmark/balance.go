@@ -0,0 +1,178 @@
+package benchmark
+
+import (
+	"crypto/tls"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"httpbench/pkg/config"
+)
+
+// hostPool 后端主机池, 每个请求按策略选择一个主机
+type hostPool struct {
+	hosts    []*poolHost
+	strategy config.BalanceStrategy
+	next     atomic.Uint64
+
+	// 目标 URL 的主机名, 作为连接各后端时的 SNI
+	serverName string
+
+	// 平滑加权轮询的状态
+	mu          sync.Mutex
+	totalWeight int
+}
+
+// poolHost 池中的主机
+type poolHost struct {
+	scheme string
+	host   string
+
+	weight  int
+	current int
+
+	// 进行中的请求数
+	outstanding atomic.Int64
+
+	// 端点统计名称及其后缀
+	endpoint string
+	suffix   string
+}
+
+// newHostPool 解析主机池, 未配置主机时返回 nil
+func newHostPool(cfg *config.Config) (*hostPool, error) {
+	target := cfg.Target
+	if len(target.Hosts) == 0 {
+		return nil, nil
+	}
+
+	method := target.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	u, err := url.Parse(target.URL)
+	if err != nil {
+		return nil, fmt.Errorf("无效的目标地址: %w", err)
+	}
+
+	p := &hostPool{strategy: target.Balance, serverName: u.Hostname()}
+	for _, th := range target.Hosts {
+		h := &poolHost{host: th.Address, weight: max(th.Weight, 1)}
+		if strings.Contains(th.Address, "://") {
+			u, err := url.Parse(th.Address)
+			if err != nil || u.Host == "" {
+				return nil, fmt.Errorf("无效的后端主机: %s", th.Address)
+			}
+			h.scheme, h.host = u.Scheme, u.Host
+		}
+		h.suffix = " @ " + h.host
+		h.endpoint = method + " " + target.URL + h.suffix
+		p.hosts = append(p.hosts, h)
+		p.totalWeight += h.weight
+	}
+	return p, nil
+}
+
+// tlsConfig 连接后端的 TLS 配置: SNI 和证书校验使用目标 URL 的主机名而不是后端地址,
+// 已指定 ServerName 或池为 nil 时原样返回
+func (p *hostPool) tlsConfig(base *tls.Config) *tls.Config {
+	if p == nil || base != nil && base.ServerName != "" {
+		return base
+	}
+	cfg := &tls.Config{}
+	if base != nil {
+		cfg = base.Clone()
+	}
+	cfg.ServerName = p.serverName
+	return cfg
+}
+
+// acquire 选择主机并计为进行中, 请求结束后调用 release; 池为 nil 时返回 nil
+func (p *hostPool) acquire() *poolHost {
+	if p == nil {
+		return nil
+	}
+
+	var h *poolHost
+	switch p.strategy {
+	case config.BalanceRandom:
+		h = p.hosts[rand.Intn(len(p.hosts))]
+	case config.BalanceWeighted:
+		h = p.weighted()
+	case config.BalanceLeastOutstanding:
+		h = p.leastOutstanding()
+	default:
+		h = p.hosts[(p.next.Add(1)-1)%uint64(len(p.hosts))]
+	}
+	h.outstanding.Add(1)
+	return h
+}
+
+// weighted 平滑加权轮询: 每次给所有主机加上各自权重, 选择当前值最大者并减去总权重
+func (p *hostPool) weighted() *poolHost {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var best *poolHost
+	for _, h := range p.hosts {
+		h.current += h.weight
+		if best == nil || h.current > best.current {
+			best = h
+		}
+	}
+	best.current -= p.totalWeight
+	return best
+}
+
+// leastOutstanding 进行中请求最少的主机, 从轮转的起点开始比较以分散并列的情况
+func (p *hostPool) leastOutstanding() *poolHost {
+	n := len(p.hosts)
+	start := int((p.next.Add(1) - 1) % uint64(n))
+	best := p.hosts[start]
+	bestCount := best.outstanding.Load()
+	for i := 1; i < n && bestCount > 0; i++ {
+		h := p.hosts[(start+i)%n]
+		if c := h.outstanding.Load(); c < bestCount {
+			best, bestCount = h, c
+		}
+	}
+	return best
+}
+
+// release 请求结束
+func (h *poolHost) release() {
+	if h != nil {
+		h.outstanding.Add(-1)
+	}
+}
+
+// route 将请求的连接发往该主机并返回端点统计名称, endpoint 为执行器给出的端点名称
+//
+// 只改变连接的去向: Host 头仍是目标 URL 的主机, SNI 由 tlsConfig 固定为目标主机名。
+// 改写 URL 的主机使传输层按后端分别复用连接。
+func (h *poolHost) route(req *http.Request, endpoint string) string {
+	if h == nil {
+		return endpoint
+	}
+
+	if req.Host == "" {
+		req.Host = req.URL.Host
+	}
+	// 原型请求的 URL 是共享的, 需要复制后修改
+	u := *req.URL
+	if h.scheme != "" {
+		u.Scheme = h.scheme
+	}
+	u.Host = h.host
+	req.URL = &u
+
+	if endpoint == "" {
+		return h.endpoint
+	}
+	return endpoint + h.suffix
+}
@@ -0,0 +1,162 @@
+package benchmark
+
+import (
+	"encoding/pem"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"httpbench/pkg/config"
+)
+
+// TestHostPool 测试多个目标主机的负载均衡策略、按主机的端点统计和 HTTPS 后端的 Host 与 SNI
+func TestHostPool(t *testing.T) {
+	var counts [3]atomic.Int64
+	var badHost atomic.Int64
+	var servers []*httptest.Server
+	for i := range counts {
+		i := i
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			counts[i].Add(1)
+			if r.URL.Path != "/api" || r.Host != "svc.test" {
+				badHost.Add(1)
+			}
+			// 第一个主机明显更慢
+			if i == 0 {
+				time.Sleep(20 * time.Millisecond)
+			}
+			io.WriteString(w, "ok")
+		}))
+		defer server.Close()
+		servers = append(servers, server)
+	}
+
+	run := func(balance config.BalanceStrategy, weights []int, total int) *Results {
+		t.Helper()
+		for i := range counts {
+			counts[i].Store(0)
+		}
+		cfg := &config.Config{
+			Target: config.TargetConfig{URL: "http://svc.test/api", Timeout: 2 * time.Second, Balance: balance},
+			Load:   config.LoadConfig{Concurrency: 6, TotalRequests: total},
+			Protocol: config.ProtocolConfig{
+				KeepAlive: true,
+			},
+		}
+		for i, server := range servers {
+			host := config.TargetHost{Address: server.Listener.Addr().String()}
+			if i == 2 {
+				host.Address = server.URL
+			}
+			if weights != nil {
+				host.Weight = weights[i]
+			}
+			cfg.Target.Hosts = append(cfg.Target.Hosts, host)
+		}
+		if err := cfg.Validate(); err != nil {
+			t.Fatalf("配置无效: %v", err)
+		}
+		results := runBenchmark(t, cfg)
+		if results.SuccessRequests != int64(total) {
+			t.Errorf("%s: 成功 %d, 错误 %v", balance, results.SuccessRequests, results.ErrorsByType)
+		}
+		return results
+	}
+	endpoint := func(i int) string {
+		return "GET http://svc.test/api @ " + servers[i].Listener.Addr().String()
+	}
+
+	// 轮询: 每个主机相同数量, 端点统计按主机区分
+	results := run(config.BalanceRoundRobin, nil, 30)
+	for i := range servers {
+		if counts[i].Load() != 10 || results.Endpoints[endpoint(i)].TotalRequests != 10 {
+			t.Errorf("轮询: 主机 %d 收到 %d, 端点 %v", i, counts[i].Load(), results.Endpoints)
+		}
+	}
+	if slow, fast := results.Endpoints[endpoint(0)].Latency.P50, results.Endpoints[endpoint(1)].Latency.P50; slow <= fast {
+		t.Errorf("慢主机的延迟应更高: %v <= %v", slow, fast)
+	}
+	if badHost.Load() != 0 {
+		t.Errorf("路径和 Host 不应改变")
+	}
+
+	// 加权: 按权重精确分配
+	run(config.BalanceWeighted, []int{1, 2, 3}, 60)
+	for i, want := range []int64{10, 20, 30} {
+		if counts[i].Load() != want {
+			t.Errorf("加权: 主机 %d 收到 %d, 期望 %d", i, counts[i].Load(), want)
+		}
+	}
+
+	// 随机: 每个主机都会被选中
+	run(config.BalanceRandom, nil, 120)
+	for i := range servers {
+		if counts[i].Load() == 0 {
+			t.Errorf("随机: 主机 %d 未被选中", i)
+		}
+	}
+
+	// 最少进行中: 慢主机分到的请求更少
+	run(config.BalanceLeastOutstanding, nil, 120)
+	if counts[0].Load() >= counts[1].Load() || counts[0].Load() >= counts[2].Load() {
+		t.Errorf("最少进行中: 分配 %d %d %d", counts[0].Load(), counts[1].Load(), counts[2].Load())
+	}
+
+	// 平滑加权轮询的顺序
+	pool, err := newHostPool(&config.Config{Target: config.TargetConfig{
+		URL:     "http://svc.test/",
+		Balance: config.BalanceWeighted,
+		Hosts:   []config.TargetHost{{Address: "a", Weight: 5}, {Address: "b", Weight: 1}, {Address: "c", Weight: 1}},
+	}})
+	if err != nil {
+		t.Fatalf("创建主机池失败: %v", err)
+	}
+	var order string
+	for i := 0; i < 7; i++ {
+		h := pool.acquire()
+		order += h.host
+		h.release()
+	}
+	if order != "aabacaa" {
+		t.Errorf("加权顺序 %s", order)
+	}
+
+	// HTTPS 后端: Host 和 SNI 仍是目标主机名, 按目标主机名校验证书
+	var gotHost, gotSNI atomic.Value
+	tlsServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHost.Store(r.Host)
+		gotSNI.Store(r.TLS.ServerName)
+	}))
+	defer tlsServer.Close()
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: tlsServer.Certificate().Raw})
+	if err := os.WriteFile(caFile, certPEM, 0o600); err != nil {
+		t.Fatalf("写入证书失败: %v", err)
+	}
+	_, port, _ := net.SplitHostPort(tlsServer.Listener.Addr().String())
+	for _, engine := range []config.Engine{config.EngineStandard, config.EngineRaw} {
+		cfg := &config.Config{
+			Target: config.TargetConfig{
+				URL:     "https://example.com:" + port + "/",
+				Timeout: 2 * time.Second,
+				Hosts:   []config.TargetHost{{Address: tlsServer.Listener.Addr().String()}},
+			},
+			Load:     config.LoadConfig{Concurrency: 1, TotalRequests: 2},
+			Protocol: config.ProtocolConfig{KeepAlive: true, Engine: engine},
+			TLS:      config.TLSConfig{Enabled: true, CAFile: caFile},
+		}
+		results := runBenchmark(t, cfg)
+		if results.SuccessRequests != 2 {
+			t.Errorf("%s: HTTPS 后端成功 %d, 错误 %v", engine, results.SuccessRequests, results.ErrorsByType)
+		}
+		if gotHost.Load() != "example.com:"+port || gotSNI.Load() != "example.com" {
+			t.Errorf("%s: Host %v, SNI %v", engine, gotHost.Load(), gotSNI.Load())
+		}
+	}
+}
@@ -3,37 +3,68 @@ package benchmark
 import (
 	"context"
 	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"io"
 	"net/http"
-	"strings"
+	"net/http/httptrace"
+	"os"
 	"sync"
 	"sync/atomic"
 	"time"
 
-	"github.com/quic-go/quic-go/http3"
-	"golang.org/x/net/http2"
-
 	"httpbench/pkg/config"
+	"httpbench/pkg/rawlog"
+	"httpbench/pkg/sink"
 	"httpbench/pkg/stats"
-	"httpbench/pkg/template"
-	"httpbench/pkg/validator"
 )
 
 // Benchmark 基准测试器
 type Benchmark struct {
-	config    *config.Config
-	client    *http.Client
-	stats     *stats.Collector
-	validator *validator.Validator
-	template  *template.Engine
+	config   *config.Config
+	executor Executor
+	protocol string
+	stats    *stats.Collector
+
+	// 端点标识 (用于按端点统计)
+	endpoint string
 
 	// 状态管理
-	running   atomic.Bool
-	startTime time.Time
+	running       atomic.Bool
+	startTime     time.Time
+	inFlight      atomic.Int64
+	activeWorkers atomic.Int64
+	canceled      atomic.Int64
+	interrupted   atomic.Bool
+
+	// 运行控制
+	gate           pauseGate
+	stage          atomic.Value
+	controlMu      sync.Mutex
+	stopGeneration context.CancelFunc
+	stopRequested  bool
+	logOutput      io.Writer
+	pool           *workerPool
+	nextStage      chan struct{}
+	hasNextStage   atomic.Bool
 
 	// 速率限制
 	rateLimiter *RateLimiter
+
+	// 客户端自身资源监控, Run 开始时创建
+	selfMon atomic.Pointer[selfMonitor]
+
+	// 采样回调
+	intervalHandlers []IntervalHandler
+
+	// 区间指标推送
+	sinks *sink.Dispatcher
+
+	// 逐请求原始结果
+	rawLog *rawlog.Writer
+
+	outputsOnce sync.Once
+	outputsErr  error
 }
 
 // Results 测试结果
@@ -51,39 +82,87 @@ type Results struct {
 	Latency      stats.LatencyStats
 	ErrorsByType map[string]int64
 	StatusCodes  map[int]int64
+	Endpoints    map[string]stats.EndpointStats
+
+	// 阈值检查结果
+	Thresholds []ThresholdResult
+
+	// 测试被中断 (信号、停止命令或取消) 时为部分结果
+	Partial bool
+	// 排空超时或取消时被放弃的请求数 (不计入统计)
+	Canceled int64
+
+	// 压测客户端自身的资源使用, 由原始结果重建时为 nil
+	Client *ClientStats
+
+	// 协议及协议特定的统计
+	Protocol      string
+	ProtocolStats map[string]float64
 
 	// 时间序列数据
 	TimeSeries []stats.TimePoint
 }
 
+// ThresholdResult 阈值检查结果
+type ThresholdResult struct {
+	Name    string
+	Limit   string
+	Actual  string
+	Passed  bool
+	Message string
+}
+
 // New 创建基准测试器
 func New(cfg *config.Config) (*Benchmark, error) {
-	// 创建HTTP客户端
-	client, err := createHTTPClient(cfg)
+	// 按协议创建执行器
+	executor, err := newExecutor(cfg)
 	if err != nil {
-		return nil, fmt.Errorf("创建HTTP客户端失败: %w", err)
+		return nil, fmt.Errorf("创建执行器失败: %w", err)
 	}
+	return NewWithExecutor(cfg, executor)
+}
 
+// NewWithExecutor 使用自定义执行器创建基准测试器, 执行器由基准测试器负责关闭
+func NewWithExecutor(cfg *config.Config, executor Executor) (*Benchmark, error) {
 	// 创建统计收集器
 	statsCollector := stats.NewCollector()
 
-	// 创建验证器
-	val := validator.New(cfg.Validation)
-
-	// 创建模板引擎
-	tmpl := template.New(cfg.Request.Template)
+	method := cfg.Target.Method
+	if method == "" {
+		method = http.MethodGet
+	}
 
 	b := &Benchmark{
 		config:    cfg,
-		client:    client,
+		executor:  executor,
+		protocol:  protocolName(cfg),
 		stats:     statsCollector,
-		validator: val,
-		template:  tmpl,
+		endpoint:  method + " " + cfg.Target.URL,
+		nextStage: make(chan struct{}, 1),
+	}
+
+	// 初始化速率限制器 (不限速时同样创建,以便运行期间调整)
+	b.rateLimiter = NewRateLimiter(cfg.Load.RateLimit)
+
+	// 区间指标推送
+	if len(cfg.Output.Sinks) > 0 {
+		dispatcher, err := sink.NewDispatcher(cfg.Output.Sinks, b.logf)
+		if err != nil {
+			b.Close()
+			return nil, fmt.Errorf("创建指标推送失败: %w", err)
+		}
+		b.sinks = dispatcher
+		b.OnInterval(dispatcher.Push)
 	}
 
-	// 初始化速率限制器
-	if cfg.Load.RateLimit > 0 {
-		b.rateLimiter = NewRateLimiter(cfg.Load.RateLimit)
+	// 逐请求原始结果
+	if rawCfg := cfg.Output.RawLog; rawCfg.File != "" {
+		writer, err := rawlog.NewWriter(rawCfg.File, rawCfg.Format, rawCfg.BufferSize)
+		if err != nil {
+			b.Close()
+			return nil, err
+		}
+		b.rawLog = writer
 	}
 
 	return b, nil
@@ -93,27 +172,65 @@ func New(cfg *config.Config) (*Benchmark, error) {
 func (b *Benchmark) Run(ctx context.Context) (*Results, error) {
 	b.running.Store(true)
 	b.startTime = time.Now()
+	b.selfMon.Store(newSelfMonitor())
 	defer b.running.Store(false)
 
+	if err := b.executor.Prepare(ctx); err != nil {
+		return nil, fmt.Errorf("准备执行器失败: %w", err)
+	}
+
 	// 创建工作上下文
 	workCtx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
+	// 停止生成请求的上下文,正在执行的请求不受影响
+	genCtx, stopGeneration := context.WithCancel(workCtx)
+	defer stopGeneration()
+	b.controlMu.Lock()
+	b.stopGeneration = stopGeneration
+	stopped := b.stopRequested
+	b.controlMu.Unlock()
+	// Run 开始前已请求停止 (如准备期间收到中断信号)
+	if stopped {
+		b.interrupted.Store(true)
+		stopGeneration()
+	}
+
+	// 区间采样
+	samplerCtx, stopSampler := context.WithCancel(workCtx)
+	samplerDone := make(chan struct{})
+	go func() {
+		defer close(samplerDone)
+		b.intervalSampler(samplerCtx)
+	}()
+
 	// 根据负载模式执行测试
+	var err error
 	switch b.config.Load.LoadPattern {
 	case config.LoadPatternRampUp:
-		return b.runRampUp(workCtx)
+		err = b.runRampUp(workCtx, genCtx)
 	case config.LoadPatternBurst:
-		return b.runBurst(workCtx)
+		err = b.runBurst(workCtx, genCtx)
 	default:
-		return b.runConstant(workCtx)
+		err = b.runConstant(workCtx, genCtx)
+	}
+	b.setStage(StageDone)
+	if ctx.Err() != nil {
+		b.interrupted.Store(true)
 	}
+
+	stopSampler()
+	<-samplerDone
+
+	if err != nil {
+		return nil, err
+	}
+	return b.generateResults(), nil
 }
 
 // runConstant 恒定负载测试
-func (b *Benchmark) runConstant(ctx context.Context) (*Results, error) {
-	var wg sync.WaitGroup
-	requestChan := make(chan struct{}, b.config.Load.Concurrency)
+func (b *Benchmark) runConstant(ctx, genCtx context.Context) error {
+	b.setStage(StageConstant)
 
 	// 时间或请求数限制
 	var timeoutCtx context.Context
@@ -126,185 +243,191 @@ func (b *Benchmark) runConstant(ctx context.Context) (*Results, error) {
 	}
 	defer timeoutCancel()
 
-	// 启动工作协程
-	for i := 0; i < b.config.Load.Concurrency; i++ {
-		wg.Add(1)
-		go func(workerID int) {
-			defer wg.Done()
-			b.worker(timeoutCtx, workerID, requestChan)
-		}(i)
-	}
-
-	// 生成请求
-	go b.generateRequests(timeoutCtx, requestChan)
-
-	// 实时监控
-	if b.config.Output.RealtimeMonitor {
-		go b.realtimeMonitor(timeoutCtx)
-	}
-
-	// 等待完成
-	wg.Wait()
+	// 到达时间限制或被停止时不再生成请求
+	genTimeoutCtx, genTimeoutCancel := context.WithCancel(genCtx)
+	defer genTimeoutCancel()
+	stopAfter := context.AfterFunc(timeoutCtx, genTimeoutCancel)
+	defer stopAfter()
 
-	return b.generateResults(), nil
+	return b.drive(ctx, genTimeoutCtx, b.config.Load.Concurrency, b.config.Load.Concurrency, nil)
 }
 
 // runRampUp 渐进式负载测试
-func (b *Benchmark) runRampUp(ctx context.Context) (*Results, error) {
+func (b *Benchmark) runRampUp(ctx, genCtx context.Context) error {
 	rampCfg := b.config.Load.RampUp
 	if !rampCfg.Enabled {
-		return b.runConstant(ctx)
+		return b.runConstant(ctx, genCtx)
 	}
 
 	stepDuration := rampCfg.Duration / time.Duration(rampCfg.Steps)
 	concurrencyStep := (rampCfg.EndConcurrency - rampCfg.StartConcurrency) / rampCfg.Steps
 
-	fmt.Printf("📈 渐进式负载: %d -> %d (步长: %d, 每步: %v)\n",
+	b.logf("📈 渐进式负载: %d -> %d (步长: %d, 每步: %v)\n",
 		rampCfg.StartConcurrency, rampCfg.EndConcurrency, rampCfg.Steps, stepDuration)
 
-	var wg sync.WaitGroup
-	requestChan := make(chan struct{}, rampCfg.EndConcurrency)
-	workerControl := make(chan int, rampCfg.EndConcurrency)
-
-	// 启动工作协程池
-	for i := 0; i < rampCfg.EndConcurrency; i++ {
-		wg.Add(1)
-		go func(workerID int) {
-			defer wg.Done()
-
-			// 等待激活信号
-			<-workerControl
-			b.worker(ctx, workerID, requestChan)
-		}(i)
-	}
-
 	// 渐进增加并发
-	go func() {
+	schedule := func(pool *workerPool) {
 		currentConcurrency := rampCfg.StartConcurrency
+		b.setStage(fmt.Sprintf("%s 0/%d (c=%d)", StageRampUp, rampCfg.Steps, currentConcurrency))
 
-		// 激活初始工作协程
-		for i := 0; i < currentConcurrency; i++ {
-			workerControl <- i
-		}
+		b.hasNextStage.Store(true)
+		defer b.hasNextStage.Store(false)
 
 		ticker := time.NewTicker(stepDuration)
 		defer ticker.Stop()
 
-		for step := 0; step < rampCfg.Steps && ctx.Err() == nil; step++ {
-			<-ticker.C
+		for step := 0; step < rampCfg.Steps; step++ {
+			select {
+			case <-genCtx.Done():
+				return
+			case <-ticker.C:
+			case <-b.nextStage:
+				ticker.Reset(stepDuration)
+			}
 
-			// 增加并发
+			// 增加并发 (以当前并发为基准,保留手动调整的结果)
+			currentConcurrency = pool.size()
 			newConcurrency := currentConcurrency + concurrencyStep
 			if newConcurrency > rampCfg.EndConcurrency {
-				newConcurrency = rampCfg.EndConcurrency
-			}
-
-			for i := currentConcurrency; i < newConcurrency; i++ {
-				workerControl <- i
+				newConcurrency = max(rampCfg.EndConcurrency, currentConcurrency)
 			}
+			pool.resize(newConcurrency)
 
-			fmt.Printf("  ↑ 并发调整: %d -> %d\n", currentConcurrency, newConcurrency)
+			b.logf("  ↑ 并发调整: %d -> %d\n", currentConcurrency, newConcurrency)
 			currentConcurrency = newConcurrency
+			b.setStage(fmt.Sprintf("%s %d/%d (c=%d)", StageRampUp, step+1, rampCfg.Steps, currentConcurrency))
 		}
-	}()
-
-	// 生成请求
-	go b.generateRequests(ctx, requestChan)
-
-	// 实时监控
-	if b.config.Output.RealtimeMonitor {
-		go b.realtimeMonitor(ctx)
 	}
 
-	wg.Wait()
-	return b.generateResults(), nil
+	return b.drive(ctx, genCtx, rampCfg.StartConcurrency, rampCfg.EndConcurrency, schedule)
 }
 
 // runBurst 突发负载测试
-func (b *Benchmark) runBurst(ctx context.Context) (*Results, error) {
+func (b *Benchmark) runBurst(ctx, genCtx context.Context) error {
 	burstCfg := b.config.Load.BurstMode
 	if !burstCfg.Enabled {
-		return b.runConstant(ctx)
+		return b.runConstant(ctx, genCtx)
 	}
+	b.setStage(StageBase)
 
-	fmt.Printf("💥 突发负载模式: 基准 %d, 突发 %d (持续: %v, 间隔: %v)\n",
+	b.logf("💥 突发负载模式: 基准 %d, 突发 %d (持续: %v, 间隔: %v)\n",
 		burstCfg.BaseConcurrency, burstCfg.BurstConcurrency,
 		burstCfg.BurstDuration, burstCfg.BurstInterval)
 
-	var wg sync.WaitGroup
-	requestChan := make(chan struct{}, burstCfg.BurstConcurrency)
+	// 突发控制: 基准与突发交替
+	schedule := func(pool *workerPool) {
+		b.hasNextStage.Store(true)
+		defer b.hasNextStage.Store(false)
 
-	// 启动基准工作协程
-	for i := 0; i < burstCfg.BaseConcurrency; i++ {
-		wg.Add(1)
-		go func(workerID int) {
-			defer wg.Done()
-			b.worker(ctx, workerID, requestChan)
-		}(i)
-	}
-
-	// 突发协程池
-	// burstWorkers := make(chan struct{}, burstCfg.BurstConcurrency-burstCfg.BaseConcurrency)
-
-	// 突发控制
-	go func() {
-		ticker := time.NewTicker(burstCfg.BurstInterval)
-		defer ticker.Stop()
+		// wait 等待阶段结束,返回false表示已停止
+		wait := func(d time.Duration) bool {
+			timer := time.NewTimer(d)
+			defer timer.Stop()
+			select {
+			case <-genCtx.Done():
+				return false
+			case <-timer.C:
+			case <-b.nextStage:
+			}
+			return true
+		}
 
 		for {
-			select {
-			case <-ctx.Done():
+			if !wait(burstCfg.BurstInterval) {
 				return
-			case <-ticker.C:
-				// 触发突发
-				fmt.Printf("  💥 触发突发: +%d 并发\n", burstCfg.BurstConcurrency-burstCfg.BaseConcurrency)
-
-				burstCtx, burstCancel := context.WithTimeout(ctx, burstCfg.BurstDuration)
+			}
 
-				for i := 0; i < burstCfg.BurstConcurrency-burstCfg.BaseConcurrency; i++ {
-					wg.Add(1)
-					go func(workerID int) {
-						defer wg.Done()
-						b.worker(burstCtx, burstCfg.BaseConcurrency+workerID, requestChan)
-					}(i)
-				}
+			// 触发突发
+			b.logf("  💥 触发突发: +%d 并发\n", burstCfg.BurstConcurrency-burstCfg.BaseConcurrency)
+			b.setStage(StageBurst)
+			pool.resize(burstCfg.BurstConcurrency)
 
-				<-burstCtx.Done()
-				burstCancel()
-				fmt.Printf("  ✓ 突发结束\n")
+			if !wait(burstCfg.BurstDuration) {
+				return
 			}
+
+			// 突发结束后的工作协程完成手头请求后退出
+			pool.resize(burstCfg.BaseConcurrency)
+			b.setStage(StageBase)
+			b.logf("  ✓ 突发结束\n")
 		}
-	}()
+	}
 
-	// 生成请求
-	go b.generateRequests(ctx, requestChan)
+	return b.drive(ctx, genCtx, burstCfg.BaseConcurrency, burstCfg.BurstConcurrency, schedule)
+}
 
-	// 实时监控
-	if b.config.Output.RealtimeMonitor {
-		go b.realtimeMonitor(ctx)
+// drive 启动工作协程池和请求生成,直到请求生成结束且所有工作协程退出
+//
+// ctx 用于执行请求, genCtx 用于生成请求; schedule 可选,用于按阶段调整并发。
+// 请求生成结束后等待进行中的请求完成,超过排空时间则取消剩余请求。
+func (b *Benchmark) drive(ctx, genCtx context.Context, concurrency, buffer int, schedule func(pool *workerPool)) error {
+	requestChan := make(chan time.Time, buffer)
+
+	reqCtx, cancelRequests := context.WithCancel(ctx)
+	defer cancelRequests()
+
+	pool := newWorkerPool(b, reqCtx, genCtx, requestChan)
+	pool.resize(concurrency)
+
+	b.controlMu.Lock()
+	b.pool = pool
+	b.controlMu.Unlock()
+	defer func() {
+		b.controlMu.Lock()
+		b.pool = nil
+		b.controlMu.Unlock()
+	}()
+
+	if schedule != nil {
+		go schedule(pool)
 	}
 
-	wg.Wait()
-	return b.generateResults(), nil
+	// 生成请求,结束时关闭请求通道
+	b.generateRequests(genCtx, requestChan)
+
+	// 排空进行中的请求
+	drain := time.AfterFunc(b.drainTimeout(), func() {
+		if inFlight := b.inFlight.Load(); inFlight > 0 {
+			b.logf("⚠️  排空超时, 取消 %d 个进行中的请求\n", inFlight)
+		}
+		cancelRequests()
+	})
+	defer drain.Stop()
+
+	pool.wait()
+	return nil
 }
 
-// worker 工作协程
-func (b *Benchmark) worker(ctx context.Context, workerID int, requestChan <-chan struct{}) {
+// worker 工作协程, quit 关闭时完成手头请求后退出
+//
+// genCtx 结束后通道中排队的请求不再执行,只排空已发出的请求。
+func (b *Benchmark) worker(ctx, genCtx context.Context, quit <-chan struct{}, workerID int, requestChan <-chan time.Time) {
+	b.activeWorkers.Add(1)
+	defer b.activeWorkers.Add(-1)
+
 	for {
 		select {
 		case <-ctx.Done():
 			return
-		case _, ok := <-requestChan:
+		case <-quit:
+			return
+		case sent, ok := <-requestChan:
 			if !ok {
 				return
 			}
+			if genCtx.Err() != nil {
+				continue
+			}
+			if !sent.IsZero() {
+				b.selfMon.Load().recordQueueWait(time.Since(sent))
+			}
 			b.executeRequest(ctx, workerID)
 		}
 	}
 }
 
 // generateRequests 生成请求
-func (b *Benchmark) generateRequests(ctx context.Context, requestChan chan<- struct{}) {
+func (b *Benchmark) generateRequests(ctx context.Context, requestChan chan<- time.Time) {
 	defer close(requestChan)
 
 	totalRequests := b.config.Load.TotalRequests
@@ -320,13 +443,28 @@ func (b *Benchmark) generateRequests(ctx context.Context, requestChan chan<- str
 				return
 			}
 
-			// 速率限制
-			if b.rateLimiter != nil {
-				b.rateLimiter.Wait(ctx)
+			// 暂停
+			if !b.gate.wait(ctx) {
+				return
+			}
+
+			// 速率限制, 返回计划发送时间
+			ready := time.Now()
+			scheduled := b.rateLimiter.Wait(ctx)
+
+			// 限速时发送离开限速器的时间, 工作协程据此统计排队等待
+			var sent time.Time
+			if !scheduled.IsZero() {
+				sent = time.Now()
+				// 上一个请求等待空闲工作协程时计划时间已过, 这段时间不是客户端的调度延迟
+				if ready.After(scheduled) {
+					scheduled = ready
+				}
+				b.selfMon.Load().recordLag(sent.Sub(scheduled))
 			}
 
 			select {
-			case requestChan <- struct{}{}:
+			case requestChan <- sent:
 				requestCount++
 			case <-ctx.Done():
 				return
@@ -337,159 +475,87 @@ func (b *Benchmark) generateRequests(ctx context.Context, requestChan chan<- str
 
 // executeRequest 执行单个请求
 func (b *Benchmark) executeRequest(ctx context.Context, workerID int) {
-	startTime := time.Now()
+	result := requestResult{workerID: workerID}
 
-	// 创建请求
-	req, err := b.createRequest(ctx, workerID)
-	if err != nil {
-		b.stats.RecordError("request_creation", err)
-		fmt.Printf("err: %e \n", err)
-		return
+	// 原始结果需要记录各阶段耗时
+	if b.rawLog != nil {
+		result.trace = &requestTrace{}
+		ctx = httptrace.WithClientTrace(ctx, result.trace.clientTrace())
 	}
 
-	// 发送请求
-	resp, err := b.client.Do(req)
-	latency := time.Since(startTime)
+	b.inFlight.Add(1)
+	defer b.inFlight.Add(-1)
 
+	var err error
+	result.Result, err = b.executor.Execute(ctx, workerID)
 	if err != nil {
-		b.stats.RecordError("network", err)
-		fmt.Printf("err: %e \n", err)
-		b.stats.RecordRequest(latency, 0, 0, false)
+		b.stats.Recorder(workerID).RecordError("request_creation", err)
+		b.logf("err: %e \n", err)
 		return
 	}
-	defer resp.Body.Close()
-
-	// 读取响应体
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		b.stats.RecordError("body_read", err)
-		fmt.Printf("err: %e \n", err)
-		b.stats.RecordRequest(latency, 0, 0, false)
+	if result.Err != nil && b.canceledBy(ctx) {
 		return
 	}
 
-	// 验证响应
-	validationErr := b.validator.Validate(resp, body)
-	success := validationErr == nil
-
-	if !success {
-		b.stats.RecordError("validation", validationErr)
-		fmt.Printf("err: %e \n", validationErr)
-	}
-
 	// 记录统计
-	b.stats.RecordRequest(latency, int64(len(body)), int64(req.ContentLength), success)
-	b.stats.RecordStatusCode(resp.StatusCode)
+	b.recordResult(&result)
 }
 
-// createRequest 创建HTTP请求
-func (b *Benchmark) createRequest(ctx context.Context, workerID int) (*http.Request, error) {
-	// 应用模板
-	url := b.config.Target.URL
-	body := b.config.Target.Body
-
-	if b.config.Request.Template.Enabled {
-		vars := map[string]interface{}{
-			"worker_id": workerID,
-			"timestamp": time.Now().Unix(),
-		}
-
-		var err error
-		url, err = b.template.Render(url, vars)
-		if err != nil {
-			return nil, fmt.Errorf("渲染URL模板失败: %w", err)
-		}
-
-		if b.config.Request.DynamicBody {
-			body, err = b.template.Render(b.config.Request.BodyTemplate, vars)
-			if err != nil {
-				return nil, fmt.Errorf("渲染Body模板失败: %w", err)
-			}
-		}
-	}
-
-	// 创建请求
-	var req *http.Request
-	var err error
-
-	if body != "" {
-		req, err = http.NewRequestWithContext(ctx, b.config.Target.Method, url,
-			strings.NewReader(body))
-	} else {
-		req, err = http.NewRequestWithContext(ctx, b.config.Target.Method, url, nil)
-	}
+// Stats 获取统计收集器
+func (b *Benchmark) Stats() *stats.Collector {
+	return b.stats
+}
 
-	if err != nil {
-		// fmt.Printf("err: %e\n", err)
-		return nil, err
-	}
+// Endpoint 获取端点标识 (方法 + URL)
+func (b *Benchmark) Endpoint() string {
+	return b.endpoint
+}
 
-	// 设置请求头
-	for key, value := range b.config.Target.Headers {
-		req.Header.Set(key, value)
-	}
-	for key, value := range b.config.Request.Headers {
-		req.Header.Set(key, value)
-	}
+// InFlight 获取正在执行的请求数
+func (b *Benchmark) InFlight() int64 {
+	return b.inFlight.Load()
+}
 
-	// 设置Cookie
-	for _, cookie := range b.config.Request.Cookies {
-		req.AddCookie(&http.Cookie{
-			Name:     cookie.Name,
-			Value:    cookie.Value,
-			Domain:   cookie.Domain,
-			Path:     cookie.Path,
-			Expires:  cookie.Expires,
-			Secure:   cookie.Secure,
-			HttpOnly: cookie.HttpOnly,
-		})
-	}
+// ActiveWorkers 获取活跃的工作协程数
+func (b *Benchmark) ActiveWorkers() int64 {
+	return b.activeWorkers.Load()
+}
 
-	return req, nil
+// TargetRate 获取当前目标速率(请求/秒), 0 表示不限速
+func (b *Benchmark) TargetRate() float64 {
+	return float64(b.rateLimiter.Rate())
 }
 
 // Close 关闭基准测试器
 func (b *Benchmark) Close() error {
 	b.running.Store(false)
-	return nil
-}
+	b.rateLimiter.Stop()
 
-// createHTTPClient 创建HTTP客户端
-func createHTTPClient(cfg *config.Config) (*http.Client, error) {
-	// TLS配置
-	tlsConfig, err := createTLSConfig(cfg.TLS)
-	if err != nil {
-		return nil, err
-	}
-
-	// HTTP/3 (QUIC)
-	if cfg.Protocol.HTTP3Enabled {
-		return &http.Client{
-			Transport: &http3.RoundTripper{
-				TLSClientConfig: tlsConfig,
-			},
-			Timeout: cfg.Target.Timeout,
-		}, nil
-	}
-
-	// HTTP/1.1 和 HTTP/2
-	transport := &http.Transport{
-		TLSClientConfig:     tlsConfig,
-		MaxIdleConns:        cfg.Load.Concurrency * 2,
-		MaxIdleConnsPerHost: cfg.Load.Concurrency,
-		IdleConnTimeout:     cfg.Protocol.IdleTimeout,
-		DisableKeepAlives:   !cfg.Protocol.KeepAlive,
-	}
-
-	// HTTP/2
-	if cfg.Protocol.HTTP2Enabled {
-		http2.ConfigureTransport(transport)
+	firstErr := b.executor.Close()
+	if err := b.CloseOutputs(); err != nil && firstErr == nil {
+		firstErr = err
 	}
+	return firstErr
+}
 
-	return &http.Client{
-		Transport: transport,
-		Timeout:   cfg.Target.Timeout,
-	}, nil
+// CloseOutputs 推送剩余的区间指标并写完原始结果文件, 之后的记录被丢弃
+//
+// 可以在测试进行中调用, 用于强制退出前保存已缓冲的数据; 多次调用只关闭一次。
+func (b *Benchmark) CloseOutputs() error {
+	b.outputsOnce.Do(func() {
+		if b.sinks != nil {
+			b.outputsErr = b.sinks.Close()
+		}
+		if b.rawLog != nil {
+			if err := b.rawLog.Close(); err != nil && b.outputsErr == nil {
+				b.outputsErr = err
+			}
+			if dropped := b.rawLog.Dropped(); dropped > 0 {
+				b.logf("⚠️  原始结果丢弃 %d 条记录 (已写入 %d 条)\n", dropped, b.rawLog.Written())
+			}
+		}
+	})
+	return b.outputsErr
 }
 
 // createTLSConfig 创建TLS配置
@@ -532,6 +598,19 @@ func createTLSConfig(cfg config.TLSConfig) (*tls.Config, error) {
 		tlsConfig.Certificates = []tls.Certificate{cert}
 	}
 
+	// 自定义CA证书
+	if cfg.CAFile != "" {
+		pem, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("读取CA证书失败: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("CA证书中没有有效的证书: %s", cfg.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
 	return tlsConfig, nil
 }
 
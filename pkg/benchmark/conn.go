@@ -0,0 +1,185 @@
+package benchmark
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/HdrHistogram/hdrhistogram-go"
+
+	"httpbench/pkg/config"
+)
+
+// errDialerClosed 等待连接名额时执行器已关闭
+var errDialerClosed = errors.New("连接池已关闭")
+
+// connPool 连接总数上限、按请求数和存活时间回收连接以及连接周转统计
+//
+// 每个主机的连接数上限由传输层实现, 这里只限制经拨号建立的连接总数。
+type connPool struct {
+	cfg config.PoolConfig
+
+	// 连接名额, 未限制时为 nil
+	slots chan struct{}
+	done  chan struct{}
+	once  sync.Once
+
+	// 等待名额时关闭空闲连接, 避免其他主机的空闲连接一直占用名额
+	closeIdle func()
+
+	// 第一个连接建立的时间 (UnixNano), 用于计算周转速率
+	first    atomic.Int64
+	opened   atomic.Int64
+	closed   atomic.Int64
+	recycled atomic.Int64
+
+	histMu   sync.Mutex
+	lifetime *hdrhistogram.Histogram
+}
+
+// newConnPool 创建连接池
+func newConnPool(cfg config.PoolConfig) *connPool {
+	p := &connPool{
+		cfg:      cfg,
+		done:     make(chan struct{}),
+		lifetime: newDurationHistogram(),
+	}
+	if cfg.MaxConnections > 0 {
+		p.slots = make(chan struct{}, cfg.MaxConnections)
+	}
+	return p
+}
+
+// recycles 是否按请求数或存活时间回收连接
+func (p *connPool) recycles() bool {
+	return p.cfg.MaxRequestsPerConnection > 0 || p.cfg.MaxConnectionAge > 0
+}
+
+// acquire 等待连接名额, 返回释放函数
+func (p *connPool) acquire(ctx context.Context) (func(), error) {
+	if p.slots == nil {
+		return func() {}, nil
+	}
+	release := func() { <-p.slots }
+
+	select {
+	case p.slots <- struct{}{}:
+		return release, nil
+	default:
+	}
+	if p.closeIdle != nil {
+		p.closeIdle()
+	}
+	select {
+	case p.slots <- struct{}{}:
+		return release, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-p.done:
+		return nil, errDialerClosed
+	}
+}
+
+// track 包装新建的连接, 关闭时释放名额并记录存活时间
+func (p *connPool) track(conn net.Conn, release func()) net.Conn {
+	now := time.Now()
+	p.first.CompareAndSwap(0, now.UnixNano())
+	p.opened.Add(1)
+	return &trackedConn{Conn: conn, pool: p, opened: now, release: release}
+}
+
+// close 中止等待名额的拨号
+func (p *connPool) close() {
+	p.once.Do(func() { close(p.done) })
+}
+
+// withRecycling 为请求登记 GotConn 钩子, 连接达到请求数或存活时间上限时本次请求后关闭连接
+//
+// 标准库客户端可能复制请求, 因此通过请求头的 Connection: close 通知传输层;
+// 请求头在各副本间共享, 这里先复制一份避免影响原型请求。
+func (p *connPool) withRecycling(req *http.Request) *http.Request {
+	header := req.Header.Clone()
+	trace := &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			if c := unwrapTracked(info.Conn); c != nil && c.use() {
+				header.Set("Connection", "close")
+				// HTTP/2 连接上可能有多个请求同时到达上限
+				if c.retired.CompareAndSwap(false, true) {
+					p.recycled.Add(1)
+				}
+			}
+		},
+	}
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+	req.Header = header
+	return req
+}
+
+// addStats 写入连接周转统计, 没有经拨号建立连接时不写入
+func (p *connPool) addStats(stats map[string]float64) {
+	opened, closed := p.opened.Load(), p.closed.Load()
+	if opened == 0 {
+		return
+	}
+	stats["connections_closed"] = float64(closed)
+	if p.recycles() {
+		stats["connections_recycled"] = float64(p.recycled.Load())
+	}
+	if first := p.first.Load(); first > 0 {
+		if elapsed := time.Since(time.Unix(0, first)).Seconds(); elapsed > 0 {
+			stats["connections_opened_per_sec"] = float64(opened) / elapsed
+			stats["connections_closed_per_sec"] = float64(closed) / elapsed
+		}
+	}
+
+	p.histMu.Lock()
+	defer p.histMu.Unlock()
+	addDurationStats(stats, "connection_lifetime", p.lifetime)
+}
+
+// trackedConn 连接池管理的连接
+type trackedConn struct {
+	net.Conn
+	pool    *connPool
+	opened  time.Time
+	release func()
+
+	requests atomic.Int64
+	retired  atomic.Bool
+	once     sync.Once
+}
+
+// use 登记一次请求, 返回是否应在本次请求后关闭
+func (c *trackedConn) use() bool {
+	n := c.requests.Add(1)
+	cfg := c.pool.cfg
+	return cfg.MaxRequestsPerConnection > 0 && n >= int64(cfg.MaxRequestsPerConnection) ||
+		cfg.MaxConnectionAge > 0 && time.Since(c.opened) >= cfg.MaxConnectionAge
+}
+
+func (c *trackedConn) Close() error {
+	err := c.Conn.Close()
+	c.once.Do(func() {
+		c.release()
+		c.pool.closed.Add(1)
+		c.pool.histMu.Lock()
+		recordDuration(c.pool.lifetime, time.Since(c.opened))
+		c.pool.histMu.Unlock()
+	})
+	return err
+}
+
+// unwrapTracked 从传输层给出的连接 (可能是 TLS 连接) 中取出 trackedConn
+func unwrapTracked(conn net.Conn) *trackedConn {
+	if tc, ok := conn.(*tls.Conn); ok {
+		conn = tc.NetConn()
+	}
+	c, _ := conn.(*trackedConn)
+	return c
+}
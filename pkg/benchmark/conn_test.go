@@ -0,0 +1,111 @@
+package benchmark
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"httpbench/pkg/config"
+)
+
+// TestConnectionPool 测试按请求数和存活时间回收连接以及连接数上限
+func TestConnectionPool(t *testing.T) {
+	var newConns atomic.Int64
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(2 * time.Millisecond)
+		io.WriteString(w, "ok")
+	})
+	var servers []*httptest.Server
+	for i := 0; i < 2; i++ {
+		server := httptest.NewUnstartedServer(handler)
+		server.Config.ConnState = func(c net.Conn, state http.ConnState) {
+			if state == http.StateNew {
+				newConns.Add(1)
+			}
+		}
+		server.Start()
+		defer server.Close()
+		servers = append(servers, server)
+	}
+
+	// run 运行并采样客户端同时存在的最大连接数
+	run := func(engine config.Engine, pool config.PoolConfig, concurrency, total int, hosts int) (*Results, int64) {
+		t.Helper()
+		newConns.Store(0)
+		cfg := &config.Config{
+			Target:   config.TargetConfig{URL: servers[0].URL + "/", Timeout: 5 * time.Second},
+			Load:     config.LoadConfig{Concurrency: concurrency, TotalRequests: total},
+			Protocol: config.ProtocolConfig{KeepAlive: true, Engine: engine, Pool: pool},
+		}
+		if hosts > 1 {
+			for _, server := range servers[:hosts] {
+				cfg.Target.Hosts = append(cfg.Target.Hosts, config.TargetHost{Address: server.Listener.Addr().String()})
+			}
+		}
+		bench := newTestBenchmark(t, cfg)
+		defer bench.Close()
+
+		connPool := bench.executor.(*httpExecutor).dialer.pool
+		var peak atomic.Int64
+		done := make(chan struct{})
+		sampled := make(chan struct{})
+		go func() {
+			defer close(sampled)
+			for {
+				if n := connPool.opened.Load() - connPool.closed.Load(); n > peak.Load() {
+					peak.Store(n)
+				}
+				select {
+				case <-done:
+					return
+				case <-time.After(200 * time.Microsecond):
+				}
+			}
+		}()
+
+		results, err := bench.Run(context.Background())
+		close(done)
+		<-sampled
+		if err != nil {
+			t.Fatalf("运行失败: %v", err)
+		}
+		if results.SuccessRequests != int64(total) {
+			t.Errorf("%s %+v: 成功 %d, 错误 %v", engine, pool, results.SuccessRequests, results.ErrorsByType)
+		}
+		return results, peak.Load()
+	}
+
+	for _, engine := range []config.Engine{config.EngineStandard, config.EngineRaw} {
+		// 每个连接 5 个请求后关闭
+		results, _ := run(engine, config.PoolConfig{MaxRequestsPerConnection: 5}, 2, 40, 1)
+		if newConns.Load() != 8 || results.ProtocolStats["connections_recycled"] != 8 {
+			t.Errorf("%s 按请求数回收: 服务端连接 %d, 统计 %v", engine, newConns.Load(), results.ProtocolStats)
+		}
+		if results.ProtocolStats["connections_opened_per_sec"] <= 0 || results.ProtocolStats["connection_lifetime_ms_max"] <= 0 {
+			t.Errorf("%s 缺少连接周转统计: %v", engine, results.ProtocolStats)
+		}
+
+		// 按存活时间回收
+		results, _ = run(engine, config.PoolConfig{MaxConnectionAge: 10 * time.Millisecond}, 2, 60, 1)
+		if results.ProtocolStats["connections_recycled"] < 2 || newConns.Load() < 3 {
+			t.Errorf("%s 按存活时间回收: 服务端连接 %d, 统计 %v", engine, newConns.Load(), results.ProtocolStats)
+		}
+
+		// 每个主机的连接数上限, 请求在已有连接上排队
+		_, peak := run(engine, config.PoolConfig{MaxConnectionsPerHost: 2}, 8, 80, 1)
+		if peak > 2 || newConns.Load() > 2 {
+			t.Errorf("%s 每主机上限: 同时存在 %d 个连接, 服务端连接 %d", engine, peak, newConns.Load())
+		}
+
+		// 两个主机共享的连接总数上限
+		_, peak = run(engine, config.PoolConfig{MaxConnections: 3}, 8, 80, 2)
+		if peak > 3 {
+			t.Errorf("%s 总数上限: 同时存在 %d 个连接", engine, peak)
+		}
+	}
+}
@@ -0,0 +1,257 @@
+package benchmark
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"httpbench/pkg/config"
+)
+
+// Stage 名称
+const (
+	StageIdle     = "idle"
+	StageConstant = "constant"
+	StageRampUp   = "ramp-up"
+	StageBase     = "base"
+	StageBurst    = "burst"
+	StageDone     = "done"
+)
+
+// pauseGate 暂停开关,暂停期间不再生成新请求
+type pauseGate struct {
+	mu     sync.Mutex
+	paused bool
+	resume chan struct{}
+}
+
+// pause 暂停,返回状态是否发生变化
+func (g *pauseGate) pause() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.paused {
+		return false
+	}
+	g.paused = true
+	g.resume = make(chan struct{})
+	return true
+}
+
+// unpause 恢复,返回状态是否发生变化
+func (g *pauseGate) unpause() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if !g.paused {
+		return false
+	}
+	g.paused = false
+	close(g.resume)
+	return true
+}
+
+// isPaused 是否处于暂停状态
+func (g *pauseGate) isPaused() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.paused
+}
+
+// wait 暂停期间阻塞,返回false表示上下文已结束
+func (g *pauseGate) wait(ctx context.Context) bool {
+	g.mu.Lock()
+	paused, resume := g.paused, g.resume
+	g.mu.Unlock()
+
+	if !paused {
+		return ctx.Err() == nil
+	}
+	select {
+	case <-resume:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// Pause 暂停生成新请求,正在执行的请求不受影响
+func (b *Benchmark) Pause() {
+	if b.gate.pause() {
+		b.logf("⏸️  已暂停\n")
+	}
+}
+
+// Resume 恢复生成请求
+func (b *Benchmark) Resume() {
+	// 暂停期间的发送计划作废, 不计入调度延迟
+	b.rateLimiter.Reset()
+	if b.gate.unpause() {
+		b.logf("▶️  已恢复\n")
+	}
+}
+
+// Paused 是否处于暂停状态
+func (b *Benchmark) Paused() bool {
+	return b.gate.isPaused()
+}
+
+// defaultDrainTimeout 未配置排空时间时的默认值
+const defaultDrainTimeout = 10 * time.Second
+
+// Stop 优雅停止: 不再生成新请求,等待正在执行的请求完成后Run返回
+//
+// 请求在排空时间 (load.drain_timeout) 内未完成则被取消且不计入统计,结果标记为部分结果。
+// Run 开始前调用时记录下来, Run 开始后立即停止。
+func (b *Benchmark) Stop() {
+	b.controlMu.Lock()
+	b.stopRequested = true
+	stop := b.stopGeneration
+	b.controlMu.Unlock()
+
+	if stop != nil {
+		b.interrupted.Store(true)
+		stop()
+	}
+	b.gate.unpause()
+}
+
+// drainTimeout 获取排空时间
+func (b *Benchmark) drainTimeout() time.Duration {
+	if b.config.Load.DrainTimeout > 0 {
+		return b.config.Load.DrainTimeout
+	}
+	return defaultDrainTimeout
+}
+
+// canceledBy 请求是否因测试自身取消而失败 (排空超时或外部取消),此类请求不计入统计
+func (b *Benchmark) canceledBy(ctx context.Context) bool {
+	if ctx.Err() == nil {
+		return false
+	}
+	b.canceled.Add(1)
+	return true
+}
+
+// SetRate 调整目标速率(请求/秒), rps <= 0 表示不限速
+func (b *Benchmark) SetRate(rps int) {
+	b.rateLimiter.SetRate(rps)
+	if rps > 0 {
+		b.logf("🎚️  目标速率调整为 %d req/s\n", rps)
+	} else {
+		b.logf("🎚️  目标速率调整为不限速\n")
+	}
+}
+
+// SetConcurrency 调整并发数,仅在测试运行期间有效
+//
+// 渐进式和突发模式下,后续阶段以调整后的并发为基准继续调整。
+func (b *Benchmark) SetConcurrency(n int) error {
+	if n < 1 {
+		return fmt.Errorf("并发数必须大于0: %d", n)
+	}
+
+	b.controlMu.Lock()
+	pool := b.pool
+	b.controlMu.Unlock()
+
+	if pool == nil {
+		return fmt.Errorf("测试未在运行")
+	}
+	if old := pool.resize(n); old != n {
+		b.logf("🎚️  并发调整为 %d (原 %d)\n", n, old)
+	}
+	return nil
+}
+
+// Concurrency 获取当前并发数 (工作协程池大小)
+func (b *Benchmark) Concurrency() int {
+	b.controlMu.Lock()
+	pool := b.pool
+	b.controlMu.Unlock()
+
+	if pool == nil {
+		return 0
+	}
+	return pool.size()
+}
+
+// NextStage 立即进入下一阶段 (渐进式的下一步,或突发模式的开始/结束突发)
+func (b *Benchmark) NextStage() error {
+	if !b.hasNextStage.Load() {
+		return fmt.Errorf("当前阶段 %s 没有后续阶段", b.Stage())
+	}
+
+	select {
+	case b.nextStage <- struct{}{}:
+	default:
+		// 已有待处理的切换请求
+	}
+	return nil
+}
+
+// Snapshot 获取当前测试结果快照,测试运行期间可随时调用 (运行期间的快照标记为部分结果)
+func (b *Benchmark) Snapshot() *Results {
+	results := b.generateResults()
+	if b.running.Load() {
+		results.Partial = true
+	}
+	return results
+}
+
+// Stage 获取当前阶段
+func (b *Benchmark) Stage() string {
+	if stage, ok := b.stage.Load().(string); ok {
+		return stage
+	}
+	return StageIdle
+}
+
+// setStage 设置当前阶段
+func (b *Benchmark) setStage(stage string) {
+	b.stage.Store(stage)
+}
+
+// Elapsed 获取已运行时间
+func (b *Benchmark) Elapsed() time.Duration {
+	if b.startTime.IsZero() {
+		return 0
+	}
+	return time.Since(b.startTime)
+}
+
+// PlannedDuration 获取计划运行时间, 0 表示由请求数或外部停止决定
+func (b *Benchmark) PlannedDuration() time.Duration {
+	load := b.config.Load
+	switch load.LoadPattern {
+	case config.LoadPatternRampUp:
+		if load.RampUp.Enabled {
+			return 0
+		}
+	case config.LoadPatternBurst:
+		if load.BurstMode.Enabled {
+			return 0
+		}
+	}
+	return load.Duration
+}
+
+// SetLogOutput 设置运行日志(错误、阶段变化等)的输出位置,默认为标准输出
+func (b *Benchmark) SetLogOutput(w io.Writer) {
+	b.controlMu.Lock()
+	b.logOutput = w
+	b.controlMu.Unlock()
+}
+
+// logf 输出运行日志,写入被串行化以便使用非并发安全的Writer
+func (b *Benchmark) logf(format string, args ...interface{}) {
+	b.controlMu.Lock()
+	defer b.controlMu.Unlock()
+
+	w := b.logOutput
+	if w == nil {
+		w = os.Stdout
+	}
+	fmt.Fprintf(w, format, args...)
+}
@@ -0,0 +1,288 @@
+package benchmark
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"httpbench/pkg/config"
+)
+
+// TestRateLimiterSetRate 测试运行期间调整速率
+func TestRateLimiterSetRate(t *testing.T) {
+	limiter := NewRateLimiter(0)
+	defer limiter.Stop()
+
+	ctx := context.Background()
+
+	// 不限速时立即返回
+	start := time.Now()
+	for i := 0; i < 1000; i++ {
+		limiter.Wait(ctx)
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("不限速时不应等待: %v", elapsed)
+	}
+
+	// 低速率下等待中的调用在调整后按新速率返回
+	limiter.SetRate(1)
+	done := make(chan struct{})
+	go func() {
+		limiter.Wait(ctx)
+		limiter.Wait(ctx)
+		close(done)
+	}()
+	time.Sleep(50 * time.Millisecond)
+	limiter.SetRate(200)
+
+	select {
+	case <-done:
+	case <-time.After(500 * time.Millisecond):
+		t.Fatal("调整速率后等待未按新速率返回")
+	}
+	if limiter.Rate() != 200 {
+		t.Errorf("速率不匹配: got %d, want 200", limiter.Rate())
+	}
+
+	// 恢复不限速
+	limiter.SetRate(0)
+	start = time.Now()
+	limiter.Wait(ctx)
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("恢复不限速后不应等待: %v", elapsed)
+	}
+}
+
+// TestPauseAndStop 测试暂停、恢复与优雅停止
+func TestPauseAndStop(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+		w.Write([]byte("OK"))
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{
+		Target: config.TargetConfig{
+			URL:     server.URL,
+			Method:  "GET",
+			Timeout: 5 * time.Second,
+		},
+		Load: config.LoadConfig{
+			Concurrency: 4,
+			Duration:    30 * time.Second,
+		},
+		Protocol: config.ProtocolConfig{
+			KeepAlive: true,
+		},
+	}
+
+	bench := newTestBenchmark(t, cfg)
+	defer bench.Close()
+
+	var logs strings.Builder
+	bench.SetLogOutput(&logs)
+
+	done := make(chan *Results, 1)
+	go func() {
+		results, err := bench.Run(context.Background())
+		if err != nil {
+			t.Errorf("运行基准测试失败: %v", err)
+		}
+		done <- results
+	}()
+
+	time.Sleep(200 * time.Millisecond)
+	if stage := bench.Stage(); stage != StageConstant {
+		t.Errorf("阶段不匹配: got %q, want %q", stage, StageConstant)
+	}
+
+	// 暂停后等待进行中的请求完成,请求数不应再增长
+	bench.Pause()
+	if !bench.Paused() {
+		t.Fatal("应处于暂停状态")
+	}
+	time.Sleep(150 * time.Millisecond)
+	paused := bench.Stats().Snapshot().TotalRequests
+	time.Sleep(200 * time.Millisecond)
+	if got := bench.Stats().Snapshot().TotalRequests; got != paused {
+		t.Errorf("暂停期间仍有新请求: %d -> %d", paused, got)
+	}
+
+	bench.Resume()
+	time.Sleep(200 * time.Millisecond)
+	if got := bench.Stats().Snapshot().TotalRequests; got <= paused {
+		t.Errorf("恢复后没有新请求: %d", got)
+	}
+
+	bench.Stop()
+	select {
+	case results := <-done:
+		if results == nil {
+			t.Fatal("结果为nil")
+		}
+		if results.FailedRequests != 0 {
+			t.Errorf("优雅停止不应产生失败请求: %d (%v)", results.FailedRequests, results.ErrorsByType)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("停止后测试未结束")
+	}
+
+	if bench.Stage() != StageDone {
+		t.Errorf("阶段不匹配: got %q, want %q", bench.Stage(), StageDone)
+	}
+	if !strings.Contains(logs.String(), "已暂停") {
+		t.Errorf("运行日志未输出到指定位置: %q", logs.String())
+	}
+}
+
+// TestStopBeforeRun 测试 Run 开始前请求的停止在开始后生效
+func TestStopBeforeRun(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("OK"))
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{
+		Target: config.TargetConfig{
+			URL:     server.URL,
+			Method:  "GET",
+			Timeout: 5 * time.Second,
+		},
+		Load: config.LoadConfig{
+			Concurrency: 2,
+			Duration:    30 * time.Second,
+		},
+	}
+
+	bench := newTestBenchmark(t, cfg)
+	defer bench.Close()
+
+	bench.Stop()
+	done := make(chan *Results, 1)
+	go func() {
+		results, err := bench.Run(context.Background())
+		if err != nil {
+			t.Errorf("运行基准测试失败: %v", err)
+		}
+		done <- results
+	}()
+
+	select {
+	case results := <-done:
+		if results == nil {
+			t.Fatal("结果为nil")
+		}
+		if !results.Partial {
+			t.Error("开始前停止的结果应标记为部分结果")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("开始前请求的停止未生效")
+	}
+}
+
+// TestRuntimeControl 测试运行期间调整并发与切换阶段
+func TestRuntimeControl(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(10 * time.Millisecond)
+		w.Write([]byte("OK"))
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{
+		Target: config.TargetConfig{
+			URL:     server.URL,
+			Method:  "GET",
+			Timeout: 5 * time.Second,
+		},
+		Load: config.LoadConfig{
+			Concurrency: 2,
+			Duration:    30 * time.Second,
+			LoadPattern: config.LoadPatternBurst,
+			BurstMode: config.BurstConfig{
+				Enabled:          true,
+				BaseConcurrency:  2,
+				BurstConcurrency: 8,
+				BurstDuration:    time.Minute,
+				BurstInterval:    time.Minute,
+			},
+		},
+		Protocol: config.ProtocolConfig{
+			KeepAlive: true,
+		},
+	}
+
+	bench := newTestBenchmark(t, cfg)
+	defer bench.Close()
+
+	if err := bench.SetConcurrency(4); err == nil {
+		t.Error("未运行时调整并发应返回错误")
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if _, err := bench.Run(context.Background()); err != nil {
+			t.Errorf("运行基准测试失败: %v", err)
+		}
+	}()
+
+	// waitFor 等待条件成立
+	waitFor := func(desc string, cond func() bool) {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for !cond() {
+			if time.Now().After(deadline) {
+				t.Fatalf("等待超时: %s (stage=%s, concurrency=%d, active=%d)",
+					desc, bench.Stage(), bench.Concurrency(), bench.ActiveWorkers())
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	waitFor("基准阶段", func() bool { return bench.Stage() == StageBase && bench.ActiveWorkers() == 2 })
+
+	// 手动切换到突发阶段,再切回基准阶段
+	if err := bench.NextStage(); err != nil {
+		t.Fatalf("切换阶段失败: %v", err)
+	}
+	waitFor("突发阶段", func() bool { return bench.Stage() == StageBurst && bench.ActiveWorkers() == 8 })
+	if err := bench.NextStage(); err != nil {
+		t.Fatalf("切换阶段失败: %v", err)
+	}
+	waitFor("回到基准阶段", func() bool { return bench.Stage() == StageBase && bench.ActiveWorkers() == 2 })
+
+	// 调整并发
+	if err := bench.SetConcurrency(5); err != nil {
+		t.Fatalf("调整并发失败: %v", err)
+	}
+	waitFor("扩容到5", func() bool { return bench.Concurrency() == 5 && bench.ActiveWorkers() == 5 })
+	if err := bench.SetConcurrency(1); err != nil {
+		t.Fatalf("调整并发失败: %v", err)
+	}
+	waitFor("缩容到1", func() bool { return bench.ActiveWorkers() == 1 })
+	if err := bench.SetConcurrency(0); err == nil {
+		t.Error("并发为0应返回错误")
+	}
+
+	if snapshot := bench.Snapshot(); snapshot.TotalRequests == 0 {
+		t.Error("快照应包含已完成的请求")
+	}
+
+	bench.Stop()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("停止后测试未结束")
+	}
+
+	// 缩容不应中断进行中的请求
+	if results := bench.Snapshot(); results.FailedRequests != 0 {
+		t.Errorf("不应有失败请求: %v", results.ErrorsByType)
+	}
+	if err := bench.NextStage(); err == nil {
+		t.Error("测试结束后切换阶段应返回错误")
+	}
+}
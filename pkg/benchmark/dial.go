@@ -0,0 +1,209 @@
+package benchmark
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strings"
+	"sync/atomic"
+
+	"github.com/quic-go/quic-go"
+
+	"httpbench/pkg/config"
+)
+
+// targetDialer 建立到目标的连接
+//
+// 支持 Unix 套接字、按 host:port 覆盖连接地址、轮流绑定本地源地址以及经代理连接。
+// 只改变连接的去向, 请求的 Host 和 TLS 的 SNI 仍取自 URL。
+type targetDialer struct {
+	unixSocket string
+	connectTo  []connectRule
+	resolve    []connectRule
+
+	sources []net.IP
+	next    atomic.Uint64
+
+	// 代理, 未配置时为 nil
+	proxy *proxyDialer
+
+	// 连接数上限和连接周转统计
+	pool *connPool
+
+	// 新建连接数
+	dials atomic.Int64
+}
+
+// connectRule 地址覆盖规则, 为空的字段匹配任意值或保持不变
+type connectRule struct {
+	host, port     string
+	toHost, toPort string
+}
+
+// newTargetDialer 解析目标和代理的连接配置
+func newTargetDialer(cfg *config.Config) (*targetDialer, error) {
+	target := cfg.Target
+	d := &targetDialer{
+		unixSocket: target.UnixSocket,
+		pool:       newConnPool(cfg.Protocol.Pool),
+	}
+
+	for _, s := range target.ConnectTo {
+		parts, err := splitHostList(s, 4)
+		if err != nil {
+			return nil, fmt.Errorf("无效的 connect_to %q: %w", s, err)
+		}
+		d.connectTo = append(d.connectTo, connectRule{parts[0], parts[1], parts[2], parts[3]})
+	}
+
+	for _, s := range target.Resolve {
+		parts, err := splitHostList(s, 3)
+		if err != nil || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+			return nil, fmt.Errorf("无效的 resolve %q: 应为 host:port:addr", s)
+		}
+		d.resolve = append(d.resolve, connectRule{host: parts[0], port: parts[1], toHost: parts[2]})
+	}
+
+	for _, s := range target.SourceAddrs {
+		ip := net.ParseIP(s)
+		if ip == nil {
+			return nil, fmt.Errorf("无效的源地址: %s", s)
+		}
+		d.sources = append(d.sources, ip)
+	}
+
+	u, err := proxyURL(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if u != nil {
+		var tlsConfig *tls.Config
+		if u.Scheme == "https" {
+			if tlsConfig, err = createTLSConfig(cfg.TLS); err != nil {
+				return nil, err
+			}
+		}
+		if d.proxy, err = newProxyDialer(u, tlsConfig, d); err != nil {
+			return nil, err
+		}
+	}
+	return d, nil
+}
+
+// splitHostList 按冒号拆分为 n 段, 方括号中的 IPv6 地址作为一段
+func splitHostList(s string, n int) ([]string, error) {
+	var parts []string
+	for len(parts) < n-1 {
+		var part string
+		if strings.HasPrefix(s, "[") {
+			end := strings.Index(s, "]")
+			if end < 0 || end+1 < len(s) && s[end+1] != ':' {
+				return nil, fmt.Errorf("IPv6 地址格式错误")
+			}
+			part, s = s[1:end], strings.TrimPrefix(s[end+1:], ":")
+		} else {
+			i := strings.IndexByte(s, ':')
+			if i < 0 {
+				return nil, fmt.Errorf("应包含 %d 段", n)
+			}
+			part, s = s[:i], s[i+1:]
+		}
+		parts = append(parts, part)
+	}
+	return append(parts, strings.Trim(s, "[]")), nil
+}
+
+// applyRules 返回第一条匹配规则改写后的地址
+func applyRules(rules []connectRule, host, port string) (string, string) {
+	for _, r := range rules {
+		if (r.host == "" || strings.EqualFold(r.host, host)) && (r.port == "" || r.port == port) {
+			if r.toHost != "" {
+				host = r.toHost
+			}
+			if r.toPort != "" {
+				port = r.toPort
+			}
+			break
+		}
+	}
+	return host, port
+}
+
+// address 实际连接的地址: 先应用 connect_to, 再对结果应用 resolve
+func (d *targetDialer) address(addr string) string {
+	if len(d.connectTo) == 0 && len(d.resolve) == 0 {
+		return addr
+	}
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	host, port = applyRules(d.connectTo, host, port)
+	host, port = applyRules(d.resolve, host, port)
+	return net.JoinHostPort(host, port)
+}
+
+// DialContext 建立连接, 可用作 http.Transport.DialContext
+//
+// 达到连接总数上限时等待已有连接关闭。
+func (d *targetDialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	d.dials.Add(1)
+
+	release, err := d.pool.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := d.dial(ctx, network, addr)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	return d.pool.track(conn, release), nil
+}
+
+// dial 按 Unix 套接字、地址覆盖和代理的配置建立连接
+func (d *targetDialer) dial(ctx context.Context, network, addr string) (net.Conn, error) {
+	if d.unixSocket != "" {
+		var dialer net.Dialer
+		return dialer.DialContext(ctx, "unix", d.unixSocket)
+	}
+	addr = d.address(addr)
+	if d.proxy != nil {
+		return d.proxy.dial(ctx, d, addr)
+	}
+	return d.dialDirect(ctx, network, addr)
+}
+
+// dialDirect 直接连接 addr, 按配置绑定源地址
+func (d *targetDialer) dialDirect(ctx context.Context, network, addr string) (net.Conn, error) {
+	var dialer net.Dialer
+	if n := uint64(len(d.sources)); n > 0 {
+		dialer.LocalAddr = &net.TCPAddr{IP: d.sources[(d.next.Add(1)-1)%n]}
+	}
+	return dialer.DialContext(ctx, network, addr)
+}
+
+// dialQUIC HTTP/3 的连接函数, 只支持地址覆盖
+func (d *targetDialer) dialQUIC(ctx context.Context, addr string, tlsCfg *tls.Config, cfg *quic.Config) (quic.EarlyConnection, error) {
+	d.dials.Add(1)
+	return quic.DialAddrEarly(ctx, d.address(addr), tlsCfg, cfg)
+}
+
+// addStats 写入连接周转统计, 配置了代理时还写入代理统计
+func (d *targetDialer) addStats(stats map[string]float64) {
+	d.pool.addStats(stats)
+	if d.proxy != nil {
+		d.proxy.addStats(stats)
+	}
+}
+
+// Close 中止等待连接名额的拨号
+func (d *targetDialer) Close() {
+	d.pool.close()
+}
+
+// rewritesAddress 是否配置了地址覆盖
+func (d *targetDialer) rewritesAddress() bool {
+	return len(d.connectTo) > 0 || len(d.resolve) > 0
+}
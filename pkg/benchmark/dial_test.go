@@ -0,0 +1,137 @@
+package benchmark
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"httpbench/pkg/config"
+)
+
+// TestTargetDialer 测试 Unix 套接字、地址覆盖和源地址绑定
+func TestTargetDialer(t *testing.T) {
+	type seen struct {
+		mu      sync.Mutex
+		hosts   map[string]int
+		sni     map[string]int
+		remotes map[string]int
+	}
+	record := func(s *seen) http.HandlerFunc {
+		s.hosts, s.sni, s.remotes = map[string]int{}, map[string]int{}, map[string]int{}
+		return func(w http.ResponseWriter, r *http.Request) {
+			s.mu.Lock()
+			s.hosts[r.Host]++
+			if r.TLS != nil {
+				s.sni[r.TLS.ServerName]++
+			}
+			if ip, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+				s.remotes[ip]++
+			}
+			s.mu.Unlock()
+			io.WriteString(w, "ok")
+		}
+	}
+
+	run := func(cfg *config.Config) *Results {
+		t.Helper()
+		cfg.Load = config.LoadConfig{Concurrency: 2, TotalRequests: 20}
+		cfg.Target.Timeout = 2 * time.Second
+		results := runBenchmark(t, cfg)
+		if results.SuccessRequests != 20 {
+			t.Errorf("成功 %d, 错误 %v", results.SuccessRequests, results.ErrorsByType)
+		}
+		return results
+	}
+
+	// Unix 套接字, std 和 raw 引擎
+	socket := filepath.Join(t.TempDir(), "sidecar.sock")
+	lis, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatalf("监听Unix套接字失败: %v", err)
+	}
+	var unixSeen seen
+	unixServer := httptest.NewUnstartedServer(record(&unixSeen))
+	unixServer.Listener = lis
+	unixServer.Start()
+	defer unixServer.Close()
+	for _, engine := range []config.Engine{config.EngineStandard, config.EngineRaw} {
+		run(&config.Config{
+			Target:   config.TargetConfig{URL: "http://sidecar.local/health", UnixSocket: socket},
+			Protocol: config.ProtocolConfig{KeepAlive: true, Engine: engine},
+		})
+	}
+	if unixSeen.hosts["sidecar.local"] != 40 {
+		t.Errorf("Unix套接字: Host %v", unixSeen.hosts)
+	}
+
+	// resolve: 连接到 127.0.0.1, Host 和 SNI 保持为域名
+	var tlsSeen seen
+	tlsServer := httptest.NewTLSServer(record(&tlsSeen))
+	defer tlsServer.Close()
+	_, port, _ := net.SplitHostPort(tlsServer.Listener.Addr().String())
+	host := net.JoinHostPort("api.example.test", port)
+	run(&config.Config{
+		Target: config.TargetConfig{URL: "https://" + host + "/", Resolve: []string{"api.example.test:" + port + ":127.0.0.1"}},
+		TLS:    config.TLSConfig{Enabled: true, InsecureSkipVerify: true},
+	})
+	if tlsSeen.hosts[host] != 20 || tlsSeen.sni["api.example.test"] != 20 {
+		t.Errorf("resolve: Host %v, SNI %v", tlsSeen.hosts, tlsSeen.sni)
+	}
+
+	// connect_to 改写端口, 源地址轮流绑定 (每个请求新建连接)
+	var plainSeen seen
+	plainServer := httptest.NewServer(record(&plainSeen))
+	defer plainServer.Close()
+	_, port, _ = net.SplitHostPort(plainServer.Listener.Addr().String())
+	results := run(&config.Config{
+		Target: config.TargetConfig{
+			URL:         "http://api.example.test/",
+			ConnectTo:   []string{"api.example.test:80:127.0.0.1:" + port},
+			SourceAddrs: []string{"127.0.0.2", "127.0.0.3"},
+		},
+		Protocol: config.ProtocolConfig{KeepAlive: false},
+	})
+	if plainSeen.hosts["api.example.test"] != 20 {
+		t.Errorf("connect_to: Host %v", plainSeen.hosts)
+	}
+	if plainSeen.remotes["127.0.0.2"] != 10 || plainSeen.remotes["127.0.0.3"] != 10 {
+		t.Errorf("源地址: %v", plainSeen.remotes)
+	}
+	if results.ProtocolStats["connections_opened"] != 20 {
+		t.Errorf("连接数: %v", results.ProtocolStats)
+	}
+
+	// 规则解析: 空字段匹配任意值, connect_to 的结果再经过 resolve
+	d, err := newTargetDialer(&config.Config{Target: config.TargetConfig{
+		ConnectTo: []string{"[::1]:443:[fe80::1]:", "api.example.test:::8443", ":81:backend.test:"},
+		Resolve:   []string{"api.example.test:8443:[2001:db8::1]"},
+	}})
+	if err != nil {
+		t.Fatalf("解析规则失败: %v", err)
+	}
+	for addr, want := range map[string]string{
+		"[::1]:443":            "[fe80::1]:443",
+		"api.example.test:443": "[2001:db8::1]:8443",
+		"other.test:81":        "backend.test:81",
+		"other.test:80":        "other.test:80",
+	} {
+		if got := d.address(addr); got != want {
+			t.Errorf("address(%s) = %s, 期望 %s", addr, got, want)
+		}
+	}
+	for _, target := range []config.TargetConfig{
+		{Resolve: []string{"api.example.test:443"}},
+		{ConnectTo: []string{"a:1:b"}},
+		{ConnectTo: []string{"[::1:443:a:1"}},
+		{SourceAddrs: []string{"not-an-ip"}},
+	} {
+		if _, err := newTargetDialer(&config.Config{Target: target}); err == nil {
+			t.Errorf("%+v 应解析失败", target)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package benchmark
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"httpbench/pkg/config"
+)
+
+// TestGracefulDrain 测试停止后排空进行中的请求及部分结果标记
+func TestGracefulDrain(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		delay, _ := time.ParseDuration(r.URL.Query().Get("delay"))
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+		}
+		w.Write([]byte("OK"))
+	}))
+	defer server.Close()
+
+	newConfig := func(delay time.Duration, duration, drain time.Duration) *config.Config {
+		return &config.Config{
+			Target: config.TargetConfig{
+				URL:     server.URL + "?delay=" + delay.String(),
+				Method:  "GET",
+				Timeout: 5 * time.Second,
+			},
+			Load: config.LoadConfig{
+				Concurrency:  4,
+				Duration:     duration,
+				DrainTimeout: drain,
+			},
+			Protocol: config.ProtocolConfig{
+				KeepAlive: true,
+			},
+		}
+	}
+
+	// 到达持续时间后进行中的请求应正常完成,不计为错误
+	t.Run("deadline", func(t *testing.T) {
+		results := runBenchmark(t, newConfig(150*time.Millisecond, 200*time.Millisecond, time.Second))
+		if results.FailedRequests != 0 || results.Partial {
+			t.Errorf("不应有失败请求或部分结果: failed=%d partial=%v errors=%v",
+				results.FailedRequests, results.Partial, results.ErrorsByType)
+		}
+		if results.TotalRequests != 8 {
+			t.Errorf("请求数不匹配: got %d, want 8", results.TotalRequests)
+		}
+	})
+
+	// 停止后在排空时间内完成的请求计入统计
+	t.Run("stop", func(t *testing.T) {
+		bench := newTestBenchmark(t, newConfig(300*time.Millisecond, time.Minute, time.Second))
+		defer bench.Close()
+
+		time.AfterFunc(100*time.Millisecond, bench.Stop)
+		results, err := bench.Run(context.Background())
+		if err != nil {
+			t.Fatalf("运行基准测试失败: %v", err)
+		}
+		if !results.Partial {
+			t.Error("停止后的结果应标记为部分结果")
+		}
+		if results.TotalRequests != 4 || results.FailedRequests != 0 || results.Canceled != 0 {
+			t.Errorf("排空结果不匹配: total=%d failed=%d canceled=%d",
+				results.TotalRequests, results.FailedRequests, results.Canceled)
+		}
+	})
+
+	// 超过排空时间的请求被取消且不计入统计
+	t.Run("drain timeout", func(t *testing.T) {
+		bench := newTestBenchmark(t, newConfig(5*time.Second, time.Minute, 100*time.Millisecond))
+		defer bench.Close()
+
+		time.AfterFunc(100*time.Millisecond, bench.Stop)
+		start := time.Now()
+		results, err := bench.Run(context.Background())
+		if err != nil {
+			t.Fatalf("运行基准测试失败: %v", err)
+		}
+		if elapsed := time.Since(start); elapsed > 2*time.Second {
+			t.Errorf("排空超时未生效: %v", elapsed)
+		}
+		if !results.Partial || results.Canceled != 4 {
+			t.Errorf("应取消4个请求并标记部分结果: partial=%v canceled=%d", results.Partial, results.Canceled)
+		}
+		if results.TotalRequests != 0 || results.FailedRequests != 0 || len(results.ErrorsByType) != 0 {
+			t.Errorf("取消的请求不应计入统计: total=%d errors=%v", results.TotalRequests, results.ErrorsByType)
+		}
+	})
+}
@@ -0,0 +1,117 @@
+package benchmark
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"httpbench/pkg/config"
+)
+
+// Executor 请求执行器, 负责具体协议的收发
+//
+// 调度、限速、统计和报告由 Benchmark 完成, 执行器只需实现单次请求。
+// Execute 会被多个工作协程并发调用。
+type Executor interface {
+	// Prepare 在测试开始前调用一次, 可用于建立连接或预热
+	Prepare(ctx context.Context) error
+
+	// Execute 执行一次请求; 返回错误表示请求未能发出 (如构建请求失败),
+	// 此时只记录错误, 不计入请求数和延迟
+	Execute(ctx context.Context, workerID int) (Result, error)
+
+	// Stats 协议特定的统计, 写入测试结果的 ProtocolStats (NaN 和 ±Inf 被忽略)
+	Stats() map[string]float64
+
+	// Close 释放连接等资源
+	Close() error
+}
+
+// Result 单个请求的执行结果
+type Result struct {
+	Start   time.Time
+	Latency time.Duration
+
+	// 状态码, 0 表示未收到响应
+	StatusCode    int
+	BytesReceived int64
+	BytesSent     int64
+	Success       bool
+
+	// 失败分类及原因, 分类计入 ErrorsByType
+	ErrorType string
+	Err       error
+
+	// 端点统计的名称, 为空时使用目标的方法和URL
+	Endpoint string
+}
+
+// Fail 标记请求失败
+func (r *Result) Fail(errorType string, err error) {
+	r.Success = false
+	r.ErrorType = errorType
+	r.Err = err
+}
+
+// ProtocolFactory 根据配置创建执行器
+type ProtocolFactory func(cfg *config.Config) (Executor, error)
+
+var (
+	protocolsMu sync.RWMutex
+	protocols   = make(map[string]ProtocolFactory)
+)
+
+// RegisterProtocol 注册协议, 配置中 protocol.type 为 name 时使用
+func RegisterProtocol(name string, factory ProtocolFactory) {
+	protocolsMu.Lock()
+	defer protocolsMu.Unlock()
+
+	if factory == nil {
+		panic("benchmark: 协议 " + name + " 的工厂函数为 nil")
+	}
+	protocols[name] = factory
+}
+
+// Protocols 已注册的协议名
+func Protocols() []string {
+	protocolsMu.RLock()
+	defer protocolsMu.RUnlock()
+
+	names := make([]string, 0, len(protocols))
+	for name := range protocols {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// protocolName 配置使用的协议, 未指定类型时由 HTTP 版本和引擎推断
+func protocolName(cfg *config.Config) string {
+	switch {
+	case cfg.Protocol.Type != "":
+		return cfg.Protocol.Type
+	case cfg.Protocol.HTTP3Enabled:
+		return "http3"
+	case cfg.Protocol.HTTP2Enabled:
+		return "http2"
+	case cfg.Protocol.Engine == config.EngineRaw:
+		return "raw"
+	default:
+		return "http1"
+	}
+}
+
+// newExecutor 按配置创建执行器
+func newExecutor(cfg *config.Config) (Executor, error) {
+	name := protocolName(cfg)
+
+	protocolsMu.RLock()
+	factory, ok := protocols[name]
+	protocolsMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("未知的协议: %s (可用: %v)", name, Protocols())
+	}
+	return factory(cfg)
+}
@@ -0,0 +1,148 @@
+package benchmark
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"httpbench/pkg/config"
+)
+
+// TestRawEngine 测试通过精简 HTTP/1.1 引擎运行基准测试
+func TestRawEngine(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if body, _ := io.ReadAll(r.Body); string(body) != "payload" {
+			http.Error(w, "bad body", http.StatusBadRequest)
+			return
+		}
+		for i := 0; i < 5; i++ {
+			fmt.Fprintf(w, "chunk-%d;", i)
+			w.(http.Flusher).Flush()
+		}
+	}))
+	defer server.Close()
+
+	results := runBenchmark(t, &config.Config{
+		Target: config.TargetConfig{
+			URL:     server.URL + "/chunked",
+			Method:  "POST",
+			Body:    "payload",
+			Timeout: 5 * time.Second,
+		},
+		Load: config.LoadConfig{
+			Concurrency:   4,
+			TotalRequests: 200,
+		},
+		Protocol: config.ProtocolConfig{
+			KeepAlive: true,
+			Engine:    config.EngineRaw,
+		},
+		Validation: config.ValidationConfig{
+			BodyValidation: config.BodyValidation{Contains: []string{"chunk-4;"}},
+		},
+	})
+	if results.SuccessRequests != 200 || results.FailedRequests != 0 {
+		t.Errorf("成功 %d, 失败 %d, 期望全部成功", results.SuccessRequests, results.FailedRequests)
+	}
+}
+
+// fakeExecutor 测试用执行器, 每10次调用中1次失败, 1次无法构建请求
+type fakeExecutor struct {
+	prepared atomic.Bool
+	closed   atomic.Bool
+	calls    atomic.Int64
+}
+
+func (e *fakeExecutor) Prepare(ctx context.Context) error {
+	e.prepared.Store(true)
+	return nil
+}
+
+func (e *fakeExecutor) Execute(ctx context.Context, workerID int) (Result, error) {
+	n := e.calls.Add(1)
+	if n%10 == 0 {
+		return Result{}, fmt.Errorf("无法构建请求")
+	}
+	result := Result{Start: time.Now(), Latency: time.Millisecond, StatusCode: 1, BytesSent: 2, BytesReceived: 3}
+	if n%10 == 5 {
+		result.Fail("fake", fmt.Errorf("失败"))
+	} else {
+		result.Success = true
+	}
+	return result, nil
+}
+
+func (e *fakeExecutor) Stats() map[string]float64 {
+	return map[string]float64{"calls": float64(e.calls.Load())}
+}
+
+func (e *fakeExecutor) Close() error {
+	e.closed.Store(true)
+	return nil
+}
+
+// TestCustomExecutor 测试注册的协议和自定义执行器
+func TestCustomExecutor(t *testing.T) {
+	exec := &fakeExecutor{}
+	RegisterProtocol("fake", func(cfg *config.Config) (Executor, error) {
+		return exec, nil
+	})
+
+	cfg := &config.Config{
+		Target: config.TargetConfig{URL: "fake://target"},
+		Load: config.LoadConfig{
+			Concurrency:   2,
+			TotalRequests: 100,
+		},
+		Protocol: config.ProtocolConfig{Type: "fake"},
+	}
+	results := runBenchmark(t, cfg)
+	if !exec.prepared.Load() || !exec.closed.Load() {
+		t.Errorf("Prepare/Close 未调用: %v/%v", exec.prepared.Load(), exec.closed.Load())
+	}
+	// 每10次调用中1次未发出, 1次失败
+	if results.TotalRequests != 90 || results.SuccessRequests != 80 {
+		t.Errorf("请求数 %d/%d, 期望 80/90", results.SuccessRequests, results.TotalRequests)
+	}
+	if results.ErrorsByType["fake"] != 10 || results.ErrorsByType["request_creation"] != 10 {
+		t.Errorf("错误统计 %v", results.ErrorsByType)
+	}
+	if results.StatusCodes[1] != 90 || results.BytesSent != 180 || results.BytesReceived != 270 {
+		t.Errorf("状态码 %v, 发送 %d, 接收 %d", results.StatusCodes, results.BytesSent, results.BytesReceived)
+	}
+	if results.Protocol != "fake" || results.ProtocolStats["calls"] != 100 {
+		t.Errorf("协议统计 %s %v", results.Protocol, results.ProtocolStats)
+	}
+
+	cfg.Protocol.Type = "missing"
+	if _, err := New(cfg); err == nil || !strings.Contains(err.Error(), "missing") {
+		t.Errorf("未知协议应返回错误, 得到 %v", err)
+	}
+}
+
+// TestNewWithExecutorCloses 测试创建失败时关闭传入的执行器
+func TestNewWithExecutorCloses(t *testing.T) {
+	for name, output := range map[string]config.OutputConfig{
+		"sink":   {Sinks: []config.SinkConfig{{Type: "unknown", Address: "127.0.0.1:1"}}},
+		"rawlog": {RawLog: config.RawLogConfig{File: filepath.Join(t.TempDir(), "missing", "raw.bin")}},
+	} {
+		exec := &fakeExecutor{}
+		_, err := NewWithExecutor(&config.Config{
+			Target: config.TargetConfig{URL: "fake://target"},
+			Output: output,
+		}, exec)
+		if err == nil {
+			t.Errorf("%s: 应返回错误", name)
+		}
+		if !exec.closed.Load() {
+			t.Errorf("%s: 创建失败时未关闭执行器", name)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package benchmark
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+
+	"httpbench/pkg/config"
+	"httpbench/pkg/template"
+)
+
+// graphqlOperation 预编译的 GraphQL 操作
+type graphqlOperation struct {
+	// 请求体中 variables 之前的部分
+	prefix []byte
+	// variables 模板, 不含动作时请求体固定为 static
+	variables *template.Template
+	static    []byte
+
+	// 端点统计的名称
+	endpoint string
+}
+
+// graphqlOperationPattern 匹配具名操作定义
+var graphqlOperationPattern = regexp.MustCompile(`(?m)^\s*(?:query|mutation|subscription)\s+([_A-Za-z][_0-9A-Za-z]*)`)
+
+// newGraphQLOperations 读取查询并预先序列化请求体的固定部分
+func newGraphQLOperations(cfg config.GraphQLConfig, engine *template.Engine) ([]*graphqlOperation, error) {
+	var ops []*graphqlOperation
+	for i, opCfg := range cfg.All() {
+		query := opCfg.Query
+		if opCfg.QueryFile != "" {
+			data, err := os.ReadFile(opCfg.QueryFile)
+			if err != nil {
+				return nil, fmt.Errorf("读取GraphQL查询失败: %w", err)
+			}
+			query = string(data)
+		}
+
+		name := opCfg.OperationName
+		if name == "" {
+			// 只有一个具名操作时可以省略操作名
+			if matches := graphqlOperationPattern.FindAllStringSubmatch(query, 2); len(matches) == 1 {
+				name = matches[0][1]
+			}
+		}
+
+		prefix := map[string]interface{}{"query": query}
+		if opCfg.OperationName != "" {
+			prefix["operationName"] = opCfg.OperationName
+		}
+		data, err := json.Marshal(prefix)
+		if err != nil {
+			return nil, err
+		}
+
+		op := &graphqlOperation{endpoint: "GraphQL " + name}
+		if name == "" {
+			op.endpoint = "GraphQL #" + strconv.Itoa(i+1)
+		}
+
+		if opCfg.Variables == "" {
+			op.static = data
+		} else {
+			// 去掉末尾的 } 以便拼接 variables
+			op.prefix = append(data[:len(data)-1], `,"variables":`...)
+			variables, err := engine.Compile(opCfg.Variables, requestVars...)
+			if err != nil {
+				return nil, fmt.Errorf("解析GraphQL变量模板失败: %w", err)
+			}
+			if variables.Static() {
+				if !json.Valid([]byte(opCfg.Variables)) {
+					return nil, fmt.Errorf("GraphQL变量不是有效的JSON: %s", opCfg.Variables)
+				}
+				op.static = append(append(bytes.Clone(op.prefix), opCfg.Variables...), '}')
+			} else {
+				op.variables = variables
+			}
+		}
+		ops = append(ops, op)
+	}
+	return ops, nil
+}
+
+// render 渲染请求体
+func (op *graphqlOperation) render(buf *bytes.Buffer, vars map[string]interface{}) ([]byte, error) {
+	buf.Write(op.prefix)
+	if err := op.variables.Execute(buf, vars); err != nil {
+		return nil, fmt.Errorf("渲染GraphQL变量失败: %w", err)
+	}
+	buf.WriteByte('}')
+	return bytes.Clone(buf.Bytes()), nil
+}
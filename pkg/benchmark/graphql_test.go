@@ -0,0 +1,97 @@
+package benchmark
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"httpbench/pkg/config"
+)
+
+// TestGraphQL 测试 GraphQL 请求构建、errors 校验和按操作名统计
+func TestGraphQL(t *testing.T) {
+	var badVariables atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Query         string                 `json:"query"`
+			OperationName string                 `json:"operationName"`
+			Variables     map[string]interface{} `json:"variables"`
+		}
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.Contains(req.Query, "Broken"):
+			// GraphQL 错误通常仍返回 200
+			io.WriteString(w, `{"data":null,"errors":[{"message":"field not found"}]}`)
+		case req.OperationName == "GetUser":
+			if _, ok := req.Variables["id"].(float64); !ok {
+				badVariables.Add(1)
+			}
+			fmt.Fprintf(w, `{"data":{"user":{"id":%v}}}`, req.Variables["id"])
+		default:
+			if req.Variables["sku"] != "A-1" {
+				badVariables.Add(1)
+			}
+			io.WriteString(w, `{"data":{"createOrder":{"ok":true}}}`)
+		}
+	}))
+	defer server.Close()
+
+	queryFile := filepath.Join(t.TempDir(), "broken.graphql")
+	os.WriteFile(queryFile, []byte("# 只有一个操作, 操作名可省略\nquery Broken {\n  missing\n}\n"), 0o644)
+
+	cfg := &config.Config{
+		Target: config.TargetConfig{URL: server.URL + "/graphql", Timeout: 2 * time.Second},
+		Load:   config.LoadConfig{Concurrency: 3, TotalRequests: 90},
+		Request: config.RequestConfig{GraphQL: config.GraphQLConfig{Operations: []config.GraphQLOperation{
+			{
+				Query:         "query GetUser($id: Int!) { user(id: $id) { id } }\nquery Other { x }",
+				OperationName: "GetUser",
+				Variables:     `{"id": {{worker_id}}}`,
+			},
+			{
+				Query:     "mutation CreateOrder($sku: String!) { createOrder(sku: $sku) { ok } }",
+				Variables: `{"sku": "A-1"}`,
+			},
+			{QueryFile: queryFile},
+		}}},
+	}
+	results := runBenchmark(t, cfg)
+	if results.SuccessRequests != 60 || results.ErrorsByType["graphql"] != 30 || results.StatusCodes[200] != 90 {
+		t.Errorf("成功 %d, 错误 %v, 状态码 %v", results.SuccessRequests, results.ErrorsByType, results.StatusCodes)
+	}
+	if n := badVariables.Load(); n != 0 {
+		t.Errorf("%d 个请求的变量不正确", n)
+	}
+	for name, want := range map[string]int64{"GraphQL GetUser": 30, "GraphQL CreateOrder": 30, "GraphQL Broken": 30} {
+		es, ok := results.Endpoints[name]
+		if !ok || es.TotalRequests != want {
+			t.Errorf("端点 %s: %+v (全部端点 %v)", name, es, results.Endpoints)
+		}
+	}
+	if es := results.Endpoints["GraphQL Broken"]; es.FailedRequests != 30 {
+		t.Errorf("Broken 应全部失败: %+v", es)
+	}
+
+	// query 和 query_file 须二选一
+	cfg.Request.GraphQL = config.GraphQLConfig{GraphQLOperation: config.GraphQLOperation{Query: "{ a }", QueryFile: queryFile}}
+	if err := cfg.Validate(); err == nil {
+		t.Error("同时配置 query 和 query_file 应报错")
+	}
+}
@@ -0,0 +1,508 @@
+package benchmark
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode"
+
+	"github.com/HdrHistogram/hdrhistogram-go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	rpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"httpbench/pkg/config"
+	"httpbench/pkg/template"
+)
+
+func init() {
+	RegisterProtocol("grpc", newGRPCExecutor)
+}
+
+// grpcVars 每条消息传入模板的变量
+var grpcVars = []string{"worker_id", "timestamp", "message_index"}
+
+// grpcExecutor gRPC 执行器
+//
+// 请求和响应均为 dynamicpb 动态消息, 不需要生成代码。
+// 一元调用和各类流式调用都以整个调用为一个请求, 结果按 gRPC 状态码统计。
+type grpcExecutor struct {
+	cfg      config.GRPCConfig
+	target   string
+	creds    credentials.TransportCredentials
+	dialer   *targetDialer
+	timeout  time.Duration
+	metadata metadata.MD
+	messages int
+
+	template *template.Template
+	vars     sync.Pool
+
+	// Prepare 中建立连接并解析方法
+	conns      []*grpc.ClientConn
+	method     protoreflect.MethodDescriptor
+	fullMethod string
+	desc       grpc.StreamDesc
+	// 静态模板预先解析的消息, 只读共享
+	static proto.Message
+
+	sent     atomic.Int64
+	received atomic.Int64
+
+	mu    sync.Mutex
+	codes map[codes.Code]*hdrhistogram.Histogram
+}
+
+// newGRPCExecutor 创建 gRPC 执行器
+func newGRPCExecutor(cfg *config.Config) (Executor, error) {
+	target, err := url.Parse(cfg.Target.URL)
+	if err != nil {
+		return nil, fmt.Errorf("无效的gRPC地址: %w", err)
+	}
+	if (target.Scheme != "grpc" && target.Scheme != "grpcs") || target.Host == "" {
+		return nil, fmt.Errorf("gRPC地址须为 grpc://host:port 或 grpcs://host:port: %s", cfg.Target.URL)
+	}
+
+	grpcCfg := cfg.Protocol.GRPC
+	if grpcCfg.Method == "" {
+		return nil, fmt.Errorf("gRPC模式需要配置 protocol.grpc.method")
+	}
+
+	creds := insecure.NewCredentials()
+	if target.Scheme == "grpcs" {
+		tlsConfig, err := createTLSConfig(cfg.TLS)
+		if err != nil {
+			return nil, err
+		}
+		creds = credentials.NewTLS(tlsConfig)
+	}
+
+	dialer, err := newTargetDialer(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	messageText := grpcCfg.Message
+	if strings.TrimSpace(messageText) == "" {
+		messageText = "{}"
+	}
+	templateCfg := cfg.Request.Template
+	templateCfg.Enabled = true
+	engine := template.New(templateCfg)
+	tmpl, err := engine.Compile(messageText, grpcVars...)
+	if err != nil {
+		return nil, fmt.Errorf("解析消息模板失败: %w", err)
+	}
+
+	md := metadata.New(nil)
+	for key, value := range grpcCfg.Metadata {
+		md.Append(key, value)
+	}
+
+	e := &grpcExecutor{
+		cfg:      grpcCfg,
+		target:   target.Host,
+		creds:    creds,
+		dialer:   dialer,
+		timeout:  cfg.Target.Timeout,
+		metadata: md,
+		messages: max(grpcCfg.StreamMessages, 1),
+		template: tmpl,
+		conns:    make([]*grpc.ClientConn, max(grpcCfg.Connections, 1)),
+		codes:    make(map[codes.Code]*hdrhistogram.Histogram),
+	}
+	e.vars.New = func() interface{} { return engine.Vars() }
+	return e, nil
+}
+
+// Prepare 建立连接, 解析方法描述符并检查消息模板
+func (e *grpcExecutor) Prepare(ctx context.Context) error {
+	for i := range e.conns {
+		conn, err := grpc.DialContext(ctx, e.target,
+			grpc.WithTransportCredentials(e.creds),
+			grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+				return e.dialer.DialContext(ctx, "tcp", addr)
+			}),
+		)
+		if err != nil {
+			return fmt.Errorf("连接gRPC服务失败: %w", err)
+		}
+		e.conns[i] = conn
+	}
+
+	method, err := e.resolveMethod(ctx)
+	if err != nil {
+		return err
+	}
+	e.method = method
+	e.fullMethod = fmt.Sprintf("/%s/%s", method.Parent().FullName(), method.Name())
+	e.desc = grpc.StreamDesc{
+		StreamName:    string(method.Name()),
+		ClientStreams: method.IsStreamingClient(),
+		ServerStreams: method.IsStreamingServer(),
+	}
+
+	// 先渲染一次, 模板或字段错误时直接报错
+	msg, err := e.build(0, 0)
+	if err != nil {
+		return err
+	}
+	if e.template.Static() {
+		e.static = msg
+	}
+	return nil
+}
+
+// Execute 完成一次调用
+func (e *grpcExecutor) Execute(ctx context.Context, workerID int) (Result, error) {
+	count := 1
+	if e.desc.ClientStreams {
+		count = e.messages
+	}
+	reqs := make([]proto.Message, count)
+	for i := range reqs {
+		msg, err := e.build(workerID, i)
+		if err != nil {
+			return Result{}, err
+		}
+		reqs[i] = msg
+	}
+
+	result := Result{Start: time.Now()}
+	if e.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.timeout)
+		defer cancel()
+	}
+	if len(e.metadata) > 0 {
+		ctx = metadata.NewOutgoingContext(ctx, e.metadata)
+	}
+
+	conn := e.conns[workerID%len(e.conns)]
+	var err error
+	if !e.desc.ClientStreams && !e.desc.ServerStreams {
+		resp := dynamicpb.NewMessage(e.method.Output())
+		err = conn.Invoke(ctx, e.fullMethod, reqs[0], resp)
+		e.sent.Add(1)
+		result.BytesSent = int64(proto.Size(reqs[0]))
+		if err == nil {
+			e.received.Add(1)
+			result.BytesReceived = int64(proto.Size(resp))
+		}
+	} else {
+		err = e.stream(ctx, conn, reqs, &result)
+	}
+	result.Latency = time.Since(result.Start)
+
+	code := status.Code(err)
+	e.record(code, result.Latency)
+	if err != nil {
+		result.Fail("grpc_"+grpcCodeName(code), err)
+	} else {
+		result.Success = true
+	}
+	return result, nil
+}
+
+// stream 完成一次流式调用: 发送全部消息后关闭发送方向, 接收直到服务端结束;
+// 双向流的发送和接收同时进行
+func (e *grpcExecutor) stream(ctx context.Context, conn *grpc.ClientConn, reqs []proto.Message, result *Result) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := conn.NewStream(ctx, &e.desc, e.fullMethod)
+	if err != nil {
+		return err
+	}
+
+	sent := make(chan error, 1)
+	send := func() {
+		for _, req := range reqs {
+			if err := stream.SendMsg(req); err != nil {
+				// io.EOF 表示服务端已结束调用, 状态由 RecvMsg 返回
+				if err == io.EOF {
+					err = nil
+				}
+				sent <- err
+				return
+			}
+			e.sent.Add(1)
+			result.BytesSent += int64(proto.Size(req))
+		}
+		sent <- stream.CloseSend()
+	}
+	if e.desc.ClientStreams && e.desc.ServerStreams {
+		go send()
+	} else {
+		send()
+	}
+
+	for {
+		resp := dynamicpb.NewMessage(e.method.Output())
+		if err := stream.RecvMsg(resp); err != nil {
+			if err == io.EOF {
+				break
+			}
+			cancel()
+			<-sent
+			return err
+		}
+		e.received.Add(1)
+		result.BytesReceived += int64(proto.Size(resp))
+
+		// 客户端流只有一条响应
+		if !e.desc.ServerStreams {
+			break
+		}
+	}
+	return <-sent
+}
+
+// build 渲染并解析第 index 条请求消息
+func (e *grpcExecutor) build(workerID, index int) (proto.Message, error) {
+	if e.static != nil {
+		return e.static, nil
+	}
+
+	vars := e.vars.Get().(map[string]interface{})
+	vars["worker_id"] = workerID
+	vars["timestamp"] = time.Now().Unix()
+	vars["message_index"] = index
+	buf := getBuffer()
+	defer putBuffer(buf)
+	err := e.template.Execute(buf, vars)
+	e.vars.Put(vars)
+	if err != nil {
+		return nil, fmt.Errorf("渲染消息模板失败: %w", err)
+	}
+
+	msg := dynamicpb.NewMessage(e.method.Input())
+	if err := protojson.Unmarshal(buf.Bytes(), msg); err != nil {
+		return nil, fmt.Errorf("解析 %s 消息失败: %w", e.method.Input().FullName(), err)
+	}
+	return msg, nil
+}
+
+// record 按状态码记录调用耗时
+func (e *grpcExecutor) record(code codes.Code, latency time.Duration) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	hist, ok := e.codes[code]
+	if !ok {
+		hist = newDurationHistogram()
+		e.codes[code] = hist
+	}
+	recordDuration(hist, latency)
+}
+
+// Stats 消息数及各状态码的调用数和耗时
+func (e *grpcExecutor) Stats() map[string]float64 {
+	stats := map[string]float64{
+		"connections":       float64(len(e.conns)),
+		"messages_sent":     float64(e.sent.Load()),
+		"messages_received": float64(e.received.Load()),
+	}
+	e.dialer.addStats(stats)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for code, hist := range e.codes {
+		name := "code_" + grpcCodeName(code)
+		stats[name] = float64(hist.TotalCount())
+		addDurationStats(stats, name, hist)
+	}
+	return stats
+}
+
+// Close 关闭连接
+func (e *grpcExecutor) Close() error {
+	e.dialer.Close()
+	for _, conn := range e.conns {
+		if conn != nil {
+			conn.Close()
+		}
+	}
+	return nil
+}
+
+// resolveMethod 从描述符集或服务端反射中查找方法
+func (e *grpcExecutor) resolveMethod(ctx context.Context) (protoreflect.MethodDescriptor, error) {
+	service, method, err := splitGRPCMethod(e.cfg.Method)
+	if err != nil {
+		return nil, err
+	}
+
+	var files *protoregistry.Files
+	if len(e.cfg.ProtoSet) > 0 {
+		files, err = loadProtoSet(e.cfg.ProtoSet)
+	} else {
+		files, err = reflectFiles(ctx, e.conns[0], service)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	d, err := files.FindDescriptorByName(protoreflect.FullName(service))
+	if err != nil {
+		return nil, fmt.Errorf("未找到服务 %s: %w", service, err)
+	}
+	sd, ok := d.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%s 不是服务", service)
+	}
+	md := sd.Methods().ByName(protoreflect.Name(method))
+	if md == nil {
+		names := make([]string, sd.Methods().Len())
+		for i := range names {
+			names[i] = string(sd.Methods().Get(i).Name())
+		}
+		return nil, fmt.Errorf("服务 %s 没有方法 %s (可用: %v)", service, method, names)
+	}
+	return md, nil
+}
+
+// splitGRPCMethod 拆分 package.Service/Method 或 package.Service.Method
+func splitGRPCMethod(name string) (service, method string, err error) {
+	name = strings.TrimPrefix(name, "/")
+	i := strings.LastIndexAny(name, "/.")
+	if i <= 0 || i == len(name)-1 {
+		return "", "", fmt.Errorf("无效的gRPC方法名: %s (应为 package.Service/Method)", name)
+	}
+	return name[:i], name[i+1:], nil
+}
+
+// loadProtoSet 读取描述符集文件, 重复的文件只保留一份
+func loadProtoSet(paths []string) (*protoregistry.Files, error) {
+	set := &descriptorpb.FileDescriptorSet{}
+	seen := make(map[string]bool)
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("读取描述符集失败: %w", err)
+		}
+		var fds descriptorpb.FileDescriptorSet
+		if err := proto.Unmarshal(data, &fds); err != nil {
+			return nil, fmt.Errorf("解析描述符集 %s 失败: %w", path, err)
+		}
+		for _, fd := range fds.File {
+			if !seen[fd.GetName()] {
+				seen[fd.GetName()] = true
+				set.File = append(set.File, fd)
+			}
+		}
+	}
+
+	files, err := protodesc.NewFiles(set)
+	if err != nil {
+		return nil, fmt.Errorf("描述符集不完整 (生成时需要 --include_imports): %w", err)
+	}
+	return files, nil
+}
+
+// reflectFiles 通过服务端反射获取服务所在文件及其全部依赖
+func reflectFiles(ctx context.Context, conn *grpc.ClientConn, service string) (*protoregistry.Files, error) {
+	stream, err := rpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("服务端反射不可用: %w", err)
+	}
+	defer stream.CloseSend()
+
+	fetch := func(req *rpb.ServerReflectionRequest) ([]*descriptorpb.FileDescriptorProto, error) {
+		if err := stream.Send(req); err != nil {
+			return nil, fmt.Errorf("服务端反射不可用: %w", err)
+		}
+		resp, err := stream.Recv()
+		if err != nil {
+			return nil, fmt.Errorf("服务端反射不可用: %w", err)
+		}
+		if errResp := resp.GetErrorResponse(); errResp != nil {
+			return nil, fmt.Errorf("服务端反射: %s", errResp.GetErrorMessage())
+		}
+		var fds []*descriptorpb.FileDescriptorProto
+		for _, raw := range resp.GetFileDescriptorResponse().GetFileDescriptorProto() {
+			fd := &descriptorpb.FileDescriptorProto{}
+			if err := proto.Unmarshal(raw, fd); err != nil {
+				return nil, fmt.Errorf("解析反射结果失败: %w", err)
+			}
+			fds = append(fds, fd)
+		}
+		return fds, nil
+	}
+
+	fds, err := fetch(&rpb.ServerReflectionRequest{
+		MessageRequest: &rpb.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: service},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 服务端可能只返回部分依赖, 缺少的逐个按文件名获取
+	set := &descriptorpb.FileDescriptorSet{}
+	seen := make(map[string]bool)
+	var queue []*descriptorpb.FileDescriptorProto
+	enqueue := func(fds []*descriptorpb.FileDescriptorProto) {
+		for _, fd := range fds {
+			if !seen[fd.GetName()] {
+				seen[fd.GetName()] = true
+				queue = append(queue, fd)
+			}
+		}
+	}
+	enqueue(fds)
+	for len(queue) > 0 {
+		fd := queue[0]
+		queue = queue[1:]
+		set.File = append(set.File, fd)
+
+		for _, dep := range fd.GetDependency() {
+			if seen[dep] {
+				continue
+			}
+			more, err := fetch(&rpb.ServerReflectionRequest{
+				MessageRequest: &rpb.ServerReflectionRequest_FileByFilename{FileByFilename: dep},
+			})
+			if err != nil {
+				return nil, err
+			}
+			enqueue(more)
+		}
+	}
+	return protodesc.NewFiles(set)
+}
+
+// grpcCodeName 状态码的下划线形式, 如 DeadlineExceeded -> deadline_exceeded
+func grpcCodeName(code codes.Code) string {
+	if code == codes.OK {
+		return "ok"
+	}
+	var b strings.Builder
+	for i, r := range code.String() {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,215 @@
+package benchmark
+
+import (
+	"context"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	testgrpc "google.golang.org/grpc/interop/grpc_testing"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"httpbench/pkg/config"
+)
+
+// testGRPCServer 测试用 gRPC 服务, 要求请求携带 x-token 元数据
+type testGRPCServer struct {
+	testgrpc.UnimplementedTestServiceServer
+}
+
+func (testGRPCServer) UnaryCall(ctx context.Context, in *testgrpc.SimpleRequest) (*testgrpc.SimpleResponse, error) {
+	if md, _ := metadata.FromIncomingContext(ctx); len(md.Get("x-token")) == 0 {
+		return nil, status.Error(codes.Unauthenticated, "缺少 x-token")
+	}
+	if s := in.GetResponseStatus(); s.GetCode() != 0 {
+		return nil, status.Error(codes.Code(s.GetCode()), s.GetMessage())
+	}
+	return &testgrpc.SimpleResponse{Payload: &testgrpc.Payload{Body: make([]byte, in.GetResponseSize())}}, nil
+}
+
+func (testGRPCServer) StreamingOutputCall(in *testgrpc.StreamingOutputCallRequest, stream testgrpc.TestService_StreamingOutputCallServer) error {
+	for _, p := range in.GetResponseParameters() {
+		if err := stream.Send(&testgrpc.StreamingOutputCallResponse{Payload: &testgrpc.Payload{Body: make([]byte, p.GetSize())}}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (testGRPCServer) StreamingInputCall(stream testgrpc.TestService_StreamingInputCallServer) error {
+	var total int32
+	for {
+		in, err := stream.Recv()
+		if err == io.EOF {
+			return stream.SendAndClose(&testgrpc.StreamingInputCallResponse{AggregatedPayloadSize: total})
+		}
+		if err != nil {
+			return err
+		}
+		total += int32(len(in.GetPayload().GetBody()))
+	}
+}
+
+func (testGRPCServer) FullDuplexCall(stream testgrpc.TestService_FullDuplexCallServer) error {
+	for {
+		in, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		for _, p := range in.GetResponseParameters() {
+			if err := stream.Send(&testgrpc.StreamingOutputCallResponse{Payload: &testgrpc.Payload{Body: make([]byte, p.GetSize())}}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// TestGRPC 测试 gRPC 模式的四种调用方式、元数据、描述符来源和按状态码统计
+func TestGRPC(t *testing.T) {
+	// 第一个服务开启反射, 第二个只能通过描述符集调用
+	start := func(withReflection bool) string {
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("监听失败: %v", err)
+		}
+		server := grpc.NewServer()
+		testgrpc.RegisterTestServiceServer(server, testGRPCServer{})
+		if withReflection {
+			reflection.Register(server)
+		}
+		go server.Serve(lis)
+		t.Cleanup(server.Stop)
+		return "grpc://" + lis.Addr().String()
+	}
+	reflectURL := start(true)
+	plainURL := start(false)
+
+	// 生成包含全部依赖的描述符集
+	set := &descriptorpb.FileDescriptorSet{}
+	seen := make(map[string]bool)
+	var add func(fd protoreflect.FileDescriptor)
+	add = func(fd protoreflect.FileDescriptor) {
+		if seen[fd.Path()] {
+			return
+		}
+		seen[fd.Path()] = true
+		for i := 0; i < fd.Imports().Len(); i++ {
+			add(fd.Imports().Get(i).FileDescriptor)
+		}
+		set.File = append(set.File, protodesc.ToFileDescriptorProto(fd))
+	}
+	add(testgrpc.File_grpc_testing_test_proto)
+	data, err := proto.Marshal(set)
+	if err != nil {
+		t.Fatal(err)
+	}
+	protoset := filepath.Join(t.TempDir(), "test.protoset")
+	if err := os.WriteFile(protoset, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	newConfig := func(url string, grpcCfg config.GRPCConfig, total int) *config.Config {
+		return &config.Config{
+			Target:   config.TargetConfig{URL: url, Timeout: 2 * time.Second},
+			Load:     config.LoadConfig{Concurrency: 4, TotalRequests: total},
+			Protocol: config.ProtocolConfig{Type: "grpc", GRPC: grpcCfg},
+		}
+	}
+	token := map[string]string{"x-token": "secret"}
+
+	// 一元调用, 通过反射获取描述符, 消息模板按工作协程渲染
+	results := runBenchmark(t, newConfig(reflectURL, config.GRPCConfig{
+		Method:   "grpc.testing.TestService/UnaryCall",
+		Message:  `{"responseSize": {{add worker_id 10}}, "payload": {"body": "aGVsbG8="}}`,
+		Metadata: token,
+	}, 100))
+	ps := results.ProtocolStats
+	if results.SuccessRequests != 100 || ps["code_ok"] != 100 || ps["messages_received"] != 100 {
+		t.Errorf("一元调用: 成功 %d, 错误 %v, 协议统计 %v", results.SuccessRequests, results.ErrorsByType, ps)
+	}
+	if _, ok := ps["code_ok_ms_p99"]; !ok || results.BytesReceived < 100*12 {
+		t.Errorf("一元调用统计: 接收 %d, 协议统计 %v", results.BytesReceived, ps)
+	}
+
+	// 错误状态码按 gRPC 状态统计, 缺少元数据时为 unauthenticated
+	results = runBenchmark(t, newConfig(reflectURL, config.GRPCConfig{
+		Method:  "grpc.testing.TestService.UnaryCall",
+		Message: `{"responseStatus": {"code": 5, "message": "missing"}}`,
+	}, 20))
+	if results.ErrorsByType["grpc_unauthenticated"] != 20 || results.ProtocolStats["code_unauthenticated"] != 20 {
+		t.Errorf("缺少元数据: 错误 %v, 协议统计 %v", results.ErrorsByType, results.ProtocolStats)
+	}
+	results = runBenchmark(t, newConfig(reflectURL, config.GRPCConfig{
+		Method:   "grpc.testing.TestService/UnaryCall",
+		Message:  `{"responseStatus": {"code": 5, "message": "missing"}}`,
+		Metadata: token,
+	}, 20))
+	if results.ErrorsByType["grpc_not_found"] != 20 || results.ProtocolStats["code_not_found"] != 20 {
+		t.Errorf("错误状态码: 错误 %v, 协议统计 %v", results.ErrorsByType, results.ProtocolStats)
+	}
+
+	// 服务端流, 使用描述符集
+	results = runBenchmark(t, newConfig(plainURL, config.GRPCConfig{
+		Method:   "grpc.testing.TestService/StreamingOutputCall",
+		ProtoSet: []string{protoset},
+		Message:  `{"responseParameters": [{"size": 1}, {"size": 2}, {"size": 3}]}`,
+	}, 40))
+	if ps := results.ProtocolStats; results.SuccessRequests != 40 || ps["messages_received"] != 120 || ps["messages_sent"] != 40 {
+		t.Errorf("服务端流: 成功 %d, 错误 %v, 协议统计 %v", results.SuccessRequests, results.ErrorsByType, ps)
+	}
+
+	// 客户端流, 每次调用发送4条消息
+	results = runBenchmark(t, newConfig(plainURL, config.GRPCConfig{
+		Method:         "grpc.testing.TestService/StreamingInputCall",
+		ProtoSet:       []string{protoset},
+		Message:        `{"payload": {"body": "{{if eq message_index 0}}AA=={{else}}AAA={{end}}"}}`,
+		StreamMessages: 4,
+		Connections:    2,
+	}, 40))
+	if ps := results.ProtocolStats; results.SuccessRequests != 40 || ps["messages_sent"] != 160 || ps["messages_received"] != 40 || ps["connections"] != 2 {
+		t.Errorf("客户端流: 成功 %d, 错误 %v, 协议统计 %v", results.SuccessRequests, results.ErrorsByType, ps)
+	}
+
+	// 双向流, 每条请求对应两条响应
+	results = runBenchmark(t, newConfig(reflectURL, config.GRPCConfig{
+		Method:         "grpc.testing.TestService/FullDuplexCall",
+		Message:        `{"responseParameters": [{"size": 8}, {"size": 8}]}`,
+		StreamMessages: 3,
+	}, 40))
+	if ps := results.ProtocolStats; results.SuccessRequests != 40 || ps["messages_sent"] != 120 || ps["messages_received"] != 240 {
+		t.Errorf("双向流: 成功 %d, 错误 %v, 协议统计 %v", results.SuccessRequests, results.ErrorsByType, ps)
+	}
+
+	// 未实现的方法
+	results = runBenchmark(t, newConfig(reflectURL, config.GRPCConfig{Method: "grpc.testing.TestService/EmptyCall"}, 5))
+	if results.ErrorsByType["grpc_unimplemented"] != 5 {
+		t.Errorf("未实现的方法: 错误 %v", results.ErrorsByType)
+	}
+
+	// 未知方法和无效消息在开始前报错
+	for _, grpcCfg := range []config.GRPCConfig{
+		{Method: "grpc.testing.TestService/NoSuchCall"},
+		{Method: "grpc.testing.TestService/UnaryCall", Message: `{"noSuchField": 1}`},
+		{Method: "grpc.testing.TestService/UnaryCall", ProtoSet: []string{protoset + ".missing"}},
+	} {
+		bench := newTestBenchmark(t, newConfig(reflectURL, grpcCfg, 1))
+		if _, err := bench.Run(context.Background()); err == nil {
+			t.Errorf("%+v 应在开始前报错", grpcCfg)
+		}
+		bench.Close()
+	}
+}
@@ -0,0 +1,33 @@
+package benchmark
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"httpbench/pkg/config"
+)
+
+// newTestBenchmark 按配置创建丢弃运行日志的基准测试器, 由调用方关闭
+func newTestBenchmark(t testing.TB, cfg *config.Config) *Benchmark {
+	t.Helper()
+	bench, err := New(cfg)
+	if err != nil {
+		t.Fatalf("创建基准测试器失败: %v", err)
+	}
+	bench.SetLogOutput(io.Discard)
+	return bench
+}
+
+// runBenchmark 按配置运行一次基准测试并返回结果, 创建或运行失败时终止测试
+func runBenchmark(t testing.TB, cfg *config.Config) *Results {
+	t.Helper()
+	bench := newTestBenchmark(t, cfg)
+	defer bench.Close()
+
+	results, err := bench.Run(context.Background())
+	if err != nil {
+		t.Fatalf("运行失败: %v", err)
+	}
+	return results
+}
@@ -0,0 +1,292 @@
+package benchmark
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/quic-go/quic-go/http3"
+	"golang.org/x/net/http2"
+
+	"httpbench/pkg/config"
+	"httpbench/pkg/rawhttp"
+	"httpbench/pkg/template"
+	"httpbench/pkg/validator"
+)
+
+func init() {
+	RegisterProtocol("http1", httpProtocol(newStdTransport))
+	RegisterProtocol("http2", httpProtocol(newStdTransport))
+	RegisterProtocol("http3", httpProtocol(newHTTP3Transport))
+	RegisterProtocol("raw", httpProtocol(newRawTransport))
+}
+
+// transportFactory 创建 HTTP 传输层, 连接通过 dialer 建立
+type transportFactory func(cfg *config.Config, tlsConfig *tls.Config, dialer *targetDialer) http.RoundTripper
+
+// httpExecutor HTTP 请求执行器
+type httpExecutor struct {
+	client    *http.Client
+	requests  *requestBuilder
+	validator *validator.Validator
+
+	// 流式响应统计, 未启用时为 nil
+	stream *streamStats
+
+	dialer *targetDialer
+
+	// 后端主机池, 未配置时为 nil
+	hosts *hostPool
+
+	// 各阶段超时
+	timeouts config.TimeoutConfig
+
+	// 重试策略, 未配置时为 nil
+	retry *retryPolicy
+}
+
+// httpProtocol 使用指定传输层的 HTTP 协议
+func httpProtocol(newTransport transportFactory) ProtocolFactory {
+	return func(cfg *config.Config) (Executor, error) {
+		tlsConfig, err := createTLSConfig(cfg.TLS)
+		if err != nil {
+			return nil, err
+		}
+
+		// GraphQL 响应中的 errors 总是视为失败
+		validation := cfg.Validation
+		if cfg.Request.GraphQL.Enabled() {
+			validation.GraphQLErrors = true
+		}
+
+		e := &httpExecutor{
+			validator: validator.New(validation),
+			stream:    newStreamStats(cfg),
+			timeouts:  cfg.Target.Timeouts,
+		}
+		e.requests, err = newRequestBuilder(cfg, template.New(cfg.Request.Template))
+		if err != nil {
+			return nil, err
+		}
+
+		e.hosts, err = newHostPool(cfg)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig = e.hosts.tlsConfig(tlsConfig)
+		e.dialer, err = newTargetDialer(cfg)
+		if err != nil {
+			return nil, err
+		}
+		e.client = &http.Client{
+			Transport: newTransport(cfg, tlsConfig, e.dialer),
+			Timeout:   cfg.Target.Timeout,
+		}
+		e.dialer.pool.closeIdle = e.client.CloseIdleConnections
+		// 流式模式下 target.timeout 是空闲超时, 不限制整个流; 流式请求不重试
+		if e.stream != nil {
+			e.client.Timeout = 0
+		} else {
+			e.retry = newRetryPolicy(cfg.Request.Retry)
+		}
+		return e, nil
+	}
+}
+
+// newStdTransport 标准库传输层 (HTTP/1.1 和 HTTP/2)
+func newStdTransport(cfg *config.Config, tlsConfig *tls.Config, dialer *targetDialer) http.RoundTripper {
+	transport := &http.Transport{
+		DialContext:         dialer.DialContext,
+		TLSClientConfig:     tlsConfig,
+		MaxIdleConns:        cfg.Load.Concurrency * 2,
+		MaxIdleConnsPerHost: maxIdlePerHost(cfg),
+		MaxConnsPerHost:     cfg.Protocol.Pool.MaxConnectionsPerHost,
+		IdleConnTimeout:     cfg.Protocol.IdleTimeout,
+		DisableKeepAlives:   !cfg.Protocol.KeepAlive,
+	}
+
+	// HTTP/2
+	if cfg.Protocol.HTTP2Enabled {
+		http2.ConfigureTransport(transport)
+	}
+	return transport
+}
+
+// newHTTP3Transport HTTP/3 (QUIC) 传输层
+//
+// 默认所有连接共享一个UDP套接字且不经过 dialer; 配置了地址覆盖时每个连接单独拨号。
+func newHTTP3Transport(cfg *config.Config, tlsConfig *tls.Config, dialer *targetDialer) http.RoundTripper {
+	transport := &http3.RoundTripper{
+		TLSClientConfig: tlsConfig,
+	}
+	if dialer.rewritesAddress() {
+		transport.Dial = dialer.dialQUIC
+	}
+	return transport
+}
+
+// newRawTransport 精简 HTTP/1.1 传输层
+func newRawTransport(cfg *config.Config, tlsConfig *tls.Config, dialer *targetDialer) http.RoundTripper {
+	return &rawhttp.Transport{
+		DialContext:         dialer.DialContext,
+		TLSClientConfig:     tlsConfig,
+		MaxIdleConnsPerHost: maxIdlePerHost(cfg),
+		MaxConns:            cfg.Protocol.Pool.MaxConnections,
+		MaxConnsPerHost:     cfg.Protocol.Pool.MaxConnectionsPerHost,
+		IdleConnTimeout:     cfg.Protocol.IdleTimeout,
+		DisableKeepAlives:   !cfg.Protocol.KeepAlive,
+	}
+}
+
+// maxIdlePerHost 每个主机保留的空闲连接数, 未配置时等于并发数
+func maxIdlePerHost(cfg *config.Config) int {
+	if n := cfg.Protocol.Pool.MaxIdlePerHost; n > 0 {
+		return n
+	}
+	return cfg.Load.Concurrency
+}
+
+// Prepare 无需预热, 只记录流式统计的开始时间
+func (e *httpExecutor) Prepare(ctx context.Context) error {
+	if e.stream != nil {
+		e.stream.start = time.Now()
+	}
+	return nil
+}
+
+// Execute 发送请求, 读取并验证响应
+func (e *httpExecutor) Execute(ctx context.Context, workerID int) (Result, error) {
+	if e.stream != nil {
+		return e.executeStream(ctx, workerID)
+	}
+	start := time.Now()
+
+	// 创建请求
+	req, endpoint, err := e.requests.build(ctx, workerID)
+	if err != nil {
+		return Result{Start: start}, err
+	}
+	host := e.hosts.acquire()
+	defer host.release()
+	endpoint = host.route(req, endpoint)
+
+	var result Result
+	if e.retry == nil {
+		result = e.attempt(req, start)
+	} else {
+		result = e.retry.do(ctx, func(n int) Result {
+			if n == 0 {
+				return e.attempt(req, time.Now())
+			}
+			retryReq, err := retryRequest(req)
+			if err != nil {
+				r := Result{Start: time.Now()}
+				r.Fail("request", err)
+				return r
+			}
+			return e.attempt(retryReq, time.Now())
+		})
+	}
+	result.Endpoint = endpoint
+	return result, nil
+}
+
+// attempt 发送一次请求并读取、验证响应, 阶段超时按每次尝试计算
+func (e *httpExecutor) attempt(req *http.Request, start time.Time) Result {
+	result := Result{Start: start}
+	ctx, timer, done := withPhaseTimeouts(req.Context(), e.timeouts)
+	defer done()
+	if timer != nil {
+		req = req.WithContext(ctx)
+	}
+	if e.dialer.pool.recycles() {
+		req = e.dialer.pool.withRecycling(req)
+	}
+
+	// 发送请求
+	resp, err := e.client.Do(req)
+	result.Latency = time.Since(result.Start)
+	if err != nil {
+		result.Fail(requestErrorType(ctx, err, "network"), err)
+		return result
+	}
+	defer resp.Body.Close()
+	result.StatusCode = resp.StatusCode
+
+	// 读取响应体, 无需校验响应体时直接丢弃只计字节数
+	var body []byte
+	var n int64
+	respBody := timer.body(resp.Body)
+	if e.validator.NeedsBody() {
+		buf := getBuffer()
+		defer putBuffer(buf)
+		n, err = buf.ReadFrom(respBody)
+		body = buf.Bytes()
+	} else {
+		n, err = io.Copy(io.Discard, respBody)
+	}
+	if err != nil {
+		result.Fail(requestErrorType(ctx, err, "body_read"), err)
+		return result
+	}
+	result.BytesReceived = n
+	result.BytesSent = req.ContentLength
+
+	// 验证响应
+	if validationErr := e.validator.Validate(resp, body); validationErr != nil {
+		result.Fail(validationErrorType(validationErr), validationErr)
+	} else {
+		result.Success = true
+	}
+	return result
+}
+
+// retryRequest 重试使用的请求副本, 请求体通过 GetBody 重新创建
+func retryRequest(req *http.Request) (*http.Request, error) {
+	if req.GetBody == nil {
+		return req, nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	r := *req
+	r.Body = body
+	return &r, nil
+}
+
+// Stats 连接和流式响应统计, HTTP/3 默认不经过 dialer 因此没有连接数
+func (e *httpExecutor) Stats() map[string]float64 {
+	stats := make(map[string]float64)
+	if dials := e.dialer.dials.Load(); dials > 0 {
+		stats["connections_opened"] = float64(dials)
+	}
+	e.dialer.addStats(stats)
+	if e.stream != nil {
+		e.stream.addStats(stats)
+	}
+	e.retry.addStats(stats)
+	return stats
+}
+
+// validationErrorType 验证失败的分类, GraphQL 错误单独统计
+func validationErrorType(err error) string {
+	var ve *validator.ValidationError
+	if errors.As(err, &ve) && ve.Type == "graphql" {
+		return "graphql"
+	}
+	return "validation"
+}
+
+// Close 关闭空闲连接
+func (e *httpExecutor) Close() error {
+	e.dialer.Close()
+	if closer, ok := e.client.Transport.(io.Closer); ok {
+		return closer.Close()
+	}
+	e.client.CloseIdleConnections()
+	return nil
+}
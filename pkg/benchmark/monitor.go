@@ -3,60 +3,90 @@ package benchmark
 import (
 	"context"
 	"fmt"
+	"math"
+	"sync"
 	"time"
+
+	"httpbench/pkg/stats"
 )
 
-// realtimeMonitor 实时监控
-func (b *Benchmark) realtimeMonitor(ctx context.Context) {
-	ticker := time.NewTicker(b.config.Output.MonitorInterval)
-	defer ticker.Stop()
+// printMonitorHeader 打印实时监控表头
+func (b *Benchmark) printMonitorHeader() {
+	fmt.Println("\n⏱️  实时监控已启动")
+	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	fmt.Printf("%-8s %-10s %-12s %-12s %-12s %-12s %-8s %-12s\n",
+		"时间", "RPS", "平均延迟", "P50延迟", "P99延迟", "最大延迟", "错误率", "接收速率")
+	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+}
 
-	lastSnapshot := b.stats.Snapshot()
-	lastTime := time.Now()
+// realtimeMonitor 打印单个采样间隔的实时指标
+func (b *Benchmark) realtimeMonitor(point stats.TimePoint) {
+	elapsed := point.Timestamp.Sub(b.startTime)
+	fmt.Printf("%-8s %-10.2f %-12v %-12v %-12v %-12v %-7.2f%% %-12s\n",
+		formatDuration(elapsed),
+		point.RPS,
+		point.AvgLatency,
+		point.P50Latency,
+		point.P99Latency,
+		point.MaxLatency,
+		point.ErrorRate*100,
+		formatRate(point.Throughput),
+	)
+}
 
-	fmt.Println("\n⏱️  实时监控已启动")
-	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
-	fmt.Printf("%-10s %-10s %-15s %-15s %-10s\n", "时间", "RPS", "平均延迟", "P99延迟", "错误率")
-	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+// generateResults 生成测试结果
+func (b *Benchmark) generateResults() *Results {
+	results := ResultsFromCollector(b.stats, time.Since(b.startTime))
+	results.Thresholds = b.evaluateThresholds(results)
+	results.Canceled = b.canceled.Load()
+	results.Partial = b.interrupted.Load() || results.Canceled > 0
+	if mon := b.selfMon.Load(); mon != nil {
+		results.Client = mon.stats()
+	}
+	results.Protocol = b.protocol
+	results.ProtocolStats = finiteStats(b.executor.Stats())
+	return results
+}
 
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case <-ticker.C:
-			currentSnapshot := b.stats.Snapshot()
-			currentTime := time.Now()
-
-			// 计算间隔内的指标
-			duration := currentTime.Sub(lastTime).Seconds()
-			requestsDelta := currentSnapshot.TotalRequests - lastSnapshot.TotalRequests
-			errorsDelta := currentSnapshot.TotalErrors - lastSnapshot.TotalErrors
-
-			rps := float64(requestsDelta) / duration
-			errorRate := 0.0
-			if requestsDelta > 0 {
-				errorRate = float64(errorsDelta) / float64(requestsDelta) * 100
-			}
+// finiteStats 复制协议统计并去掉 NaN 和 ±Inf, JSON 无法编码非有限值
+func finiteStats(protocolStats map[string]float64) map[string]float64 {
+	if protocolStats == nil {
+		return nil
+	}
+	finite := make(map[string]float64, len(protocolStats))
+	for name, value := range protocolStats {
+		if !math.IsNaN(value) && !math.IsInf(value, 0) {
+			finite[name] = value
+		}
+	}
+	return finite
+}
 
-			elapsed := currentTime.Sub(b.startTime)
-			fmt.Printf("%-10s %-10.2f %-15v %-15v %-10.2f%%\n",
-				formatDuration(elapsed),
-				rps,
-				currentSnapshot.AvgLatency,
-				currentSnapshot.P99Latency,
-				errorRate,
-			)
-
-			lastSnapshot = currentSnapshot
-			lastTime = currentTime
+// evaluateThresholds 检查配置的阈值
+func (b *Benchmark) evaluateThresholds(results *Results) []ThresholdResult {
+	var thresholds []ThresholdResult
+
+	// 响应时间阈值
+	if limit := b.config.Validation.ResponseTimeMax; limit > 0 && results.TotalRequests > 0 {
+		threshold := ThresholdResult{
+			Name:   "response_time_max",
+			Limit:  limit.String(),
+			Actual: results.Latency.Max.String(),
+			Passed: results.Latency.Max <= limit,
 		}
+		if !threshold.Passed {
+			threshold.Message = fmt.Sprintf("最大延迟 %v 超过阈值 %v (P99: %v)",
+				results.Latency.Max, limit, results.Latency.P99)
+		}
+		thresholds = append(thresholds, threshold)
 	}
+
+	return thresholds
 }
 
-// generateResults 生成测试结果
-func (b *Benchmark) generateResults() *Results {
-	snapshot := b.stats.Snapshot()
-	duration := time.Since(b.startTime)
+// ResultsFromCollector 根据统计收集器生成测试结果
+func ResultsFromCollector(collector *stats.Collector, duration time.Duration) *Results {
+	snapshot := collector.Snapshot()
 
 	results := &Results{
 		TotalRequests:   snapshot.TotalRequests,
@@ -67,6 +97,7 @@ func (b *Benchmark) generateResults() *Results {
 		BytesSent:       snapshot.BytesSent,
 		ErrorsByType:    snapshot.ErrorsByType,
 		StatusCodes:     snapshot.StatusCodes,
+		Endpoints:       snapshot.Endpoints,
 	}
 
 	// 计算吞吐量
@@ -78,7 +109,7 @@ func (b *Benchmark) generateResults() *Results {
 	results.Latency = snapshot.Latency
 
 	// 时间序列数据
-	results.TimeSeries = b.stats.GetTimeSeries()
+	results.TimeSeries = collector.GetTimeSeries()
 
 	return results
 }
@@ -93,36 +124,114 @@ func formatDuration(d time.Duration) string {
 	return fmt.Sprintf("%dm%ds", minutes, seconds)
 }
 
+// formatRate 格式化字节速率
+func formatRate(bytesPerSecond float64) string {
+	const unit = 1024
+	if bytesPerSecond < unit {
+		return fmt.Sprintf("%.0fB/s", bytesPerSecond)
+	}
+	div, exp := float64(unit), 0
+	for n := bytesPerSecond / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.2f%cB/s", bytesPerSecond/div, "KMGTPE"[exp])
+}
+
 // RateLimiter 速率限制器
+//
+// 按固定间隔排定每个请求的计划发送时间, 调用方暂时跟不上时会尽快补发落后的请求,
+// 计划时间与实际发送时间的差值即为调度延迟。速率可在运行期间调整, rps <= 0 表示不限速。
 type RateLimiter struct {
+	mu       sync.Mutex
 	rps      int
 	interval time.Duration
-	ticker   *time.Ticker
+
+	// 下一个请求的计划发送时间, 零值表示从下一次调用重新开始计划
+	next time.Time
+
+	// 速率变化或停止时关闭,唤醒等待中的调用方
+	changed chan struct{}
 }
 
 // NewRateLimiter 创建速率限制器
 func NewRateLimiter(rps int) *RateLimiter {
-	interval := time.Second / time.Duration(rps)
-	return &RateLimiter{
-		rps:      rps,
-		interval: interval,
-		ticker:   time.NewTicker(interval),
+	r := &RateLimiter{changed: make(chan struct{})}
+	r.SetRate(rps)
+	return r
+}
+
+// SetRate 调整速率
+func (r *RateLimiter) SetRate(rps int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.rps = rps
+	r.interval = 0
+	if rps > 0 {
+		r.interval = time.Second / time.Duration(rps)
 	}
+	r.next = time.Time{}
+
+	close(r.changed)
+	r.changed = make(chan struct{})
+}
+
+// Rate 获取当前速率
+func (r *RateLimiter) Rate() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rps
 }
 
-// Wait 等待速率限制
-func (r *RateLimiter) Wait(ctx context.Context) {
-	select {
-	case <-ctx.Done():
-		return
-	case <-r.ticker.C:
-		return
+// Reset 重新开始计划, 用于暂停恢复后
+func (r *RateLimiter) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.next = time.Time{}
+}
+
+// Wait 等待速率限制, 返回本次请求的计划发送时间; 不限速或已取消时返回零值
+func (r *RateLimiter) Wait(ctx context.Context) time.Time {
+	for {
+		r.mu.Lock()
+		if r.interval <= 0 {
+			r.mu.Unlock()
+			return time.Time{}
+		}
+		if r.next.IsZero() {
+			r.next = time.Now().Add(r.interval)
+		}
+		scheduled, changed := r.next, r.changed
+		r.mu.Unlock()
+
+		delay := time.Until(scheduled)
+		if delay > 0 {
+			timer := time.NewTimer(delay)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return time.Time{}
+			case <-changed:
+				// 速率已调整,按新速率重新等待
+				timer.Stop()
+				continue
+			case <-timer.C:
+			}
+		}
+
+		// 计划未被调整时占用该时间点
+		r.mu.Lock()
+		if r.next.Equal(scheduled) {
+			r.next = scheduled.Add(r.interval)
+			r.mu.Unlock()
+			return scheduled
+		}
+		r.mu.Unlock()
 	}
 }
 
-// Stop 停止速率限制器
+// Stop 停止速率限制器, 唤醒等待中的调用方
 func (r *RateLimiter) Stop() {
-	if r.ticker != nil {
-		r.ticker.Stop()
-	}
+	r.SetRate(0)
 }
@@ -0,0 +1,83 @@
+package benchmark
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// workerPool 可在运行期间调整大小的工作协程池
+//
+// 缩容时被移除的工作协程会先完成手头的请求再退出,不会中断进行中的请求。
+type workerPool struct {
+	b        *Benchmark
+	ctx      context.Context
+	genCtx   context.Context
+	requests <-chan time.Time
+
+	mu     sync.Mutex
+	quits  []chan struct{}
+	nextID int
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// newWorkerPool 创建工作协程池, ctx 用于执行请求, genCtx 结束后丢弃排队的请求
+func newWorkerPool(b *Benchmark, ctx, genCtx context.Context, requests <-chan time.Time) *workerPool {
+	return &workerPool{
+		b:        b,
+		ctx:      ctx,
+		genCtx:   genCtx,
+		requests: requests,
+	}
+}
+
+// resize 调整工作协程数量,返回调整前的数量
+func (p *workerPool) resize(n int) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	old := len(p.quits)
+	if p.closed {
+		return old
+	}
+
+	// 扩容: 工作协程ID单调递增, 缩容后被移除的协程可能仍在执行请求, 不复用其ID
+	for len(p.quits) < n {
+		quit := make(chan struct{})
+		workerID := p.nextID
+		p.nextID++
+		p.quits = append(p.quits, quit)
+
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			p.b.worker(p.ctx, p.genCtx, quit, workerID, p.requests)
+		}()
+	}
+
+	// 缩容: 优先移除最新加入的工作协程
+	for len(p.quits) > n {
+		last := len(p.quits) - 1
+		close(p.quits[last])
+		p.quits = p.quits[:last]
+	}
+
+	return old
+}
+
+// size 获取当前工作协程数量
+func (p *workerPool) size() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.quits)
+}
+
+// wait 停止调整并等待所有工作协程退出,须在请求通道关闭后调用
+func (p *workerPool) wait() {
+	p.mu.Lock()
+	p.closed = true
+	p.mu.Unlock()
+
+	p.wg.Wait()
+}
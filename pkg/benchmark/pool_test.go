@@ -0,0 +1,115 @@
+package benchmark
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"httpbench/pkg/config"
+)
+
+// blockingExecutor 测试用执行器, 请求阻塞到 release 关闭, 记录同时执行请求的重复工作协程ID
+type blockingExecutor struct {
+	mu      sync.Mutex
+	active  map[int]bool
+	dup     []int
+	entered chan int
+	release chan struct{}
+}
+
+func (e *blockingExecutor) Prepare(ctx context.Context) error { return nil }
+
+func (e *blockingExecutor) Execute(ctx context.Context, workerID int) (Result, error) {
+	start := time.Now()
+	e.mu.Lock()
+	if e.active[workerID] {
+		e.dup = append(e.dup, workerID)
+	}
+	e.active[workerID] = true
+	e.mu.Unlock()
+
+	select {
+	case e.entered <- workerID:
+	default:
+	}
+	select {
+	case <-e.release:
+	case <-ctx.Done():
+	}
+
+	e.mu.Lock()
+	delete(e.active, workerID)
+	e.mu.Unlock()
+	return Result{Start: start, Latency: time.Since(start), StatusCode: 200, Success: true}, nil
+}
+
+func (e *blockingExecutor) Stats() map[string]float64 { return nil }
+
+func (e *blockingExecutor) Close() error { return nil }
+
+// TestWorkerIDsNotReused 测试缩容后仍在执行请求的工作协程ID不会分配给新的工作协程
+func TestWorkerIDsNotReused(t *testing.T) {
+	exec := &blockingExecutor{
+		active:  make(map[int]bool),
+		entered: make(chan int, 16),
+		release: make(chan struct{}),
+	}
+	RegisterProtocol("blocking", func(cfg *config.Config) (Executor, error) {
+		return exec, nil
+	})
+
+	cfg := &config.Config{
+		Target: config.TargetConfig{URL: "blocking://target"},
+		Load: config.LoadConfig{
+			Concurrency: 2,
+			Duration:    30 * time.Second,
+		},
+		Protocol: config.ProtocolConfig{Type: "blocking"},
+	}
+	bench := newTestBenchmark(t, cfg)
+	defer bench.Close()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if _, err := bench.Run(context.Background()); err != nil {
+			t.Errorf("运行失败: %v", err)
+		}
+	}()
+
+	waitEntered := func(n int) {
+		t.Helper()
+		for i := 0; i < n; i++ {
+			select {
+			case <-exec.entered:
+			case <-time.After(5 * time.Second):
+				t.Fatal("等待请求开始超时")
+			}
+		}
+	}
+	waitEntered(2)
+
+	// 被移除的工作协程仍阻塞在请求中, 扩容后的新工作协程必须使用新的ID
+	if err := bench.SetConcurrency(1); err != nil {
+		t.Fatalf("缩容失败: %v", err)
+	}
+	if err := bench.SetConcurrency(2); err != nil {
+		t.Fatalf("扩容失败: %v", err)
+	}
+	waitEntered(1)
+
+	bench.Stop()
+	close(exec.release)
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("停止后测试未结束")
+	}
+
+	exec.mu.Lock()
+	defer exec.mu.Unlock()
+	if len(exec.dup) > 0 {
+		t.Errorf("工作协程ID被重复使用: %v", exec.dup)
+	}
+}
@@ -0,0 +1,253 @@
+package benchmark
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/HdrHistogram/hdrhistogram-go"
+	"golang.org/x/net/http/httpproxy"
+	"golang.org/x/net/proxy"
+
+	"httpbench/pkg/config"
+)
+
+// proxyError 经代理建立连接失败, 单独计入 proxy 错误
+type proxyError struct {
+	err error
+}
+
+func (e *proxyError) Error() string {
+	return "代理: " + e.err.Error()
+}
+
+func (e *proxyError) Unwrap() error {
+	return e.err
+}
+
+// networkErrorType 连接错误的分类, 代理失败单独统计
+func networkErrorType(err error, fallback string) string {
+	var pe *proxyError
+	if errors.As(err, &pe) {
+		return "proxy"
+	}
+	return fallback
+}
+
+// proxyDialer 经代理建立到目标的隧道, 并记录建立隧道的耗时
+type proxyDialer struct {
+	addr string
+
+	// HTTP/HTTPS 代理
+	tlsConfig *tls.Config
+	auth      string
+
+	// SOCKS5 代理
+	socks proxy.ContextDialer
+
+	connects atomic.Int64
+	errors   atomic.Int64
+
+	mu      sync.Mutex
+	connect *hdrhistogram.Histogram
+}
+
+// proxyURL 配置的代理地址; 从环境变量获取时按目标协议选择, 不使用代理时返回 nil
+func proxyURL(cfg *config.Config) (*url.URL, error) {
+	proxyCfg := cfg.Protocol.Proxy
+	if proxyCfg.URL != "" {
+		u, err := url.Parse(proxyCfg.URL)
+		if err != nil {
+			return nil, fmt.Errorf("无效的代理地址: %w", err)
+		}
+		return u, nil
+	}
+	if !proxyCfg.FromEnvironment {
+		return nil, nil
+	}
+
+	target, err := url.Parse(cfg.Target.URL)
+	if err != nil {
+		return nil, err
+	}
+	// WebSocket 和 gRPC 按是否加密对应到 HTTP/HTTPS 的代理变量
+	switch target.Scheme {
+	case "https", "wss", "grpcs":
+		target.Scheme = "https"
+	default:
+		target.Scheme = "http"
+	}
+	return httpproxy.FromEnvironment().ProxyFunc()(target)
+}
+
+// newProxyDialer 创建代理拨号器, forward 用于连接代理本身
+func newProxyDialer(u *url.URL, tlsConfig *tls.Config, forward *targetDialer) (*proxyDialer, error) {
+	p := &proxyDialer{connect: newDurationHistogram()}
+
+	port := u.Port()
+	switch u.Scheme {
+	case "http":
+		if port == "" {
+			port = "80"
+		}
+	case "https":
+		if port == "" {
+			port = "443"
+		}
+		p.tlsConfig = &tls.Config{}
+		if tlsConfig != nil {
+			p.tlsConfig = tlsConfig.Clone()
+		}
+		p.tlsConfig.ServerName = u.Hostname()
+		p.tlsConfig.NextProtos = nil
+	case "socks5", "socks5h":
+		if port == "" {
+			port = "1080"
+		}
+	default:
+		return nil, fmt.Errorf("不支持的代理协议: %s", u.Scheme)
+	}
+	p.addr = net.JoinHostPort(u.Hostname(), port)
+
+	if strings.HasPrefix(u.Scheme, "socks5") {
+		var auth *proxy.Auth
+		if u.User != nil {
+			password, _ := u.User.Password()
+			auth = &proxy.Auth{User: u.User.Username(), Password: password}
+		}
+		d, err := proxy.SOCKS5("tcp", p.addr, auth, forwardDialer{forward})
+		if err != nil {
+			return nil, err
+		}
+		p.socks = d.(proxy.ContextDialer)
+	} else if u.User != nil {
+		password, _ := u.User.Password()
+		p.auth = "Basic " + base64.StdEncoding.EncodeToString([]byte(u.User.Username()+":"+password))
+	}
+	return p, nil
+}
+
+// dial 建立到 addr 的隧道
+func (p *proxyDialer) dial(ctx context.Context, forward *targetDialer, addr string) (net.Conn, error) {
+	start := time.Now()
+
+	var conn net.Conn
+	var err error
+	if p.socks != nil {
+		conn, err = p.socks.DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = p.connectTunnel(ctx, forward, addr)
+	}
+	if err != nil {
+		p.errors.Add(1)
+		return nil, &proxyError{err}
+	}
+
+	p.connects.Add(1)
+	p.mu.Lock()
+	recordDuration(p.connect, time.Since(start))
+	p.mu.Unlock()
+	return conn, nil
+}
+
+// connectTunnel 通过 HTTP CONNECT 建立隧道
+func (p *proxyDialer) connectTunnel(ctx context.Context, forward *targetDialer, addr string) (net.Conn, error) {
+	conn, err := forward.dialDirect(ctx, "tcp", p.addr)
+	if err != nil {
+		return nil, err
+	}
+
+	// 上下文取消时中断握手
+	stop := context.AfterFunc(ctx, func() {
+		conn.SetDeadline(time.Unix(1, 0))
+	})
+	defer stop()
+
+	if p.tlsConfig != nil {
+		tc := tls.Client(conn, p.tlsConfig)
+		if err := tc.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		conn = tc
+	}
+
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: make(http.Header),
+	}
+	if p.auth != "" {
+		req.Header.Set("Proxy-Authorization", p.auth)
+	}
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, fmt.Errorf("CONNECT %s: %s", addr, resp.Status)
+	}
+
+	if !stop() {
+		conn.Close()
+		return nil, ctx.Err()
+	}
+	// 代理在响应后提前发送的数据需要保留
+	if br.Buffered() > 0 {
+		return &bufferedConn{Conn: conn, r: br}, nil
+	}
+	return conn, nil
+}
+
+// addStats 写入代理统计
+func (p *proxyDialer) addStats(stats map[string]float64) {
+	stats["proxy_connects"] = float64(p.connects.Load())
+	stats["proxy_errors"] = float64(p.errors.Load())
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	addDurationStats(stats, "proxy_connect", p.connect)
+}
+
+// forwardDialer 连接代理本身, 与直连一样绑定源地址
+type forwardDialer struct {
+	d *targetDialer
+}
+
+func (f forwardDialer) Dial(network, addr string) (net.Conn, error) {
+	return f.d.dialDirect(context.Background(), network, addr)
+}
+
+func (f forwardDialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	return f.d.dialDirect(ctx, network, addr)
+}
+
+// bufferedConn 先读取缓冲中剩余数据的连接
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *bufferedConn) Read(p []byte) (int, error) {
+	return c.r.Read(p)
+}
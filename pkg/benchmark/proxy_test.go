@@ -0,0 +1,230 @@
+package benchmark
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"httpbench/pkg/config"
+)
+
+// startConnectProxy 启动需要 Basic 认证的 HTTP CONNECT 代理
+func startConnectProxy(t *testing.T, user, password string) (*httptest.Server, *atomic.Int64) {
+	t.Helper()
+	var tunnels atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodConnect {
+			http.Error(w, "只支持 CONNECT", http.StatusMethodNotAllowed)
+			return
+		}
+		if u, p, ok := parseProxyAuth(r.Header.Get("Proxy-Authorization")); !ok || u != user || p != password {
+			w.WriteHeader(http.StatusProxyAuthRequired)
+			return
+		}
+		upstream, err := net.Dial("tcp", r.Host)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		conn, buf, err := http.NewResponseController(w).Hijack()
+		if err != nil {
+			upstream.Close()
+			return
+		}
+		io.WriteString(conn, "HTTP/1.1 200 Connection established\r\n\r\n")
+		tunnels.Add(1)
+		go pipeConns(conn, buf.Reader, upstream)
+	}))
+	return server, &tunnels
+}
+
+// parseProxyAuth 解析 Proxy-Authorization 中的 Basic 认证
+func parseProxyAuth(header string) (string, string, bool) {
+	r := &http.Request{Header: http.Header{"Authorization": {header}}}
+	return r.BasicAuth()
+}
+
+// pipeConns 双向转发直到任意一端关闭
+func pipeConns(conn net.Conn, r io.Reader, upstream net.Conn) {
+	defer conn.Close()
+	defer upstream.Close()
+	go io.Copy(conn, upstream)
+	io.Copy(upstream, r)
+}
+
+// startSOCKS5Proxy 启动需要用户名密码认证的最小 SOCKS5 代理
+func startSOCKS5Proxy(t *testing.T, user, password string) (net.Listener, *atomic.Int64) {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("监听失败: %v", err)
+	}
+	var tunnels atomic.Int64
+	handle := func(conn net.Conn) {
+		r := bufio.NewReader(conn)
+		readN := func(n int) []byte {
+			buf := make([]byte, n)
+			if _, err := io.ReadFull(r, buf); err != nil {
+				return nil
+			}
+			return buf
+		}
+
+		// 协商认证方式, 只接受用户名密码
+		head := readN(2)
+		if head == nil || readN(int(head[1])) == nil {
+			conn.Close()
+			return
+		}
+		conn.Write([]byte{5, 2})
+		ver := readN(2)
+		if ver == nil {
+			conn.Close()
+			return
+		}
+		u := readN(int(ver[1]))
+		plen := readN(1)
+		if u == nil || plen == nil {
+			conn.Close()
+			return
+		}
+		p := readN(int(plen[0]))
+		if string(u) != user || string(p) != password {
+			conn.Write([]byte{1, 1})
+			conn.Close()
+			return
+		}
+		conn.Write([]byte{1, 0})
+
+		// CONNECT 请求, 域名由代理解析
+		req := readN(4)
+		if req == nil {
+			conn.Close()
+			return
+		}
+		var host string
+		switch req[3] {
+		case 1:
+			host = net.IP(readN(4)).String()
+		case 3:
+			host = string(readN(int(readN(1)[0])))
+		case 4:
+			host = net.IP(readN(16)).String()
+		}
+		port := readN(2)
+		upstream, err := net.Dial("tcp", net.JoinHostPort(host, strconv.Itoa(int(port[0])<<8|int(port[1]))))
+		if err != nil {
+			conn.Write([]byte{5, 5, 0, 1, 0, 0, 0, 0, 0, 0})
+			conn.Close()
+			return
+		}
+		conn.Write([]byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0})
+		tunnels.Add(1)
+		pipeConns(conn, r, upstream)
+	}
+	go func() {
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+			go handle(conn)
+		}
+	}()
+	return lis, &tunnels
+}
+
+// TestProxy 测试经 HTTP CONNECT 和 SOCKS5 代理访问目标、代理认证失败和从环境变量读取代理
+func TestProxy(t *testing.T) {
+	var hosts sync.Map
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hosts.Store(r.Host, true)
+		io.WriteString(w, "ok")
+	})
+	plain := httptest.NewServer(handler)
+	defer plain.Close()
+	secure := httptest.NewTLSServer(handler)
+	defer secure.Close()
+
+	connectProxy, connectTunnels := startConnectProxy(t, "bench", "s3cret")
+	defer connectProxy.Close()
+	socksProxy, socksTunnels := startSOCKS5Proxy(t, "bench", "s3cret")
+	defer socksProxy.Close()
+
+	run := func(cfg *config.Config) *Results {
+		t.Helper()
+		cfg.Load = config.LoadConfig{Concurrency: 2, TotalRequests: 10}
+		cfg.Target.Timeout = 2 * time.Second
+		cfg.TLS = config.TLSConfig{Enabled: true, InsecureSkipVerify: true}
+		return runBenchmark(t, cfg)
+	}
+
+	connectURL := "http://bench:s3cret@" + connectProxy.Listener.Addr().String()
+	socksURL := "socks5://bench:s3cret@" + socksProxy.Addr().String()
+	for _, tc := range []struct {
+		name    string
+		target  string
+		proxy   string
+		tunnels *atomic.Int64
+	}{
+		{"CONNECT/http", plain.URL, connectURL, connectTunnels},
+		{"CONNECT/https", secure.URL, connectURL, connectTunnels},
+		{"SOCKS5/http", plain.URL, socksURL, socksTunnels},
+		{"SOCKS5/https", secure.URL, socksURL, socksTunnels},
+	} {
+		before := tc.tunnels.Load()
+		results := run(&config.Config{
+			Target:   config.TargetConfig{URL: tc.target},
+			Protocol: config.ProtocolConfig{KeepAlive: true, Proxy: config.ProxyConfig{URL: tc.proxy}},
+		})
+		if results.SuccessRequests != 10 {
+			t.Errorf("%s: 成功 %d, 错误 %v", tc.name, results.SuccessRequests, results.ErrorsByType)
+		}
+		connects := results.ProtocolStats["proxy_connects"]
+		if connects < 1 || float64(tc.tunnels.Load()-before) != connects {
+			t.Errorf("%s: 代理连接 %v, 代理端隧道 %d", tc.name, connects, tc.tunnels.Load()-before)
+		}
+		if results.ProtocolStats["proxy_connect_ms_mean"] <= 0 {
+			t.Errorf("%s: 缺少代理连接耗时 %v", tc.name, results.ProtocolStats)
+		}
+	}
+
+	// 认证失败计为 proxy 错误
+	for _, proxyURL := range []string{
+		"http://bench:wrong@" + connectProxy.Listener.Addr().String(),
+		"socks5://bench:wrong@" + socksProxy.Addr().String(),
+	} {
+		results := run(&config.Config{
+			Target:   config.TargetConfig{URL: plain.URL},
+			Protocol: config.ProtocolConfig{KeepAlive: true, Proxy: config.ProxyConfig{URL: proxyURL}},
+		})
+		if results.ErrorsByType["proxy"] != 10 || results.ProtocolStats["proxy_errors"] < 10 {
+			t.Errorf("%s: 错误 %v, 统计 %v", proxyURL, results.ErrorsByType, results.ProtocolStats)
+		}
+	}
+
+	// 从环境变量读取代理, 连接地址先经 connect_to 改写, Host 保持为域名
+	t.Setenv("HTTP_PROXY", connectURL)
+	t.Setenv("NO_PROXY", "")
+	_, port, _ := net.SplitHostPort(plain.Listener.Addr().String())
+	results := run(&config.Config{
+		Target: config.TargetConfig{
+			URL:       "http://api.example.test/",
+			ConnectTo: []string{"api.example.test:80:127.0.0.1:" + port},
+		},
+		Protocol: config.ProtocolConfig{KeepAlive: true, Proxy: config.ProxyConfig{FromEnvironment: true}},
+	})
+	if results.SuccessRequests != 10 || results.ProtocolStats["proxy_connects"] < 1 {
+		t.Errorf("环境变量代理: 成功 %d, 统计 %v", results.SuccessRequests, results.ProtocolStats)
+	}
+	if _, ok := hosts.Load("api.example.test"); !ok {
+		t.Errorf("Host 应保持为 api.example.test")
+	}
+}
@@ -0,0 +1,67 @@
+package benchmark
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"httpbench/pkg/config"
+	"httpbench/pkg/rawlog"
+)
+
+// TestRawLogRebuild 测试运行时写入的原始结果可重建出相同的统计
+func TestRawLogRebuild(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("fail") != "" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		w.Write([]byte("OK"))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "raw.bin")
+	results := runBenchmark(t, &config.Config{
+		Target: config.TargetConfig{
+			URL:     server.URL + "?fail=1",
+			Method:  "GET",
+			Timeout: 5 * time.Second,
+		},
+		Load: config.LoadConfig{
+			Concurrency:   4,
+			TotalRequests: 200,
+		},
+		Protocol: config.ProtocolConfig{
+			KeepAlive: true,
+		},
+		Validation: config.ValidationConfig{
+			StatusCodes: []int{200},
+		},
+		Output: config.OutputConfig{
+			RawLog: config.RawLogConfig{File: path},
+		},
+	})
+
+	reader, err := rawlog.Open(path)
+	if err != nil {
+		t.Fatalf("打开原始结果失败: %v", err)
+	}
+	defer reader.Close()
+
+	collector, _, err := rawlog.Rebuild(reader, time.Second)
+	if err != nil {
+		t.Fatalf("重建统计失败: %v", err)
+	}
+	rebuilt := ResultsFromCollector(collector, results.Duration)
+
+	if rebuilt.TotalRequests != results.TotalRequests {
+		t.Errorf("请求数不匹配: got %d, want %d", rebuilt.TotalRequests, results.TotalRequests)
+	}
+	if rebuilt.StatusCodes[503] != results.StatusCodes[503] {
+		t.Errorf("状态码统计不匹配: got %v, want %v", rebuilt.StatusCodes, results.StatusCodes)
+	}
+	if rebuilt.ErrorsByType["validation"] != results.ErrorsByType["validation"] {
+		t.Errorf("错误统计不匹配: got %v, want %v", rebuilt.ErrorsByType, results.ErrorsByType)
+	}
+}
@@ -0,0 +1,228 @@
+package benchmark
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"httpbench/pkg/config"
+	"httpbench/pkg/template"
+)
+
+// requestVars 每个请求传入模板的变量
+var requestVars = []string{"worker_id", "timestamp"}
+
+// maxPooledBuffer 超过该容量的缓冲不放回池中, 避免个别大响应长期占用内存
+const maxPooledBuffer = 1 << 20
+
+// bufferPool 模板输出和响应体缓冲
+var bufferPool = sync.Pool{
+	New: func() interface{} { return new(bytes.Buffer) },
+}
+
+// getBuffer 从池中获取已清空的缓冲
+func getBuffer() *bytes.Buffer {
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	return buf
+}
+
+// putBuffer 归还缓冲
+func putBuffer(buf *bytes.Buffer) {
+	if buf.Cap() <= maxPooledBuffer {
+		bufferPool.Put(buf)
+	}
+}
+
+// requestBuilder 请求构建器
+//
+// 在创建时完成URL解析、模板解析以及请求头和Cookie的合并,
+// 没有动态内容时每个请求只需浅拷贝原型请求。
+type requestBuilder struct {
+	// 原型请求, 请求头和URL只读共享
+	proto *http.Request
+
+	// 静态请求体
+	body []byte
+
+	// 动态URL和请求体, 未启用模板或不含动作时为 nil
+	urlTemplate  *template.Template
+	bodyTemplate *template.Template
+
+	// GraphQL 操作, 多个时轮流发送
+	graphql []*graphqlOperation
+	next    atomic.Uint64
+
+	// 模板变量, 预置配置的变量
+	vars sync.Pool
+}
+
+// newRequestBuilder 创建请求构建器
+func newRequestBuilder(cfg *config.Config, engine *template.Engine) (*requestBuilder, error) {
+	rb := &requestBuilder{
+		body: []byte(cfg.Target.Body),
+	}
+	rb.vars.New = func() interface{} { return engine.Vars() }
+
+	if cfg.Request.Template.Enabled {
+		urlTemplate, err := engine.Compile(cfg.Target.URL, requestVars...)
+		if err != nil {
+			return nil, fmt.Errorf("解析URL模板失败: %w", err)
+		}
+		if !urlTemplate.Static() {
+			rb.urlTemplate = urlTemplate
+		}
+
+		if cfg.Request.DynamicBody {
+			bodyTemplate, err := engine.Compile(cfg.Request.BodyTemplate, requestVars...)
+			if err != nil {
+				return nil, fmt.Errorf("解析Body模板失败: %w", err)
+			}
+			if bodyTemplate.Static() {
+				rb.body = []byte(cfg.Request.BodyTemplate)
+			} else {
+				rb.bodyTemplate = bodyTemplate
+			}
+		}
+	}
+
+	// GraphQL 变量总是作为模板渲染, 请求体由操作生成
+	method := cfg.Target.Method
+	if cfg.Request.GraphQL.Enabled() {
+		templateCfg := cfg.Request.Template
+		templateCfg.Enabled = true
+		ops, err := newGraphQLOperations(cfg.Request.GraphQL, template.New(templateCfg))
+		if err != nil {
+			return nil, err
+		}
+		rb.graphql = ops
+		rb.body = nil
+		rb.bodyTemplate = nil
+		if method == "" {
+			method = http.MethodPost
+		}
+	}
+
+	// 动态URL的原型使用占位地址, 每个请求替换
+	protoURL := cfg.Target.URL
+	if rb.urlTemplate != nil {
+		protoURL = "http://localhost/"
+	}
+	proto, err := http.NewRequest(method, protoURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("无效的请求: %w", err)
+	}
+
+	// 设置请求头
+	for key, value := range cfg.Target.Headers {
+		proto.Header.Set(key, value)
+	}
+	for key, value := range cfg.Request.Headers {
+		proto.Header.Set(key, value)
+	}
+
+	// 设置Cookie
+	for _, cookie := range cfg.Request.Cookies {
+		proto.AddCookie(&http.Cookie{
+			Name:     cookie.Name,
+			Value:    cookie.Value,
+			Domain:   cookie.Domain,
+			Path:     cookie.Path,
+			Expires:  cookie.Expires,
+			Secure:   cookie.Secure,
+			HttpOnly: cookie.HttpOnly,
+		})
+	}
+
+	if rb.graphql != nil && proto.Header.Get("Content-Type") == "" {
+		proto.Header.Set("Content-Type", "application/json")
+	}
+
+	rb.proto = proto
+	return rb, nil
+}
+
+// build 创建HTTP请求, GraphQL 请求同时返回操作对应的端点名称
+func (rb *requestBuilder) build(ctx context.Context, workerID int) (*http.Request, string, error) {
+	req := rb.proto.WithContext(ctx)
+	body := rb.body
+
+	var op *graphqlOperation
+	if len(rb.graphql) > 0 {
+		op = rb.graphql[(rb.next.Add(1)-1)%uint64(len(rb.graphql))]
+		body = op.static
+	}
+
+	if rb.urlTemplate != nil || rb.bodyTemplate != nil || (op != nil && op.variables != nil) {
+		vars := rb.vars.Get().(map[string]interface{})
+		defer rb.vars.Put(vars)
+		vars["worker_id"] = workerID
+		vars["timestamp"] = time.Now().Unix()
+
+		buf := getBuffer()
+		defer putBuffer(buf)
+
+		if rb.urlTemplate != nil {
+			if err := rb.urlTemplate.Execute(buf, vars); err != nil {
+				return nil, "", fmt.Errorf("渲染URL模板失败: %w", err)
+			}
+			u, err := url.Parse(buf.String())
+			if err != nil {
+				return nil, "", err
+			}
+			u.Host = strings.TrimSuffix(u.Host, ":")
+			req.URL = u
+			req.Host = u.Host
+			buf.Reset()
+		}
+
+		if rb.bodyTemplate != nil {
+			if err := rb.bodyTemplate.Execute(buf, vars); err != nil {
+				return nil, "", fmt.Errorf("渲染Body模板失败: %w", err)
+			}
+			body = bytes.Clone(buf.Bytes())
+		}
+
+		if op != nil && op.variables != nil {
+			var err error
+			if body, err = op.render(buf, vars); err != nil {
+				return nil, "", err
+			}
+		}
+	}
+
+	if len(body) > 0 {
+		req.ContentLength = int64(len(body))
+		req.Body = newBodyReader(body)
+		req.GetBody = func() (io.ReadCloser, error) {
+			return newBodyReader(body), nil
+		}
+	}
+
+	if op != nil {
+		return req, op.endpoint, nil
+	}
+	return req, "", nil
+}
+
+// bodyReader 请求体, 重定向或重试时通过 GetBody 重新创建
+type bodyReader struct {
+	bytes.Reader
+}
+
+func newBodyReader(body []byte) *bodyReader {
+	r := &bodyReader{}
+	r.Reset(body)
+	return r
+}
+
+func (r *bodyReader) Close() error {
+	return nil
+}
@@ -0,0 +1,123 @@
+package benchmark
+
+import (
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	"httpbench/pkg/rawlog"
+)
+
+// requestResult 执行器返回的结果及记录所需的上下文
+type requestResult struct {
+	Result
+	workerID int
+
+	// 仅在记录原始结果时启用
+	trace *requestTrace
+}
+
+// recordResult 记录请求结果 (汇总、端点维度及原始结果)
+func (b *Benchmark) recordResult(r *requestResult) {
+	// 每个工作协程写入各自的统计分片
+	rec := b.stats.Recorder(r.workerID)
+
+	if r.ErrorType != "" {
+		rec.RecordError(r.ErrorType, r.Err)
+		b.logf("err: %e \n", r.Err)
+	}
+
+	endpoint := b.endpoint
+	if r.Endpoint != "" {
+		endpoint = r.Endpoint
+	}
+
+	rec.RecordRequest(r.Latency, r.BytesReceived, r.BytesSent, r.Success)
+	rec.RecordEndpoint(endpoint, r.StatusCode, r.Latency, r.Success)
+	if r.StatusCode > 0 {
+		rec.RecordStatusCode(r.StatusCode)
+	}
+
+	if b.rawLog != nil {
+		b.rawLog.Write(r.rawRecord(endpoint))
+	}
+}
+
+// rawRecord 转换为原始结果记录
+func (r *requestResult) rawRecord(endpoint string) rawlog.Record {
+	rec := rawlog.Record{
+		Timestamp:     r.Start,
+		Endpoint:      endpoint,
+		WorkerID:      r.workerID,
+		StatusCode:    r.StatusCode,
+		Success:       r.Success,
+		Latency:       r.Latency,
+		BytesReceived: r.BytesReceived,
+		BytesSent:     r.BytesSent,
+		ErrorType:     r.ErrorType,
+	}
+	if r.Err != nil {
+		rec.Error = r.Err.Error()
+	}
+
+	if t := r.trace; t != nil {
+		t.mu.Lock()
+		rec.DNS = span(t.dnsStart, t.dnsDone)
+		rec.Connect = span(t.connectStart, t.connectDone)
+		rec.TLS = span(t.tlsStart, t.tlsDone)
+		rec.FirstByte = span(r.Start, t.firstByte)
+		rec.ConnReused = t.reused
+		t.mu.Unlock()
+	}
+
+	return rec
+}
+
+// span 计算阶段耗时,阶段未完成时为0
+func span(start, end time.Time) time.Duration {
+	if start.IsZero() || end.Before(start) {
+		return 0
+	}
+	return end.Sub(start)
+}
+
+// requestTrace 请求各阶段时间点
+//
+// 拨号可能在独立协程中完成,因此所有字段由互斥锁保护。
+type requestTrace struct {
+	mu sync.Mutex
+
+	dnsStart, dnsDone         time.Time
+	connectStart, connectDone time.Time
+	tlsStart, tlsDone         time.Time
+	firstByte                 time.Time
+	reused                    bool
+}
+
+// clientTrace 创建httptrace钩子
+func (t *requestTrace) clientTrace() *httptrace.ClientTrace {
+	mark := func(field *time.Time) {
+		t.mu.Lock()
+		// 多地址拨号时开始时间取第一次尝试,建连完成时间取最后一次
+		if field.IsZero() || field == &t.connectDone {
+			*field = time.Now()
+		}
+		t.mu.Unlock()
+	}
+
+	return &httptrace.ClientTrace{
+		DNSStart:             func(httptrace.DNSStartInfo) { mark(&t.dnsStart) },
+		DNSDone:              func(httptrace.DNSDoneInfo) { mark(&t.dnsDone) },
+		ConnectStart:         func(network, addr string) { mark(&t.connectStart) },
+		ConnectDone:          func(network, addr string, err error) { mark(&t.connectDone) },
+		TLSHandshakeStart:    func() { mark(&t.tlsStart) },
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { mark(&t.tlsDone) },
+		GotFirstResponseByte: func() { mark(&t.firstByte) },
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.mu.Lock()
+			t.reused = info.Reused
+			t.mu.Unlock()
+		},
+	}
+}
@@ -0,0 +1,200 @@
+package benchmark
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/HdrHistogram/hdrhistogram-go"
+
+	"httpbench/pkg/config"
+)
+
+// minRetryBudget 重试预算的下限, 避免测试刚开始时逻辑请求数太少而无法重试
+const minRetryBudget = 10
+
+// retryPolicy 重试策略及重试统计
+//
+// 逻辑请求是执行器返回的一个结果, 尝试是其中的每一次发送; 两者分开计数以计算重试放大倍数。
+type retryPolicy struct {
+	cfg      config.RetryConfig
+	statuses map[int]bool
+	errors   map[string]bool
+
+	requests      atomic.Int64
+	attempts      atomic.Int64
+	retries       atomic.Int64
+	retried       atomic.Int64
+	recovered     atomic.Int64
+	giveUps       atomic.Int64
+	budgetDenials atomic.Int64
+
+	mu    sync.Mutex
+	first *hdrhistogram.Histogram
+	final *hdrhistogram.Histogram
+}
+
+// newRetryPolicy 创建重试策略, 最多尝试次数不超过 1 时返回 nil
+func newRetryPolicy(cfg config.RetryConfig) *retryPolicy {
+	if cfg.MaxAttempts <= 1 {
+		return nil
+	}
+
+	p := &retryPolicy{
+		cfg:      cfg,
+		statuses: make(map[int]bool),
+		errors:   make(map[string]bool),
+		first:    newDurationHistogram(),
+		final:    newDurationHistogram(),
+	}
+	statuses, errTypes := cfg.StatusCodes, cfg.Errors
+	if len(statuses) == 0 && len(errTypes) == 0 {
+		statuses = []int{502, 503, 504}
+		errTypes = []string{"network"}
+	}
+	for _, code := range statuses {
+		p.statuses[code] = true
+	}
+	for _, t := range errTypes {
+		p.errors[t] = true
+	}
+	return p
+}
+
+// do 执行一个逻辑请求, attempt 发送第 n 次尝试 (从 0 开始) 并返回其结果
+//
+// 返回最后一次尝试的结果, 开始时间和延迟按整个逻辑请求 (含退避等待) 计算。
+func (p *retryPolicy) do(ctx context.Context, attempt func(n int) Result) Result {
+	p.requests.Add(1)
+	start := time.Now()
+
+	var result Result
+	for n := 0; ; n++ {
+		p.attempts.Add(1)
+		result = attempt(n)
+		if n == 0 {
+			p.mu.Lock()
+			recordDuration(p.first, result.Latency)
+			p.mu.Unlock()
+		}
+
+		if !p.retryable(result) {
+			if n > 0 && result.Success {
+				p.recovered.Add(1)
+			}
+			break
+		}
+		if n+1 >= p.cfg.MaxAttempts || ctx.Err() != nil {
+			p.giveUps.Add(1)
+			break
+		}
+		if !p.takeBudget() {
+			p.budgetDenials.Add(1)
+			p.giveUps.Add(1)
+			break
+		}
+		if n == 0 {
+			p.retried.Add(1)
+		}
+		if !sleepContext(ctx, p.backoff(n+1)) {
+			p.giveUps.Add(1)
+			break
+		}
+	}
+
+	result.Start = start
+	result.Latency = time.Since(start)
+	p.mu.Lock()
+	recordDuration(p.final, result.Latency)
+	p.mu.Unlock()
+	return result
+}
+
+// retryable 尝试的状态码或失败的错误类型是否需要重试; 状态码不论是否通过验证都会重试
+func (p *retryPolicy) retryable(r Result) bool {
+	if r.StatusCode != 0 && p.statuses[r.StatusCode] {
+		return true
+	}
+	if r.Success {
+		return false
+	}
+	if p.errors[r.ErrorType] {
+		return true
+	}
+	return p.errors["connection_reset"] && errors.Is(r.Err, syscall.ECONNRESET)
+}
+
+// takeBudget 占用一次重试, 超出预算时返回 false
+func (p *retryPolicy) takeBudget() bool {
+	if p.cfg.Budget <= 0 {
+		p.retries.Add(1)
+		return true
+	}
+	limit := max(int64(p.cfg.Budget*float64(p.requests.Load())), minRetryBudget)
+	if p.retries.Add(1) > limit {
+		p.retries.Add(-1)
+		return false
+	}
+	return true
+}
+
+// backoff 第 n 次重试前的等待时间
+func (p *retryPolicy) backoff(n int) time.Duration {
+	d := p.cfg.Backoff
+	for i := 1; i < n && d > 0; i++ {
+		d *= 2
+		if p.cfg.MaxBackoff > 0 && d >= p.cfg.MaxBackoff {
+			break
+		}
+	}
+	if p.cfg.MaxBackoff > 0 && d > p.cfg.MaxBackoff {
+		d = p.cfg.MaxBackoff
+	}
+	if p.cfg.Jitter > 0 {
+		d -= time.Duration(rand.Float64() * p.cfg.Jitter * float64(d))
+	}
+	return d
+}
+
+// sleepContext 等待 d, 上下文取消时提前返回 false
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// addStats 写入重试统计, 策略为 nil 时不写入
+func (p *retryPolicy) addStats(stats map[string]float64) {
+	if p == nil {
+		return
+	}
+	requests, attempts := p.requests.Load(), p.attempts.Load()
+	stats["attempts"] = float64(attempts)
+	stats["retries"] = float64(p.retries.Load())
+	stats["retried_requests"] = float64(p.retried.Load())
+	stats["retry_recovered"] = float64(p.recovered.Load())
+	stats["retry_give_ups"] = float64(p.giveUps.Load())
+	if p.cfg.Budget > 0 {
+		stats["retry_budget_exhausted"] = float64(p.budgetDenials.Load())
+	}
+	if requests > 0 {
+		stats["retry_amplification"] = float64(attempts) / float64(requests)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	addDurationStats(stats, "first_attempt", p.first)
+	addDurationStats(stats, "final", p.final)
+}
@@ -0,0 +1,93 @@
+package benchmark
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"httpbench/pkg/config"
+)
+
+// TestRetry 测试重试策略、重试预算和退避等待
+func TestRetry(t *testing.T) {
+	var count atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != "payload" {
+			t.Errorf("重试时请求体为 %q", body)
+		}
+		// /flaky 每个请求的前两次尝试失败
+		if r.URL.Path == "/down" || r.URL.Path == "/flaky" && count.Add(1)%3 != 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		io.WriteString(w, "ok")
+	}))
+	defer server.Close()
+
+	// run 返回结果和其中的重试统计
+	run := func(path string, total int, retry config.RetryConfig) (*Results, map[string]float64) {
+		t.Helper()
+		results := runBenchmark(t, &config.Config{
+			Target:   config.TargetConfig{URL: server.URL + path, Method: http.MethodPost, Body: "payload", Timeout: 5 * time.Second},
+			Load:     config.LoadConfig{Concurrency: 1, TotalRequests: total},
+			Protocol: config.ProtocolConfig{KeepAlive: true},
+			Request:  config.RequestConfig{Retry: retry},
+		})
+		return results, results.ProtocolStats
+	}
+
+	// 重试后成功: 逻辑请求数不变, 尝试次数单独统计
+	results, stats := run("/flaky", 5, config.RetryConfig{MaxAttempts: 3, Backoff: 20 * time.Millisecond})
+	if results.TotalRequests != 5 || results.SuccessRequests != 5 {
+		t.Errorf("请求 %d, 成功 %d", results.TotalRequests, results.SuccessRequests)
+	}
+	if stats["attempts"] != 15 || stats["retries"] != 10 || stats["retry_amplification"] != 3 {
+		t.Errorf("尝试统计错误: %v", stats)
+	}
+	if stats["retried_requests"] != 5 || stats["retry_recovered"] != 5 || stats["retry_give_ups"] != 0 {
+		t.Errorf("重试结果统计错误: %v", stats)
+	}
+	// 最终延迟包含两次退避 (20ms + 40ms)
+	if stats["final_ms_mean"] < 60 || stats["first_attempt_ms_mean"] >= stats["final_ms_mean"] {
+		t.Errorf("第一次尝试 %.1fms, 最终 %.1fms", stats["first_attempt_ms_mean"], stats["final_ms_mean"])
+	}
+
+	// 一直失败: 达到次数上限或超出预算后放弃
+	results, stats = run("/down", 20, config.RetryConfig{MaxAttempts: 3, Budget: 0.1})
+	if results.TotalRequests != 20 {
+		t.Errorf("请求 %d", results.TotalRequests)
+	}
+	if stats["retries"] != minRetryBudget || stats["retry_give_ups"] != 20 || stats["retry_budget_exhausted"] != 15 {
+		t.Errorf("预算统计错误: %v", stats)
+	}
+	if stats["retry_amplification"] != 1.5 {
+		t.Errorf("重试放大 %.2f, 期望 1.5", stats["retry_amplification"])
+	}
+
+	// 不匹配的状态码不重试
+	_, stats = run("/down", 3, config.RetryConfig{MaxAttempts: 3, StatusCodes: []int{502}})
+	if stats["attempts"] != 3 || stats["retry_give_ups"] != 0 {
+		t.Errorf("不应重试: %v", stats)
+	}
+
+	// 退避: 指数增长, 不超过上限, 抖动只会减少等待时间
+	p := newRetryPolicy(config.RetryConfig{MaxAttempts: 5, Backoff: 10 * time.Millisecond, MaxBackoff: 35 * time.Millisecond})
+	for n, want := range []time.Duration{10, 20, 35, 35} {
+		if got := p.backoff(n + 1); got != want*time.Millisecond {
+			t.Errorf("第 %d 次重试等待 %v, 期望 %v", n+1, got, want*time.Millisecond)
+		}
+	}
+	p.cfg.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		if d := p.backoff(1); d < 5*time.Millisecond || d > 10*time.Millisecond {
+			t.Fatalf("抖动后等待 %v", d)
+		}
+	}
+	if newRetryPolicy(config.RetryConfig{MaxAttempts: 1}) != nil {
+		t.Errorf("一次尝试不应创建重试策略")
+	}
+}
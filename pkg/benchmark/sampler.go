@@ -0,0 +1,63 @@
+package benchmark
+
+import (
+	"context"
+	"time"
+
+	"httpbench/pkg/stats"
+)
+
+// defaultMonitorInterval 未配置采样间隔时的默认值
+const defaultMonitorInterval = time.Second
+
+// IntervalHandler 采样间隔回调
+//
+// 回调在采样协程中同步执行,耗时操作应自行异步处理,避免拖慢采样。
+type IntervalHandler func(point stats.TimePoint)
+
+// OnInterval 注册采样间隔回调,须在Run之前调用
+func (b *Benchmark) OnInterval(handler IntervalHandler) {
+	b.intervalHandlers = append(b.intervalHandlers, handler)
+}
+
+// monitorInterval 获取采样间隔
+func (b *Benchmark) monitorInterval() time.Duration {
+	if b.config.Output.MonitorInterval > 0 {
+		return b.config.Output.MonitorInterval
+	}
+	return defaultMonitorInterval
+}
+
+// intervalSampler 按采样间隔采集区间指标,与是否开启实时监控无关
+func (b *Benchmark) intervalSampler(ctx context.Context) {
+	// 第一个间隔从Run开始计算,不包括创建与准备执行器的时间
+	b.stats.StartSampling()
+	ticker := time.NewTicker(b.monitorInterval())
+	defer ticker.Stop()
+
+	if b.config.Output.RealtimeMonitor {
+		b.printMonitorHeader()
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			// 记录最后一个不完整的间隔
+			b.dispatchInterval(b.stats.Sample())
+			return
+		case <-ticker.C:
+			b.selfMon.Load().sample()
+			b.dispatchInterval(b.stats.Sample())
+		}
+	}
+}
+
+// dispatchInterval 分发采样结果
+func (b *Benchmark) dispatchInterval(point stats.TimePoint) {
+	if b.config.Output.RealtimeMonitor {
+		b.realtimeMonitor(point)
+	}
+	for _, handler := range b.intervalHandlers {
+		handler(point)
+	}
+}
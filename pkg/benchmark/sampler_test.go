@@ -0,0 +1,74 @@
+package benchmark
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"httpbench/pkg/config"
+	"httpbench/pkg/stats"
+)
+
+// TestIntervalTimeSeries 测试区间时间序列采样
+func TestIntervalTimeSeries(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(2 * time.Millisecond)
+		w.Write([]byte("OK"))
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{
+		Target: config.TargetConfig{
+			URL:     server.URL,
+			Method:  "GET",
+			Timeout: 5 * time.Second,
+		},
+		Load: config.LoadConfig{
+			Concurrency: 4,
+			Duration:    550 * time.Millisecond,
+		},
+		Protocol: config.ProtocolConfig{
+			KeepAlive: true,
+		},
+		Output: config.OutputConfig{
+			MonitorInterval: 100 * time.Millisecond,
+		},
+	}
+
+	bench := newTestBenchmark(t, cfg)
+	defer bench.Close()
+
+	intervals := 0
+	bench.OnInterval(func(point stats.TimePoint) {
+		intervals++
+	})
+
+	results, err := bench.Run(context.Background())
+	if err != nil {
+		t.Fatalf("运行基准测试失败: %v", err)
+	}
+
+	if len(results.TimeSeries) < 5 {
+		t.Fatalf("时间序列点数过少: %d", len(results.TimeSeries))
+	}
+	if intervals != len(results.TimeSeries) {
+		t.Errorf("回调次数不匹配: got %d, want %d", intervals, len(results.TimeSeries))
+	}
+
+	// 各区间请求数之和应等于总请求数
+	var total int64
+	for _, point := range results.TimeSeries {
+		total += point.Requests
+		if point.Requests > 0 && (point.P50Latency <= 0 || point.MaxLatency < point.P99Latency) {
+			t.Errorf("区间延迟分布异常: %+v", point)
+		}
+	}
+	if total != results.TotalRequests {
+		t.Errorf("区间请求数之和不匹配: got %d, want %d", total, results.TotalRequests)
+	}
+	if point := results.TimeSeries[0]; point.RPS <= 0 || point.Throughput <= 0 {
+		t.Errorf("首个区间RPS/吞吐量应大于0: %+v", point)
+	}
+}
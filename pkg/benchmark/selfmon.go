@@ -0,0 +1,232 @@
+package benchmark
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// 客户端饱和判定阈值
+const (
+	// 平均CPU使用率(相对 GOMAXPROCS)
+	saturationCPUPercent = 90.0
+	// GC暂停占运行时长的比例
+	saturationGCFraction = 0.05
+	// 打开的文件描述符占上限的比例
+	saturationFDFraction = 0.9
+	// 调度延迟超过该值的请求视为迟发
+	lateSendThreshold = 10 * time.Millisecond
+	// 迟发请求比例
+	saturationLateFraction = 0.05
+	// 运行时长过短时CPU和GC占比噪声太大,不做判定
+	saturationMinWindow = time.Second
+	// 调度延迟样本过少时个别迟发的请求就会超过比例,不做判定
+	saturationMinSchedSamples = 100
+)
+
+// ClientStats 压测客户端自身的资源使用
+//
+// 客户端成为瓶颈时测得的延迟和吞吐量反映的是压测机而非目标服务。
+type ClientStats struct {
+	// CPU使用率, 100% 表示 GOMAXPROCS 个核心全部占满; 平台不支持时为 -1
+	CPUPercent     float64
+	PeakCPUPercent float64
+	GOMAXPROCS     int
+
+	// GC
+	NumGC        uint32
+	GCPauseTotal time.Duration
+	GCPauseMax   time.Duration
+
+	// 协程数峰值
+	PeakGoroutines int
+
+	// 打开的文件描述符峰值及上限; 平台不支持时为 -1
+	PeakOpenFDs int
+	FDLimit     int
+
+	// 速率限制器调度延迟: 计划发送时间与请求离开限速器的差值, 仅在限速时统计
+	//
+	// 不包括等待空闲工作协程的时间, 工作协程全忙时生成协程阻塞的时间计入排队等待。
+	SchedSamples int64
+	SchedLagMean time.Duration
+	SchedLagMax  time.Duration
+	LateRequests int64
+
+	// 请求离开限速器到被工作协程取走的排队等待, 仅在限速时统计;
+	// 等待长说明并发数不足以达到目标速率 (通常是目标服务变慢), 不代表客户端饱和
+	QueueWaitMean time.Duration
+	QueueWaitMax  time.Duration
+
+	// 客户端可能是瓶颈
+	Saturated bool
+	Warnings  []string
+}
+
+// selfMonitor 客户端自身资源采样
+type selfMonitor struct {
+	start    time.Time
+	startCPU time.Duration
+
+	mu          sync.Mutex
+	lastSample  time.Time
+	lastCPU     time.Duration
+	peakCPU     float64
+	peakRoutine int
+	peakFDs     int
+	startGC     runtime.MemStats
+	lastGC      runtime.MemStats
+	pauseMax    time.Duration
+
+	// 调度延迟和排队等待
+	lag   delayCounter
+	queue delayCounter
+}
+
+// delayCounter 并发安全的延迟计数
+type delayCounter struct {
+	count atomic.Int64
+	sum   atomic.Int64
+	max   atomic.Int64
+	late  atomic.Int64
+}
+
+// record 记录一次延迟, 超过 lateSendThreshold 时计为迟发
+func (c *delayCounter) record(d time.Duration) {
+	c.count.Add(1)
+	c.sum.Add(int64(d))
+	if d > lateSendThreshold {
+		c.late.Add(1)
+	}
+	for {
+		current := c.max.Load()
+		if int64(d) <= current || c.max.CompareAndSwap(current, int64(d)) {
+			return
+		}
+	}
+}
+
+// mean 平均延迟, 没有样本时为 0
+func (c *delayCounter) mean() time.Duration {
+	if n := c.count.Load(); n > 0 {
+		return time.Duration(c.sum.Load() / n)
+	}
+	return 0
+}
+
+// newSelfMonitor 创建采样器并记录基线
+func newSelfMonitor() *selfMonitor {
+	m := &selfMonitor{
+		start:   time.Now(),
+		peakFDs: -1,
+	}
+	m.startCPU, _ = processCPUTime()
+	m.lastSample, m.lastCPU = m.start, m.startCPU
+	runtime.ReadMemStats(&m.startGC)
+	m.lastGC = m.startGC
+	return m
+}
+
+// sample 采样一次, 在区间采样协程中调用
+func (m *selfMonitor) sample() {
+	now := time.Now()
+	cpu, cpuOK := processCPUTime()
+	fds, fdsOK := openFDs()
+	routines := runtime.NumGoroutine()
+
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if cpuOK {
+		// 过短的间隔噪声太大,不计入峰值
+		if wall := now.Sub(m.lastSample); wall >= 100*time.Millisecond {
+			m.peakCPU = max(m.peakCPU, cpuPercent(cpu-m.lastCPU, wall))
+		}
+		m.lastCPU = cpu
+	}
+	m.lastSample = now
+
+	if fdsOK {
+		m.peakFDs = max(m.peakFDs, fds)
+	}
+	m.peakRoutine = max(m.peakRoutine, routines)
+
+	// 自上次采样以来的GC暂停 (PauseNs 为最近256次的环形缓冲)
+	for gc := max(m.lastGC.NumGC, mem.NumGC-min(mem.NumGC, 256)); gc < mem.NumGC; gc++ {
+		m.pauseMax = max(m.pauseMax, time.Duration(mem.PauseNs[gc%256]))
+	}
+	m.lastGC = mem
+}
+
+// recordLag 记录一次调度延迟, 在请求离开限速器时调用
+func (m *selfMonitor) recordLag(lag time.Duration) {
+	m.lag.record(lag)
+}
+
+// recordQueueWait 记录一次排队等待, 在工作协程取走请求时调用
+func (m *selfMonitor) recordQueueWait(wait time.Duration) {
+	m.queue.record(wait)
+}
+
+// stats 汇总客户端资源使用并判断是否饱和
+func (m *selfMonitor) stats() *ClientStats {
+	m.sample()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	elapsed := m.lastSample.Sub(m.start)
+	cs := &ClientStats{
+		CPUPercent:     -1,
+		PeakCPUPercent: -1,
+		GOMAXPROCS:     runtime.GOMAXPROCS(0),
+		NumGC:          m.lastGC.NumGC - m.startGC.NumGC,
+		GCPauseTotal:   time.Duration(m.lastGC.PauseTotalNs - m.startGC.PauseTotalNs),
+		GCPauseMax:     m.pauseMax,
+		PeakGoroutines: m.peakRoutine,
+		PeakOpenFDs:    m.peakFDs,
+		FDLimit:        -1,
+		SchedSamples:   m.lag.count.Load(),
+		SchedLagMean:   m.lag.mean(),
+		SchedLagMax:    time.Duration(m.lag.max.Load()),
+		LateRequests:   m.lag.late.Load(),
+		QueueWaitMean:  m.queue.mean(),
+		QueueWaitMax:   time.Duration(m.queue.max.Load()),
+	}
+	if _, ok := processCPUTime(); ok && elapsed > 0 {
+		cs.CPUPercent = cpuPercent(m.lastCPU-m.startCPU, elapsed)
+		cs.PeakCPUPercent = max(m.peakCPU, cs.CPUPercent)
+	}
+	if limit, ok := fdLimit(); ok {
+		cs.FDLimit = limit
+	}
+
+	if elapsed >= saturationMinWindow && cs.CPUPercent >= saturationCPUPercent {
+		cs.Warnings = append(cs.Warnings, fmt.Sprintf("客户端CPU使用率 %.0f%% (GOMAXPROCS=%d)", cs.CPUPercent, cs.GOMAXPROCS))
+	}
+	if elapsed >= saturationMinWindow && float64(cs.GCPauseTotal) > float64(elapsed)*saturationGCFraction {
+		cs.Warnings = append(cs.Warnings, fmt.Sprintf("GC暂停共 %v, 占运行时长 %.1f%%",
+			cs.GCPauseTotal.Round(time.Microsecond), float64(cs.GCPauseTotal)/float64(elapsed)*100))
+	}
+	if cs.PeakOpenFDs > 0 && cs.FDLimit > 0 && float64(cs.PeakOpenFDs) >= float64(cs.FDLimit)*saturationFDFraction {
+		cs.Warnings = append(cs.Warnings, fmt.Sprintf("打开的文件描述符 %d 接近上限 %d", cs.PeakOpenFDs, cs.FDLimit))
+	}
+	if elapsed >= saturationMinWindow && cs.SchedSamples >= saturationMinSchedSamples &&
+		float64(cs.LateRequests) > float64(cs.SchedSamples)*saturationLateFraction {
+		cs.Warnings = append(cs.Warnings, fmt.Sprintf("%.1f%% 的请求晚于计划时间 %v 以上发出 (最大 %v), 未能达到目标速率",
+			float64(cs.LateRequests)/float64(cs.SchedSamples)*100, lateSendThreshold, cs.SchedLagMax.Round(time.Microsecond)))
+	}
+	cs.Saturated = len(cs.Warnings) > 0
+
+	return cs
+}
+
+// cpuPercent CPU时间占可用核心时间的百分比
+func cpuPercent(cpu, wall time.Duration) float64 {
+	return float64(cpu) / float64(wall) / float64(runtime.GOMAXPROCS(0)) * 100
+}
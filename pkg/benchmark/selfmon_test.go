@@ -0,0 +1,96 @@
+package benchmark
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"httpbench/pkg/config"
+)
+
+// TestSelfMonitor 测试客户端自身资源监控和饱和判定
+func TestSelfMonitor(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("OK"))
+	}))
+	defer server.Close()
+
+	results := runBenchmark(t, &config.Config{
+		Target: config.TargetConfig{
+			URL:     server.URL,
+			Method:  "GET",
+			Timeout: 5 * time.Second,
+		},
+		Load: config.LoadConfig{
+			Concurrency: 2,
+			Duration:    300 * time.Millisecond,
+			RateLimit:   50,
+		},
+		Protocol: config.ProtocolConfig{
+			KeepAlive: true,
+		},
+	})
+
+	cs := results.Client
+	if cs == nil {
+		t.Fatal("结果应包含客户端资源统计")
+	}
+	// 停止时已离开限速器但未执行的请求也有调度延迟
+	if cs.SchedSamples < results.TotalRequests {
+		t.Errorf("限速时每个请求都应记录调度延迟: got %d, want >= %d", cs.SchedSamples, results.TotalRequests)
+	}
+	if cs.PeakGoroutines == 0 || cs.GOMAXPROCS == 0 {
+		t.Errorf("协程峰值和GOMAXPROCS应大于0: %+v", cs)
+	}
+	if cs.Saturated {
+		t.Errorf("低负载下不应判定为饱和: %v", cs.Warnings)
+	}
+
+	// 样本太少或运行时间太短时不判定
+	mon := newSelfMonitor()
+	for i := 0; i < 100; i++ {
+		mon.recordLag(time.Duration(i) * time.Millisecond)
+	}
+	if cs := mon.stats(); cs.Saturated {
+		t.Errorf("运行时间过短不应判定为饱和: %v", cs.Warnings)
+	}
+	mon = newSelfMonitor()
+	mon.start = mon.start.Add(-2 * saturationMinWindow)
+	for i := 0; i < 20; i++ {
+		mon.recordLag(time.Second)
+	}
+	if cs := mon.stats(); cs.Saturated {
+		t.Errorf("样本过少不应判定为饱和: %v", cs.Warnings)
+	}
+
+	// 大量请求迟发时判定为饱和, 排队等待不计入
+	mon = newSelfMonitor()
+	mon.start = mon.start.Add(-2 * saturationMinWindow)
+	for i := 0; i < 100; i++ {
+		mon.recordLag(time.Duration(i) * time.Millisecond)
+		mon.recordQueueWait(time.Second)
+	}
+	cs = mon.stats()
+	if !cs.Saturated || len(cs.Warnings) != 1 || !strings.Contains(cs.Warnings[0], "目标速率") {
+		t.Errorf("调度延迟过大应判定为饱和: %v", cs.Warnings)
+	}
+	if cs.SchedLagMax != 99*time.Millisecond || cs.LateRequests != 89 {
+		t.Errorf("调度延迟统计不匹配: max=%v late=%d", cs.SchedLagMax, cs.LateRequests)
+	}
+	if cs.QueueWaitMean != time.Second || cs.QueueWaitMax != time.Second {
+		t.Errorf("排队等待统计不匹配: mean=%v max=%v", cs.QueueWaitMean, cs.QueueWaitMax)
+	}
+
+	// 目标服务变慢时只有排队等待, 不判定为饱和
+	mon = newSelfMonitor()
+	mon.start = mon.start.Add(-2 * saturationMinWindow)
+	for i := 0; i < 200; i++ {
+		mon.recordLag(0)
+		mon.recordQueueWait(100 * time.Millisecond)
+	}
+	if cs := mon.stats(); cs.Saturated {
+		t.Errorf("排队等待不应判定为饱和: %v", cs.Warnings)
+	}
+}
@@ -0,0 +1,39 @@
+//go:build !windows
+
+package benchmark
+
+import (
+	"math"
+	"os"
+	"syscall"
+	"time"
+)
+
+// processCPUTime 进程累计CPU时间(用户态+内核态)
+func processCPUTime() (time.Duration, bool) {
+	var usage syscall.Rusage
+	if err := syscall.Getrusage(syscall.RUSAGE_SELF, &usage); err != nil {
+		return 0, false
+	}
+	return time.Duration(usage.Utime.Nano() + usage.Stime.Nano()), true
+}
+
+// openFDs 当前打开的文件描述符数量
+func openFDs() (int, bool) {
+	for _, dir := range []string{"/proc/self/fd", "/dev/fd"} {
+		entries, err := os.ReadDir(dir)
+		if err == nil {
+			return len(entries), true
+		}
+	}
+	return 0, false
+}
+
+// fdLimit 文件描述符软上限
+func fdLimit() (int, bool) {
+	var limit syscall.Rlimit
+	if err := syscall.Getrlimit(syscall.RLIMIT_NOFILE, &limit); err != nil {
+		return 0, false
+	}
+	return int(min(uint64(limit.Cur), math.MaxInt32)), true
+}
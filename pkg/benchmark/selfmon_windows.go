@@ -0,0 +1,20 @@
+//go:build windows
+
+package benchmark
+
+import "time"
+
+// processCPUTime Windows 下暂不支持
+func processCPUTime() (time.Duration, bool) {
+	return 0, false
+}
+
+// openFDs Windows 下暂不支持
+func openFDs() (int, bool) {
+	return 0, false
+}
+
+// fdLimit Windows 下暂不支持
+func fdLimit() (int, bool) {
+	return 0, false
+}
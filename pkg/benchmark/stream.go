@@ -0,0 +1,357 @@
+package benchmark
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/HdrHistogram/hdrhistogram-go"
+
+	"httpbench/pkg/config"
+)
+
+// errStreamLimit 读取到配置的上限, 主动结束流
+var errStreamLimit = errors.New("达到流的读取上限")
+
+// streamStats 流式响应统计
+type streamStats struct {
+	cfg  config.StreamConfig
+	idle time.Duration
+
+	start     time.Time
+	streams   atomic.Int64
+	events    atomic.Int64
+	truncated atomic.Int64
+
+	mu         sync.Mutex
+	ttfb       *hdrhistogram.Histogram
+	firstEvent *hdrhistogram.Histogram
+	gap        *hdrhistogram.Histogram
+	duration   *hdrhistogram.Histogram
+}
+
+// newStreamStats 未启用流式模式时返回 nil
+func newStreamStats(cfg *config.Config) *streamStats {
+	if !cfg.Protocol.Stream.Enabled {
+		return nil
+	}
+	return &streamStats{
+		cfg:        cfg.Protocol.Stream,
+		idle:       cfg.Target.Timeout,
+		ttfb:       newDurationHistogram(),
+		firstEvent: newDurationHistogram(),
+		gap:        newDurationHistogram(),
+		duration:   newDurationHistogram(),
+	}
+}
+
+// streamReading 单个流的读取状态
+type streamReading struct {
+	stats *streamStats
+	body  io.Reader
+	keep  *bytes.Buffer
+
+	start  time.Time
+	last   time.Time
+	first  time.Duration
+	gaps   []time.Duration
+	events int
+	bytes  int64
+
+	// 空闲超时计时器, 每次读到数据时重置
+	idle *time.Timer
+}
+
+// Read 统计字节数并重置空闲计时器
+func (r *streamReading) Read(p []byte) (int, error) {
+	if limit := r.stats.cfg.MaxBytes; limit > 0 {
+		if r.bytes >= limit {
+			return 0, errStreamLimit
+		}
+		p = p[:min(int64(len(p)), limit-r.bytes)]
+	}
+
+	n, err := r.body.Read(p)
+	if n > 0 {
+		r.bytes += int64(n)
+		if r.keep != nil {
+			r.keep.Write(p[:n])
+		}
+		if r.idle != nil {
+			r.idle.Reset(r.stats.idle)
+		}
+	}
+	return n, err
+}
+
+// event 记录一个完整事件, 达到事件数上限时返回 errStreamLimit
+func (r *streamReading) event() error {
+	now := time.Now()
+	if r.events == 0 {
+		r.first = now.Sub(r.start)
+	} else {
+		r.gaps = append(r.gaps, now.Sub(r.last))
+	}
+	r.last = now
+	r.events++
+
+	if limit := r.stats.cfg.MaxEvents; limit > 0 && r.events >= limit {
+		return errStreamLimit
+	}
+	return nil
+}
+
+// streamFormat 流的事件格式, 未配置时按 Content-Type 推断
+func (s *streamStats) streamFormat(resp *http.Response) config.StreamFormat {
+	if s.cfg.Format != "" {
+		return s.cfg.Format
+	}
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	switch mediaType {
+	case "text/event-stream":
+		return config.StreamFormatSSE
+	case "application/x-ndjson", "application/ndjson", "application/jsonl", "application/json-seq":
+		return config.StreamFormatNDJSON
+	default:
+		return config.StreamFormatChunk
+	}
+}
+
+// executeStream 发送请求并按事件读取流式响应
+func (e *httpExecutor) executeStream(ctx context.Context, workerID int) (Result, error) {
+	s := e.stream
+	result := Result{Start: time.Now()}
+
+	// 空闲超时和持续时间上限都通过取消上下文中断读取
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var idleExpired, durationReached atomic.Bool
+	var idle *time.Timer
+	if s.idle > 0 {
+		idle = time.AfterFunc(s.idle, func() {
+			idleExpired.Store(true)
+			cancel()
+		})
+		defer idle.Stop()
+	}
+	if s.cfg.MaxDuration > 0 {
+		limit := time.AfterFunc(s.cfg.MaxDuration, func() {
+			durationReached.Store(true)
+			cancel()
+		})
+		defer limit.Stop()
+	}
+
+	// 连接、TLS 握手和响应头的阶段超时, 读取流的空闲超时由上面的计时器负责
+	ctx, _, done := withPhaseTimeouts(ctx, e.timeouts)
+	defer done()
+
+	// 创建请求
+	req, endpoint, err := e.requests.build(ctx, workerID)
+	if err != nil {
+		return result, err
+	}
+	host := e.hosts.acquire()
+	defer host.release()
+	result.Endpoint = host.route(req, endpoint)
+	if e.dialer.pool.recycles() {
+		req = e.dialer.pool.withRecycling(req)
+	}
+	result.BytesSent = req.ContentLength
+
+	// 发送请求
+	resp, err := e.client.Do(req)
+	if err != nil {
+		result.Latency = time.Since(result.Start)
+		if idleExpired.Load() {
+			result.Fail("timeout", err)
+		} else {
+			result.Fail(requestErrorType(ctx, err, "network"), err)
+		}
+		return result, nil
+	}
+	defer resp.Body.Close()
+	result.StatusCode = resp.StatusCode
+	ttfb := time.Since(result.Start)
+	if idle != nil {
+		idle.Reset(s.idle)
+	}
+
+	r := &streamReading{
+		stats: s,
+		body:  resp.Body,
+		start: result.Start,
+		idle:  idle,
+	}
+	if e.validator.NeedsBody() {
+		r.keep = getBuffer()
+		defer putBuffer(r.keep)
+	}
+
+	switch s.streamFormat(resp) {
+	case config.StreamFormatSSE:
+		err = readSSE(r)
+	case config.StreamFormatNDJSON:
+		err = readLines(r)
+	default:
+		err = readChunks(r)
+	}
+	result.Latency = time.Since(result.Start)
+	result.BytesReceived = r.bytes
+
+	truncated := errors.Is(err, errStreamLimit) || durationReached.Load()
+	if truncated {
+		err = nil
+	}
+	s.record(ttfb, r, truncated)
+
+	switch {
+	case err != nil && idleExpired.Load():
+		result.Fail("timeout", err)
+		return result, nil
+	case err != nil:
+		result.Fail("body_read", err)
+		return result, nil
+	}
+
+	// 验证响应
+	var body []byte
+	if r.keep != nil {
+		body = r.keep.Bytes()
+	}
+	if validationErr := e.validator.Validate(resp, body); validationErr != nil {
+		result.Fail(validationErrorType(validationErr), validationErr)
+	} else {
+		result.Success = true
+	}
+	return result, nil
+}
+
+// readSSE 读取 text/event-stream, 空行结束一个事件, 仅含注释的块 (心跳) 不计为事件
+func readSSE(r *streamReading) error {
+	br := bufio.NewReader(r)
+	pending := false
+	for {
+		line, err := readStreamLine(br)
+		if len(line) == 0 && err == nil {
+			if pending {
+				if err := r.event(); err != nil {
+					return err
+				}
+			}
+			pending = false
+			continue
+		}
+		if len(line) > 0 && line[0] != ':' {
+			pending = true
+		}
+		if err != nil {
+			// 流结束时未以空行结尾的事件按规范丢弃
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// readLines 读取 NDJSON, 每个非空行为一个事件
+func readLines(r *streamReading) error {
+	br := bufio.NewReader(r)
+	for {
+		line, err := readStreamLine(br)
+		if len(bytes.TrimSpace(line)) > 0 {
+			if err := r.event(); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// readChunks 每次读到的数据块为一个事件, 通常与服务端的每次 flush 对应
+func readChunks(r *streamReading) error {
+	buf := make([]byte, 32<<10)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			if err := r.event(); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// readStreamLine 读取一行 (不含换行符), 超长的行跳过超出缓冲的部分
+func readStreamLine(br *bufio.Reader) ([]byte, error) {
+	line, err := br.ReadSlice('\n')
+	if err == bufio.ErrBufferFull {
+		// 只需判断行是否为空或注释, 保留第一个字节即可
+		head := []byte{line[0]}
+		for err == bufio.ErrBufferFull {
+			_, err = br.ReadSlice('\n')
+		}
+		return head, err
+	}
+	return bytes.TrimRight(line, "\r\n"), err
+}
+
+// record 记录单个流的时间指标
+func (s *streamStats) record(ttfb time.Duration, r *streamReading, truncated bool) {
+	s.streams.Add(1)
+	s.events.Add(int64(r.events))
+	if truncated {
+		s.truncated.Add(1)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	recordDuration(s.ttfb, ttfb)
+	recordDuration(s.duration, time.Since(r.start))
+	if r.events > 0 {
+		recordDuration(s.firstEvent, r.first)
+	}
+	for _, gap := range r.gaps {
+		recordDuration(s.gap, gap)
+	}
+}
+
+// addStats 写入流统计
+func (s *streamStats) addStats(stats map[string]float64) {
+	streams := s.streams.Load()
+	events := s.events.Load()
+	stats["streams"] = float64(streams)
+	stats["streams_truncated"] = float64(s.truncated.Load())
+	stats["events"] = float64(events)
+	if streams > 0 {
+		stats["events_per_stream"] = float64(events) / float64(streams)
+	}
+	if elapsed := time.Since(s.start).Seconds(); !s.start.IsZero() && elapsed > 0 {
+		stats["events_per_sec"] = float64(events) / elapsed
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	addDurationStats(stats, "ttfb", s.ttfb)
+	addDurationStats(stats, "first_event", s.firstEvent)
+	addDurationStats(stats, "event_gap", s.gap)
+	addDurationStats(stats, "stream_duration", s.duration)
+}
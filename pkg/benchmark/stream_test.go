@@ -0,0 +1,116 @@
+package benchmark
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"httpbench/pkg/config"
+)
+
+// TestStreaming 测试流式响应的事件计数、时间指标和读取上限
+func TestStreaming(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/sse", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		flusher := w.(http.Flusher)
+		time.Sleep(20 * time.Millisecond)
+		for i := 0; i < 5; i++ {
+			// 心跳注释不计为事件
+			fmt.Fprintf(w, ": ping\n\nid: %d\nevent: token\ndata: {\"n\":%d}\r\n\r\n", i, i)
+			flusher.Flush()
+			time.Sleep(10 * time.Millisecond)
+		}
+		io.WriteString(w, "data: incomplete")
+	})
+	mux.HandleFunc("/ndjson", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		for i := 0; i < 8; i++ {
+			fmt.Fprintf(w, "{\"n\":%d}\n\n", i)
+			w.(http.Flusher).Flush()
+		}
+		io.WriteString(w, `{"done":true}`)
+	})
+	mux.HandleFunc("/endless", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for {
+			if _, err := io.WriteString(w, "data: tick\n\n"); err != nil {
+				return
+			}
+			w.(http.Flusher).Flush()
+			select {
+			case <-r.Context().Done():
+				return
+			case <-time.After(5 * time.Millisecond):
+			}
+		}
+	})
+	mux.HandleFunc("/stall", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		io.WriteString(w, "data: first\n\n")
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	newConfig := func(path string, stream config.StreamConfig, timeout time.Duration) *config.Config {
+		stream.Enabled = true
+		return &config.Config{
+			Target:   config.TargetConfig{URL: server.URL + path, Method: "GET", Timeout: timeout},
+			Load:     config.LoadConfig{Concurrency: 2, TotalRequests: 4},
+			Protocol: config.ProtocolConfig{KeepAlive: true, Stream: stream},
+		}
+	}
+
+	// SSE: 每个流5个事件, 首个事件晚于响应头, 未结束的事件被丢弃
+	results := runBenchmark(t, newConfig("/sse", config.StreamConfig{}, 5*time.Second))
+	ps := results.ProtocolStats
+	if results.SuccessRequests != 4 || ps["streams"] != 4 || ps["events"] != 20 || ps["events_per_stream"] != 5 {
+		t.Errorf("SSE: 成功 %d, 协议统计 %v", results.SuccessRequests, ps)
+	}
+	if ps["first_event_ms_mean"] < 20 || ps["first_event_ms_mean"] < ps["ttfb_ms_mean"] {
+		t.Errorf("首个事件时间不合理: %v", ps)
+	}
+	if ps["event_gap_ms_mean"] < 9 || ps["stream_duration_ms_mean"] < 60 || ps["events_per_sec"] <= 0 {
+		t.Errorf("事件间隔或流持续时间不合理: %v", ps)
+	}
+	if results.Latency.Mean < 60*time.Millisecond {
+		t.Errorf("请求延迟应为流的持续时间: %v", results.Latency.Mean)
+	}
+
+	// NDJSON: 空行不计, 最后一行没有换行符也计为事件
+	results = runBenchmark(t, newConfig("/ndjson", config.StreamConfig{}, 5*time.Second))
+	if ps := results.ProtocolStats; ps["events"] != 36 || results.SuccessRequests != 4 {
+		t.Errorf("NDJSON: 成功 %d, 协议统计 %v", results.SuccessRequests, ps)
+	}
+
+	// 强制按数据块计数
+	results = runBenchmark(t, newConfig("/ndjson", config.StreamConfig{Format: config.StreamFormatChunk}, 5*time.Second))
+	if ps := results.ProtocolStats; ps["events"] < 4 || results.SuccessRequests != 4 {
+		t.Errorf("数据块: 成功 %d, 协议统计 %v", results.SuccessRequests, ps)
+	}
+
+	// 无限流按事件数、字节数和持续时间截断, 截断不算失败
+	results = runBenchmark(t, newConfig("/endless", config.StreamConfig{MaxEvents: 10}, 5*time.Second))
+	if ps := results.ProtocolStats; ps["events"] != 40 || ps["streams_truncated"] != 4 || results.SuccessRequests != 4 {
+		t.Errorf("事件数上限: 成功 %d, 协议统计 %v", results.SuccessRequests, ps)
+	}
+	results = runBenchmark(t, newConfig("/endless", config.StreamConfig{MaxBytes: 60}, 5*time.Second))
+	if ps := results.ProtocolStats; ps["events"] != 20 || results.BytesReceived != 240 || results.SuccessRequests != 4 {
+		t.Errorf("字节数上限: 成功 %d, 接收 %d, 协议统计 %v", results.SuccessRequests, results.BytesReceived, ps)
+	}
+	results = runBenchmark(t, newConfig("/endless", config.StreamConfig{MaxDuration: 100 * time.Millisecond}, 5*time.Second))
+	if ps := results.ProtocolStats; ps["streams_truncated"] != 4 || results.SuccessRequests != 4 || ps["stream_duration_ms_max"] > 500 {
+		t.Errorf("持续时间上限: 成功 %d, 错误 %v, 协议统计 %v", results.SuccessRequests, results.ErrorsByType, ps)
+	}
+
+	// target.timeout 是两次读取之间的空闲超时
+	results = runBenchmark(t, newConfig("/stall", config.StreamConfig{}, 100*time.Millisecond))
+	if results.ErrorsByType["timeout"] != 4 || results.ProtocolStats["events"] != 4 {
+		t.Errorf("空闲超时: 错误 %v, 协议统计 %v", results.ErrorsByType, results.ProtocolStats)
+	}
+}
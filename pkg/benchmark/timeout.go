@@ -0,0 +1,153 @@
+package benchmark
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"net"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	"httpbench/pkg/config"
+)
+
+// timeoutError 阶段超时, 作为请求上下文的取消原因
+type timeoutError struct {
+	phase string
+}
+
+func (e *timeoutError) Error() string {
+	return e.phase + " 超时"
+}
+
+var (
+	errDialTimeout           = &timeoutError{"dial"}
+	errTLSTimeout            = &timeoutError{"tls_handshake"}
+	errResponseHeaderTimeout = &timeoutError{"response_header"}
+	errBodyIdleTimeout       = &timeoutError{"body_idle"}
+)
+
+// requestErrorType 请求失败的分类: 阶段超时为 timeout_<阶段>, 整个请求超时为 timeout,
+// 代理失败为 proxy, 其余为 fallback
+func requestErrorType(ctx context.Context, err error, fallback string) string {
+	var te *timeoutError
+	if errors.As(context.Cause(ctx), &te) {
+		return "timeout_" + te.phase
+	}
+	var ne net.Error
+	if errors.As(err, &ne) && ne.Timeout() {
+		return "timeout"
+	}
+	return networkErrorType(err, fallback)
+}
+
+// phaseTimer 一个请求的阶段计时, 各阶段依次进行因此共用一个计时器
+//
+// 通过 httptrace 钩子在连接、TLS 握手和等待响应头时启动计时, 超时后以对应的原因取消请求。
+type phaseTimer struct {
+	cfg    config.TimeoutConfig
+	cancel context.CancelCauseFunc
+
+	mu    sync.Mutex
+	timer *time.Timer
+	// 已拿到连接, 之后其他拨号的事件与本请求无关
+	gotConn bool
+}
+
+// withPhaseTimeouts 为请求上下文登记阶段超时, 未配置时原样返回; 请求结束后调用返回的函数
+func withPhaseTimeouts(ctx context.Context, cfg config.TimeoutConfig) (context.Context, *phaseTimer, func()) {
+	if !cfg.Enabled() {
+		return ctx, nil, func() {}
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	t := &phaseTimer{cfg: cfg, cancel: cancel}
+	trace := &httptrace.ClientTrace{
+		ConnectStart:      func(network, addr string) { t.startDial(cfg.Dial, errDialTimeout) },
+		ConnectDone:       func(network, addr string, err error) { t.stopDial() },
+		TLSHandshakeStart: func() { t.startDial(cfg.TLSHandshake, errTLSTimeout) },
+		TLSHandshakeDone:  func(tls.ConnectionState, error) { t.stopDial() },
+		GotConn: func(httptrace.GotConnInfo) {
+			t.mu.Lock()
+			t.gotConn = true
+			t.mu.Unlock()
+			t.stop()
+		},
+		WroteRequest: func(httptrace.WroteRequestInfo) {
+			t.start(cfg.ResponseHeader, errResponseHeaderTimeout)
+		},
+		GotFirstResponseByte: t.stop,
+	}
+	return httptrace.WithClientTrace(ctx, trace), t, func() {
+		t.stop()
+		cancel(nil)
+	}
+}
+
+// start 启动阶段计时, 替换正在进行的计时
+func (t *phaseTimer) start(d time.Duration, cause error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.startLocked(d, cause)
+}
+
+func (t *phaseTimer) startLocked(d time.Duration, cause error) {
+	if t.timer != nil {
+		t.timer.Stop()
+		t.timer = nil
+	}
+	if d > 0 {
+		t.timer = time.AfterFunc(d, func() { t.cancel(cause) })
+	}
+}
+
+// stop 停止当前阶段的计时
+func (t *phaseTimer) stop() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.timer != nil {
+		t.timer.Stop()
+		t.timer = nil
+	}
+}
+
+// startDial 建立连接阶段的计时, 已拿到连接后忽略
+func (t *phaseTimer) startDial(d time.Duration, cause error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.gotConn {
+		t.startLocked(d, cause)
+	}
+}
+
+func (t *phaseTimer) stopDial() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.gotConn && t.timer != nil {
+		t.timer.Stop()
+		t.timer = nil
+	}
+}
+
+// body 包装响应体, 每次读取超过 body_idle 时取消请求
+func (t *phaseTimer) body(body io.ReadCloser) io.ReadCloser {
+	if t == nil || t.cfg.BodyIdle <= 0 {
+		return body
+	}
+	return &idleBody{ReadCloser: body, t: t}
+}
+
+// idleBody 限制每次读取等待时间的响应体
+type idleBody struct {
+	io.ReadCloser
+	t *phaseTimer
+}
+
+func (b *idleBody) Read(p []byte) (int, error) {
+	b.t.start(b.t.cfg.BodyIdle, errBodyIdleTimeout)
+	n, err := b.ReadCloser.Read(p)
+	b.t.stop()
+	return n, err
+}
@@ -0,0 +1,111 @@
+package benchmark
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"sync"
+	"testing"
+	"time"
+
+	"httpbench/pkg/config"
+)
+
+// TestPhaseTimeouts 测试各阶段超时的触发与错误分类
+func TestPhaseTimeouts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/slow-header":
+			time.Sleep(300 * time.Millisecond)
+		case "/slow-body":
+			io.WriteString(w, "partial")
+			w.(http.Flusher).Flush()
+			time.Sleep(300 * time.Millisecond)
+		}
+		io.WriteString(w, "ok")
+	}))
+	defer server.Close()
+
+	// 接受连接但从不响应 TLS 握手
+	silent, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("监听失败: %v", err)
+	}
+	defer silent.Close()
+	var held sync.Map
+	go func() {
+		for {
+			conn, err := silent.Accept()
+			if err != nil {
+				return
+			}
+			held.Store(conn, true)
+		}
+	}()
+	defer held.Range(func(conn, _ interface{}) bool {
+		conn.(net.Conn).Close()
+		return true
+	})
+
+	newConfig := func(engine config.Engine, url string, timeout time.Duration, timeouts config.TimeoutConfig) *config.Config {
+		return &config.Config{
+			Target:   config.TargetConfig{URL: url, Timeout: timeout, Timeouts: timeouts},
+			Load:     config.LoadConfig{Concurrency: 2, TotalRequests: 4},
+			Protocol: config.ProtocolConfig{KeepAlive: true, Engine: engine},
+			TLS:      config.TLSConfig{Enabled: true, InsecureSkipVerify: true},
+		}
+	}
+
+	phase := 50 * time.Millisecond
+	for _, engine := range []config.Engine{config.EngineStandard, config.EngineRaw} {
+		for _, tc := range []struct {
+			name     string
+			url      string
+			timeout  time.Duration
+			timeouts config.TimeoutConfig
+			want     string
+		}{
+			{"TLS握手", "https://" + silent.Addr().String() + "/", 5 * time.Second, config.TimeoutConfig{TLSHandshake: phase}, "timeout_tls_handshake"},
+			{"响应头", server.URL + "/slow-header", 5 * time.Second, config.TimeoutConfig{ResponseHeader: phase}, "timeout_response_header"},
+			{"响应体空闲", server.URL + "/slow-body", 5 * time.Second, config.TimeoutConfig{ResponseHeader: time.Second, BodyIdle: phase}, "timeout_body_idle"},
+			{"整个请求", server.URL + "/slow-header", phase, config.TimeoutConfig{}, "timeout"},
+		} {
+			results := runBenchmark(t, newConfig(engine, tc.url, tc.timeout, tc.timeouts))
+			if results.ErrorsByType[tc.want] != 4 {
+				t.Errorf("%s %s: 错误 %v, 期望 4 个 %s", engine, tc.name, results.ErrorsByType, tc.want)
+			}
+		}
+
+		// 阶段超时不影响正常请求
+		results := runBenchmark(t, newConfig(engine, server.URL+"/", 5*time.Second, config.TimeoutConfig{
+			Dial: time.Second, TLSHandshake: time.Second, ResponseHeader: time.Second, BodyIdle: time.Second,
+		}))
+		if results.SuccessRequests != 4 {
+			t.Errorf("%s: 成功 %d, 错误 %v", engine, results.SuccessRequests, results.ErrorsByType)
+		}
+	}
+
+	// 连接阶段: 拿到连接后其他拨号的事件不再计时
+	ctx, _, done := withPhaseTimeouts(context.Background(), config.TimeoutConfig{Dial: 20 * time.Millisecond})
+	defer done()
+	trace := httptrace.ContextClientTrace(ctx)
+	trace.ConnectStart("tcp", "backend:80")
+	<-ctx.Done()
+	if got := requestErrorType(ctx, ctx.Err(), "network"); got != "timeout_dial" {
+		t.Errorf("连接超时分类 %s", got)
+	}
+
+	ctx, _, done = withPhaseTimeouts(context.Background(), config.TimeoutConfig{Dial: 20 * time.Millisecond})
+	defer done()
+	trace = httptrace.ContextClientTrace(ctx)
+	trace.GotConn(httptrace.GotConnInfo{})
+	trace.ConnectStart("tcp", "backend:80")
+	select {
+	case <-ctx.Done():
+		t.Errorf("拿到连接后不应再计时连接阶段")
+	case <-time.After(60 * time.Millisecond):
+	}
+}
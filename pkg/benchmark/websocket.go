@@ -0,0 +1,546 @@
+package benchmark
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/HdrHistogram/hdrhistogram-go"
+	"golang.org/x/net/websocket"
+
+	"httpbench/pkg/config"
+	"httpbench/pkg/template"
+)
+
+func init() {
+	RegisterProtocol("websocket", newWebSocketExecutor)
+}
+
+// websocketVars 每条消息传入模板的变量
+var websocketVars = []string{"worker_id", "timestamp", "message_id"}
+
+// errDisconnected 等待回复期间连接断开
+var errDisconnected = errors.New("连接已断开")
+
+// wsExecutor WebSocket 执行器
+//
+// 维护固定数量的连接, 工作协程按编号分配到已建立的连接上; 每个连接由一个读协程
+// 接收消息, 按关联字段分发给等待中的请求。
+type wsExecutor struct {
+	cfg       config.WebSocketConfig
+	location  *url.URL
+	origin    *url.URL
+	header    http.Header
+	tlsConfig *tls.Config
+	dialer    *targetDialer
+	message   *template.Template
+	vars      sync.Pool
+	timeout   time.Duration
+
+	// 连接槽位, 前 opened 个可用
+	slots  []*wsSlot
+	opened atomic.Int64
+
+	nextID atomic.Int64
+	start  time.Time
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	// 连接统计
+	dials         atomic.Int64
+	connectErrors atomic.Int64
+	disconnects   atomic.Int64
+	sent          atomic.Int64
+	received      atomic.Int64
+	unmatched     atomic.Int64
+
+	histMu  sync.Mutex
+	connect *hdrhistogram.Histogram
+	upgrade *hdrhistogram.Histogram
+}
+
+// wsSlot 连接槽位, 连接断开后在下次使用时重连
+type wsSlot struct {
+	mu   sync.Mutex
+	conn *wsConn
+
+	// 无关联字段时同一连接同时只有一条消息
+	turn sync.Mutex
+}
+
+// wsConn 一个 WebSocket 连接
+type wsConn struct {
+	ws   *websocket.Conn
+	dead chan struct{}
+
+	mu      sync.Mutex
+	pending map[string]chan []byte
+	next    chan []byte
+}
+
+// newWebSocketExecutor 创建 WebSocket 执行器
+func newWebSocketExecutor(cfg *config.Config) (Executor, error) {
+	location, err := url.Parse(cfg.Target.URL)
+	if err != nil {
+		return nil, fmt.Errorf("无效的WebSocket地址: %w", err)
+	}
+	if location.Scheme != "ws" && location.Scheme != "wss" {
+		return nil, fmt.Errorf("WebSocket地址须以 ws:// 或 wss:// 开头: %s", cfg.Target.URL)
+	}
+
+	wsCfg := cfg.Protocol.WebSocket
+	originURL := wsCfg.Origin
+	if originURL == "" {
+		originURL = "http://" + location.Host
+	}
+	origin, err := url.Parse(originURL)
+	if err != nil {
+		return nil, fmt.Errorf("无效的Origin: %w", err)
+	}
+
+	tlsConfig, err := createTLSConfig(cfg.TLS)
+	if err != nil {
+		return nil, err
+	}
+	dialer, err := newTargetDialer(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// 消息总是作为模板渲染, 以便生成 message_id
+	templateCfg := cfg.Request.Template
+	templateCfg.Enabled = true
+	engine := template.New(templateCfg)
+	message, err := engine.Compile(wsCfg.Message, websocketVars...)
+	if err != nil {
+		return nil, fmt.Errorf("解析消息模板失败: %w", err)
+	}
+
+	connections := wsCfg.Connections
+	if connections <= 0 {
+		connections = max(cfg.Load.Concurrency, 1)
+	}
+	timeout := wsCfg.ResponseTimeout
+	if timeout <= 0 {
+		timeout = cfg.Target.Timeout
+	}
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+
+	header := make(http.Header)
+	for key, value := range cfg.Target.Headers {
+		header.Set(key, value)
+	}
+	for key, value := range cfg.Request.Headers {
+		header.Set(key, value)
+	}
+
+	e := &wsExecutor{
+		cfg:       wsCfg,
+		location:  location,
+		origin:    origin,
+		header:    header,
+		tlsConfig: tlsConfig,
+		dialer:    dialer,
+		message:   message,
+		timeout:   timeout,
+		slots:     make([]*wsSlot, connections),
+		connect:   newDurationHistogram(),
+		upgrade:   newDurationHistogram(),
+	}
+	e.vars.New = func() interface{} { return engine.Vars() }
+	for i := range e.slots {
+		e.slots[i] = &wsSlot{}
+	}
+	return e, nil
+}
+
+// newDurationHistogram 1微秒到1小时的直方图
+func newDurationHistogram() *hdrhistogram.Histogram {
+	return hdrhistogram.New(1, int64(time.Hour/time.Microsecond), 3)
+}
+
+// recordDuration 以微秒记录耗时, 超出范围的值记为边界值
+func recordDuration(hist *hdrhistogram.Histogram, d time.Duration) {
+	hist.RecordValue(min(max(d.Microseconds(), 1), hist.HighestTrackableValue()))
+}
+
+// addDurationStats 写入直方图的平均值、P99 和最大值 (毫秒)
+func addDurationStats(stats map[string]float64, name string, hist *hdrhistogram.Histogram) {
+	if hist.TotalCount() == 0 {
+		return
+	}
+	stats[name+"_ms_mean"] = hist.Mean() / 1000
+	stats[name+"_ms_p99"] = float64(hist.ValueAtQuantile(99)) / 1000
+	stats[name+"_ms_max"] = float64(hist.Max()) / 1000
+}
+
+// Prepare 建立连接; 配置了 ramp_up 时第一个连接建立后返回, 其余在后台按时间均匀建立
+func (e *wsExecutor) Prepare(ctx context.Context) error {
+	e.start = time.Now()
+	e.ctx, e.cancel = context.WithCancel(context.Background())
+
+	// 第一个连接失败时直接报错, 避免整个测试都在重连
+	if _, err := e.slots[0].get(ctx, e); err != nil {
+		return fmt.Errorf("WebSocket连接失败: %w", err)
+	}
+	e.opened.Store(1)
+
+	if e.cfg.RampUp <= 0 {
+		// 有限并行地建立其余连接
+		sem := make(chan struct{}, 64)
+		var wg sync.WaitGroup
+		for i := 1; i < len(e.slots); i++ {
+			sem <- struct{}{}
+			wg.Add(1)
+			go func(slot *wsSlot) {
+				defer wg.Done()
+				defer func() { <-sem }()
+				slot.get(ctx, e)
+			}(e.slots[i])
+		}
+		wg.Wait()
+		e.opened.Store(int64(len(e.slots)))
+		return ctx.Err()
+	}
+
+	// 逐个建立连接
+	interval := e.cfg.RampUp / time.Duration(len(e.slots))
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for i := 1; i < len(e.slots); i++ {
+			select {
+			case <-e.ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			e.slots[i].get(e.ctx, e)
+			e.opened.Store(int64(i + 1))
+		}
+	}()
+	return nil
+}
+
+// Execute 发送一条消息并等待回复
+func (e *wsExecutor) Execute(ctx context.Context, workerID int) (Result, error) {
+	// 渲染消息
+	id := strconv.FormatInt(e.nextID.Add(1), 10)
+	vars := e.vars.Get().(map[string]interface{})
+	vars["worker_id"] = workerID
+	vars["timestamp"] = time.Now().Unix()
+	vars["message_id"] = id
+	buf := getBuffer()
+	defer putBuffer(buf)
+	err := e.message.Execute(buf, vars)
+	e.vars.Put(vars)
+	if err != nil {
+		return Result{}, fmt.Errorf("渲染消息模板失败: %w", err)
+	}
+
+	result := Result{Start: time.Now()}
+	slot := e.slots[uint(workerID)%uint(max(e.opened.Load(), 1))]
+	conn, err := slot.get(ctx, e)
+	if err != nil {
+		result.Latency = time.Since(result.Start)
+		result.Fail(networkErrorType(err, "connect"), err)
+		return result, nil
+	}
+
+	// 登记等待的回复
+	var reply chan []byte
+	if e.cfg.CorrelationField != "" {
+		reply = conn.await(id)
+		defer conn.forget(id)
+	} else {
+		slot.turn.Lock()
+		defer slot.turn.Unlock()
+		reply = conn.next
+		// 丢弃此前未被认领的消息
+		select {
+		case <-reply:
+		default:
+		}
+	}
+
+	result.Start = time.Now()
+	if e.cfg.Binary {
+		err = websocket.Message.Send(conn.ws, buf.Bytes())
+	} else {
+		err = websocket.Message.Send(conn.ws, buf.String())
+	}
+	if err != nil {
+		result.Latency = time.Since(result.Start)
+		result.Fail("send", err)
+		if conn.close(err) {
+			e.disconnects.Add(1)
+		}
+		return result, nil
+	}
+	e.sent.Add(1)
+	result.BytesSent = int64(buf.Len())
+
+	timer := time.NewTimer(e.timeout)
+	defer timer.Stop()
+	select {
+	case data := <-reply:
+		result.Latency = time.Since(result.Start)
+		result.BytesReceived = int64(len(data))
+		result.Success = true
+	case <-conn.dead:
+		result.Latency = time.Since(result.Start)
+		result.Fail("disconnect", errDisconnected)
+	case <-timer.C:
+		result.Latency = time.Since(result.Start)
+		result.Fail("timeout", fmt.Errorf("%v 内未收到回复", e.timeout))
+	case <-ctx.Done():
+		result.Latency = time.Since(result.Start)
+		result.Fail("canceled", ctx.Err())
+	}
+	return result, nil
+}
+
+// Stats 连接和消息统计
+func (e *wsExecutor) Stats() map[string]float64 {
+	stats := map[string]float64{
+		"connections":        float64(len(e.slots)),
+		"connections_opened": float64(e.dials.Load()),
+		"connect_errors":     float64(e.connectErrors.Load()),
+		"disconnects":        float64(e.disconnects.Load()),
+		"messages_sent":      float64(e.sent.Load()),
+		"messages_received":  float64(e.received.Load()),
+		"unmatched_messages": float64(e.unmatched.Load()),
+	}
+	if elapsed := time.Since(e.start).Seconds(); !e.start.IsZero() && elapsed > 0 {
+		stats["messages_sent_per_sec"] = float64(e.sent.Load()) / elapsed
+		stats["messages_received_per_sec"] = float64(e.received.Load()) / elapsed
+	}
+	e.dialer.addStats(stats)
+
+	e.histMu.Lock()
+	defer e.histMu.Unlock()
+	addDurationStats(stats, "connect", e.connect)
+	addDurationStats(stats, "upgrade", e.upgrade)
+	return stats
+}
+
+// Close 关闭所有连接
+func (e *wsExecutor) Close() error {
+	if e.cancel != nil {
+		e.cancel()
+	}
+	e.dialer.Close()
+	e.wg.Wait()
+
+	for _, slot := range e.slots {
+		slot.mu.Lock()
+		if slot.conn != nil {
+			slot.conn.close(nil)
+			slot.conn = nil
+		}
+		slot.mu.Unlock()
+	}
+	return nil
+}
+
+// get 获取槽位上的连接, 未建立或已断开时重新连接
+func (s *wsSlot) get(ctx context.Context, e *wsExecutor) (*wsConn, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn != nil {
+		select {
+		case <-s.conn.dead:
+		default:
+			return s.conn, nil
+		}
+	}
+
+	conn, err := e.dial(ctx)
+	if err != nil {
+		e.connectErrors.Add(1)
+		return nil, err
+	}
+	s.conn = conn
+	return conn, nil
+}
+
+// dial 建立连接并完成升级握手, 分别记录建连和升级耗时
+func (e *wsExecutor) dial(ctx context.Context) (*wsConn, error) {
+	start := time.Now()
+
+	host := e.location.Host
+	if e.location.Port() == "" {
+		if e.location.Scheme == "wss" {
+			host = net.JoinHostPort(e.location.Hostname(), "443")
+		} else {
+			host = net.JoinHostPort(e.location.Hostname(), "80")
+		}
+	}
+
+	dialCtx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+	nc, err := e.dialer.DialContext(dialCtx, "tcp", host)
+	if err != nil {
+		return nil, err
+	}
+	if e.location.Scheme == "wss" {
+		cfg := &tls.Config{}
+		if e.tlsConfig != nil {
+			cfg = e.tlsConfig.Clone()
+		}
+		if cfg.ServerName == "" {
+			cfg.ServerName = e.location.Hostname()
+		}
+		tc := tls.Client(nc, cfg)
+		if err := tc.HandshakeContext(dialCtx); err != nil {
+			nc.Close()
+			return nil, err
+		}
+		nc = tc
+	}
+	connected := time.Now()
+
+	wsConfig := &websocket.Config{
+		Location: e.location,
+		Origin:   e.origin,
+		Version:  websocket.ProtocolVersionHybi13,
+		Header:   e.header.Clone(),
+	}
+	if e.cfg.Subprotocol != "" {
+		wsConfig.Protocol = []string{e.cfg.Subprotocol}
+	}
+	nc.SetDeadline(time.Now().Add(e.timeout))
+	ws, err := websocket.NewClient(wsConfig, nc)
+	if err != nil {
+		nc.Close()
+		return nil, fmt.Errorf("升级握手失败: %w", err)
+	}
+	nc.SetDeadline(time.Time{})
+	upgraded := time.Now()
+
+	e.dials.Add(1)
+	e.histMu.Lock()
+	recordDuration(e.connect, connected.Sub(start))
+	recordDuration(e.upgrade, upgraded.Sub(connected))
+	e.histMu.Unlock()
+
+	conn := &wsConn{
+		ws:      ws,
+		dead:    make(chan struct{}),
+		pending: make(map[string]chan []byte),
+		next:    make(chan []byte, 1),
+	}
+	go e.readLoop(conn)
+	return conn, nil
+}
+
+// readLoop 接收消息并分发给等待的请求
+func (e *wsExecutor) readLoop(conn *wsConn) {
+	for {
+		var data []byte
+		if err := websocket.Message.Receive(conn.ws, &data); err != nil {
+			if conn.close(err) {
+				e.disconnects.Add(1)
+			}
+			return
+		}
+		e.received.Add(1)
+
+		if e.cfg.CorrelationField == "" {
+			select {
+			case conn.next <- data:
+			default:
+				e.unmatched.Add(1)
+			}
+			continue
+		}
+
+		id, ok := correlationID(data, e.cfg.CorrelationField)
+		if !ok || !conn.deliver(id, data) {
+			e.unmatched.Add(1)
+		}
+	}
+}
+
+// await 登记等待的消息ID
+func (c *wsConn) await(id string) chan []byte {
+	ch := make(chan []byte, 1)
+	c.mu.Lock()
+	c.pending[id] = ch
+	c.mu.Unlock()
+	return ch
+}
+
+// forget 取消等待
+func (c *wsConn) forget(id string) {
+	c.mu.Lock()
+	delete(c.pending, id)
+	c.mu.Unlock()
+}
+
+// deliver 将回复交给等待的请求
+func (c *wsConn) deliver(id string, data []byte) bool {
+	c.mu.Lock()
+	ch, ok := c.pending[id]
+	delete(c.pending, id)
+	c.mu.Unlock()
+	if ok {
+		ch <- data
+	}
+	return ok
+}
+
+// close 关闭连接, 首次关闭时返回 true; err 为 nil 表示主动关闭
+func (c *wsConn) close(err error) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	select {
+	case <-c.dead:
+		return false
+	default:
+	}
+	close(c.dead)
+	c.ws.Close()
+	return err != nil
+}
+
+// correlationID 从JSON回复中取出关联字段, 字符串和数字均按文本比较
+func correlationID(data []byte, field string) (string, bool) {
+	var value json.RawMessage = data
+	for _, key := range strings.Split(field, ".") {
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal(value, &obj); err != nil {
+			return "", false
+		}
+		if value = obj[key]; value == nil {
+			return "", false
+		}
+	}
+
+	value = bytes.TrimSpace(value)
+	if len(value) > 0 && value[0] == '"' {
+		var s string
+		if err := json.Unmarshal(value, &s); err != nil {
+			return "", false
+		}
+		return s, true
+	}
+	return string(value), true
+}
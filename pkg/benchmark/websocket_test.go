@@ -0,0 +1,104 @@
+package benchmark
+
+import (
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/net/websocket"
+
+	"httpbench/pkg/config"
+)
+
+// TestWebSocket 测试 WebSocket 模式的消息关联、断线和连接爬坡
+func TestWebSocket(t *testing.T) {
+	var connections, closeAfter atomic.Int64
+	server := httptest.NewServer(websocket.Handler(func(ws *websocket.Conn) {
+		connections.Add(1)
+		var count int64
+		for {
+			var msg string
+			if err := websocket.Message.Receive(ws, &msg); err != nil {
+				return
+			}
+			count++
+			if limit := closeAfter.Load(); limit > 0 && count > limit {
+				return
+			}
+			// 乱序回复, 并夹杂一条无关消息
+			go func(msg string) {
+				if strings.Contains(msg, `"id":"`) {
+					websocket.Message.Send(ws, `{"type":"noise"}`)
+				}
+				websocket.Message.Send(ws, strings.Replace(msg, "ping", "pong", 1))
+			}(msg)
+		}
+	}))
+	defer server.Close()
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http")
+
+	newConfig := func(wsCfg config.WebSocketConfig, load config.LoadConfig) *config.Config {
+		return &config.Config{
+			Target:   config.TargetConfig{URL: wsURL, Timeout: 2 * time.Second},
+			Load:     load,
+			Protocol: config.ProtocolConfig{Type: "websocket", WebSocket: wsCfg},
+		}
+	}
+
+	// 多个工作协程共享连接, 按关联字段匹配回复
+	results := runBenchmark(t, newConfig(config.WebSocketConfig{
+		Connections:      2,
+		Message:          `{"id":"{{.message_id}}","worker":{{worker_id}},"op":"ping"}`,
+		CorrelationField: "id",
+	}, config.LoadConfig{Concurrency: 8, TotalRequests: 400}))
+	if results.SuccessRequests != 400 || results.TotalRequests != 400 {
+		t.Errorf("关联模式: 成功 %d/%d, 错误 %v", results.SuccessRequests, results.TotalRequests, results.ErrorsByType)
+	}
+	ps := results.ProtocolStats
+	if ps["connections_opened"] != 2 || ps["messages_sent"] != 400 || ps["unmatched_messages"] != 400 {
+		t.Errorf("关联模式协议统计: %v", ps)
+	}
+	if _, ok := ps["upgrade_ms_mean"]; !ok {
+		t.Errorf("缺少升级耗时: %v", ps)
+	}
+
+	// 无关联字段: 每个连接一问一答
+	results = runBenchmark(t, newConfig(config.WebSocketConfig{
+		Message: "ping {{.message_id}}",
+	}, config.LoadConfig{Concurrency: 3, TotalRequests: 150}))
+	if results.SuccessRequests != 150 || results.ProtocolStats["connections_opened"] != 3 {
+		t.Errorf("一问一答模式: 成功 %d, 协议统计 %v", results.SuccessRequests, results.ProtocolStats)
+	}
+
+	// 服务端每收到10条消息后断开, 断开的连接在下次使用时重连 (最后一次断开后不再重连)
+	closeAfter.Store(10)
+	results = runBenchmark(t, newConfig(config.WebSocketConfig{
+		Connections:      1,
+		Message:          `{"id":{{.message_id}}}`,
+		CorrelationField: "id",
+	}, config.LoadConfig{Concurrency: 1, TotalRequests: 55}))
+	closeAfter.Store(0)
+	if results.SuccessRequests != 50 || results.ErrorsByType["disconnect"] != 5 {
+		t.Errorf("断线: 成功 %d, 错误 %v", results.SuccessRequests, results.ErrorsByType)
+	}
+	if ps := results.ProtocolStats; ps["disconnects"] != 5 || ps["connections_opened"] != 5 {
+		t.Errorf("断线协议统计: %v", ps)
+	}
+
+	// 连接爬坡: 300ms 内逐个建立4个连接
+	before := connections.Load()
+	results = runBenchmark(t, newConfig(config.WebSocketConfig{
+		Connections:      4,
+		RampUp:           300 * time.Millisecond,
+		Message:          `{"id":"{{.message_id}}"}`,
+		CorrelationField: "id",
+	}, config.LoadConfig{Concurrency: 4, Duration: 500 * time.Millisecond, RateLimit: 100}))
+	if opened := connections.Load() - before; opened != 4 || results.ProtocolStats["connections_opened"] != 4 {
+		t.Errorf("爬坡建立连接 %d, 协议统计 %v", opened, results.ProtocolStats)
+	}
+	if results.FailedRequests != 0 {
+		t.Errorf("爬坡存在失败请求: %v", results.ErrorsByType)
+	}
+}
@@ -0,0 +1,125 @@
+package control
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"httpbench/pkg/benchmark"
+)
+
+// Client 控制API客户端
+type Client struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewClient 创建客户端, addr 可以是 host:port 或完整URL
+func NewClient(addr string) *Client {
+	baseURL := addr
+	if !strings.Contains(addr, "://") {
+		baseURL = "http://" + addr
+	}
+
+	return &Client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Status 获取运行状态
+func (c *Client) Status() (*Status, error) {
+	var status Status
+	if err := c.do(http.MethodGet, "/v1/status", nil, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// Snapshot 获取当前测试结果快照
+func (c *Client) Snapshot() (*benchmark.Results, error) {
+	var results benchmark.Results
+	if err := c.do(http.MethodGet, "/v1/snapshot", nil, &results); err != nil {
+		return nil, err
+	}
+	return &results, nil
+}
+
+// Pause 暂停
+func (c *Client) Pause() (*Status, error) {
+	return c.post("/v1/pause", struct{}{})
+}
+
+// Resume 恢复
+func (c *Client) Resume() (*Status, error) {
+	return c.post("/v1/resume", struct{}{})
+}
+
+// Stop 优雅停止
+func (c *Client) Stop() (*Status, error) {
+	return c.post("/v1/stop", struct{}{})
+}
+
+// NextStage 进入下一阶段
+func (c *Client) NextStage() (*Status, error) {
+	return c.post("/v1/next-stage", struct{}{})
+}
+
+// SetConcurrency 调整并发数
+func (c *Client) SetConcurrency(n int) (*Status, error) {
+	return c.post("/v1/concurrency", concurrencyRequest{Concurrency: n})
+}
+
+// SetRate 调整目标速率, 0 表示不限速
+func (c *Client) SetRate(rps int) (*Status, error) {
+	return c.post("/v1/rate", rateRequest{Rate: &rps})
+}
+
+// post 发送控制请求
+func (c *Client) post(path string, body interface{}) (*Status, error) {
+	var status Status
+	if err := c.do(http.MethodPost, path, body, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// do 发送请求并解析JSON响应
+func (c *Client) do(method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, c.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("连接控制服务失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var errResp errorResponse
+		if json.NewDecoder(resp.Body).Decode(&errResp) == nil && errResp.Error != "" {
+			return fmt.Errorf("%s", errResp.Error)
+		}
+		return fmt.Errorf("控制服务返回 %s", resp.Status)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
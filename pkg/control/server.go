@@ -0,0 +1,259 @@
+package control
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"time"
+
+	"httpbench/pkg/benchmark"
+)
+
+// DefaultAddr httpbench ctl 默认连接的控制地址
+const DefaultAddr = "127.0.0.1:9091"
+
+// Status 运行状态
+type Status struct {
+	Endpoint        string  `json:"endpoint"`
+	Stage           string  `json:"stage"`
+	Paused          bool    `json:"paused"`
+	Done            bool    `json:"done"`
+	Elapsed         float64 `json:"elapsed_s"`
+	Planned         float64 `json:"planned_s"`
+	Concurrency     int     `json:"concurrency"`
+	ActiveWorkers   int64   `json:"active_workers"`
+	InFlight        int64   `json:"in_flight"`
+	TargetRate      float64 `json:"target_rate"`
+	TotalRequests   int64   `json:"total_requests"`
+	SuccessRequests int64   `json:"success_requests"`
+	FailedRequests  int64   `json:"failed_requests"`
+}
+
+// concurrencyRequest 调整并发请求
+type concurrencyRequest struct {
+	Concurrency int `json:"concurrency"`
+}
+
+// rateRequest 调整速率请求
+type rateRequest struct {
+	Rate *int `json:"rate"`
+}
+
+// errorResponse 错误响应
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// Server 运行控制API服务
+type Server struct {
+	addr     string
+	server   *http.Server
+	listener net.Listener
+}
+
+// NewServer 创建控制服务
+func NewServer(addr string, bench *benchmark.Benchmark) *Server {
+	return &Server{
+		addr: addr,
+		server: &http.Server{
+			Handler:           NewHandler(bench),
+			ReadHeaderTimeout: 5 * time.Second,
+		},
+	}
+}
+
+// NewHandler 运行控制API的路由, 浏览器仪表盘挂载同一组接口
+//
+//	GET  /v1/status       运行状态
+//	GET  /v1/snapshot     当前测试结果快照
+//	POST /v1/pause        暂停生成新请求
+//	POST /v1/resume       恢复
+//	POST /v1/stop         优雅停止
+//	POST /v1/next-stage   进入下一阶段
+//	POST /v1/concurrency  {"concurrency": 50}
+//	POST /v1/rate         {"rate": 200} (0 表示不限速)
+//
+// POST请求须使用JSON请求体,浏览器跨站表单无法直接触发。
+func NewHandler(bench *benchmark.Benchmark) http.Handler {
+	h := &handler{bench: bench}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/status", h.handleStatus)
+	mux.HandleFunc("/v1/snapshot", h.handleSnapshot)
+	mux.HandleFunc("/v1/pause", h.action(func() error { bench.Pause(); return nil }))
+	mux.HandleFunc("/v1/resume", h.action(func() error { bench.Resume(); return nil }))
+	mux.HandleFunc("/v1/stop", h.action(func() error { bench.Stop(); return nil }))
+	mux.HandleFunc("/v1/next-stage", h.action(bench.NextStage))
+	mux.HandleFunc("/v1/concurrency", h.handleConcurrency)
+	mux.HandleFunc("/v1/rate", h.handleRate)
+	return mux
+}
+
+// handler 控制API的处理函数
+type handler struct {
+	bench *benchmark.Benchmark
+}
+
+// Start 启动控制服务(非阻塞)
+func (s *Server) Start() error {
+	lis, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return fmt.Errorf("监听控制地址失败: %w", err)
+	}
+	s.listener = lis
+
+	go func() {
+		if err := s.server.Serve(lis); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fmt.Printf("⚠️  控制服务异常退出: %v\n", err)
+		}
+	}()
+
+	return nil
+}
+
+// Addr 获取实际监听地址
+func (s *Server) Addr() string {
+	if s.listener == nil {
+		return s.addr
+	}
+	return s.listener.Addr().String()
+}
+
+// Close 关闭控制服务
+func (s *Server) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return s.server.Shutdown(ctx)
+}
+
+// CurrentStatus 获取当前运行状态
+func CurrentStatus(bench *benchmark.Benchmark) Status {
+	snapshot := bench.Stats().Snapshot()
+	stage := bench.Stage()
+
+	return Status{
+		Endpoint:        bench.Endpoint(),
+		Stage:           stage,
+		Paused:          bench.Paused(),
+		Done:            stage == benchmark.StageDone,
+		Elapsed:         bench.Elapsed().Seconds(),
+		Planned:         bench.PlannedDuration().Seconds(),
+		Concurrency:     bench.Concurrency(),
+		ActiveWorkers:   bench.ActiveWorkers(),
+		InFlight:        bench.InFlight(),
+		TargetRate:      bench.TargetRate(),
+		TotalRequests:   snapshot.TotalRequests,
+		SuccessRequests: snapshot.SuccessRequests,
+		FailedRequests:  snapshot.TotalErrors,
+	}
+}
+
+// handleStatus 返回运行状态
+func (h *handler) handleStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, http.MethodGet)
+		return
+	}
+	writeJSON(w, http.StatusOK, CurrentStatus(h.bench))
+}
+
+// handleSnapshot 返回当前测试结果快照
+func (h *handler) handleSnapshot(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, http.MethodGet)
+		return
+	}
+	writeJSON(w, http.StatusOK, h.bench.Snapshot())
+}
+
+// action 无参数的控制操作
+func (h *handler) action(fn func() error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !checkPost(w, r) {
+			return
+		}
+		if err := fn(); err != nil {
+			writeJSON(w, http.StatusConflict, errorResponse{Error: err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, CurrentStatus(h.bench))
+	}
+}
+
+// handleConcurrency 调整并发数
+func (h *handler) handleConcurrency(w http.ResponseWriter, r *http.Request) {
+	if !checkPost(w, r) {
+		return
+	}
+
+	var req concurrencyRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if req.Concurrency < 1 {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "concurrency must be >= 1"})
+		return
+	}
+	if err := h.bench.SetConcurrency(req.Concurrency); err != nil {
+		writeJSON(w, http.StatusConflict, errorResponse{Error: err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, CurrentStatus(h.bench))
+}
+
+// handleRate 调整目标速率
+func (h *handler) handleRate(w http.ResponseWriter, r *http.Request) {
+	if !checkPost(w, r) {
+		return
+	}
+
+	var req rateRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if req.Rate == nil || *req.Rate < 0 {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "rate must be >= 0 (0 = unlimited)"})
+		return
+	}
+	h.bench.SetRate(*req.Rate)
+	writeJSON(w, http.StatusOK, CurrentStatus(h.bench))
+}
+
+// checkPost 校验请求方法和Content-Type
+func checkPost(w http.ResponseWriter, r *http.Request) bool {
+	if r.Method != http.MethodPost {
+		methodNotAllowed(w, http.MethodPost)
+		return false
+	}
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType != "application/json" {
+		writeJSON(w, http.StatusUnsupportedMediaType, errorResponse{Error: "content type must be application/json"})
+		return false
+	}
+	return true
+}
+
+// decodeJSON 解析JSON请求体
+func decodeJSON(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 4096)).Decode(v); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid request: " + err.Error()})
+		return false
+	}
+	return true
+}
+
+// methodNotAllowed 方法不允许
+func methodNotAllowed(w http.ResponseWriter, allow string) {
+	w.Header().Set("Allow", allow)
+	writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "method not allowed"})
+}
+
+// writeJSON 输出JSON响应
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,144 @@
+package control
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"httpbench/pkg/benchmark"
+	"httpbench/pkg/config"
+)
+
+// stubExecutor 测试用执行器, 返回固定的协议统计
+type stubExecutor struct {
+	stats map[string]float64
+}
+
+func (e *stubExecutor) Prepare(ctx context.Context) error { return nil }
+
+func (e *stubExecutor) Execute(ctx context.Context, workerID int) (benchmark.Result, error) {
+	return benchmark.Result{Start: time.Now(), StatusCode: 200, Success: true}, nil
+}
+
+func (e *stubExecutor) Stats() map[string]float64 { return e.stats }
+
+func (e *stubExecutor) Close() error { return nil }
+
+// newTestHandler 创建未运行的基准测试器及其控制API
+func newTestHandler(t *testing.T, exec benchmark.Executor) (*benchmark.Benchmark, http.Handler) {
+	t.Helper()
+	bench, err := benchmark.NewWithExecutor(&config.Config{
+		Target: config.TargetConfig{URL: "stub://target"},
+		Load:   config.LoadConfig{Concurrency: 2, Duration: time.Minute},
+	}, exec)
+	if err != nil {
+		t.Fatalf("创建基准测试器失败: %v", err)
+	}
+	bench.SetLogOutput(io.Discard)
+	t.Cleanup(func() { bench.Close() })
+	return bench, NewHandler(bench)
+}
+
+// serve 发送请求, 返回响应记录
+func serve(handler http.Handler, method, path, contentType, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+// TestHandler 测试各控制操作的状态码和返回的运行状态
+func TestHandler(t *testing.T) {
+	bench, handler := newTestHandler(t, &stubExecutor{})
+
+	for _, tc := range []struct {
+		name        string
+		method      string
+		path        string
+		contentType string
+		body        string
+		code        int
+		check       func(Status) bool
+	}{
+		{"状态", http.MethodGet, "/v1/status", "", "", http.StatusOK,
+			func(s Status) bool { return s.Stage == benchmark.StageIdle && s.Planned == 60 }},
+		{"状态不接受POST", http.MethodPost, "/v1/status", "application/json", "{}", http.StatusMethodNotAllowed, nil},
+		{"暂停不接受GET", http.MethodGet, "/v1/pause", "", "", http.StatusMethodNotAllowed, nil},
+		{"缺少Content-Type", http.MethodPost, "/v1/pause", "", "{}", http.StatusUnsupportedMediaType, nil},
+		{"表单Content-Type", http.MethodPost, "/v1/pause", "application/x-www-form-urlencoded", "a=1", http.StatusUnsupportedMediaType, nil},
+		{"暂停", http.MethodPost, "/v1/pause", "application/json; charset=utf-8", "{}", http.StatusOK,
+			func(s Status) bool { return s.Paused }},
+		{"恢复", http.MethodPost, "/v1/resume", "application/json", "{}", http.StatusOK,
+			func(s Status) bool { return !s.Paused }},
+		{"调整速率", http.MethodPost, "/v1/rate", "application/json", `{"rate": 200}`, http.StatusOK,
+			func(s Status) bool { return s.TargetRate == 200 }},
+		{"不限速", http.MethodPost, "/v1/rate", "application/json", `{"rate": 0}`, http.StatusOK,
+			func(s Status) bool { return s.TargetRate == 0 }},
+		{"缺少速率", http.MethodPost, "/v1/rate", "application/json", "{}", http.StatusBadRequest, nil},
+		{"负速率", http.MethodPost, "/v1/rate", "application/json", `{"rate": -1}`, http.StatusBadRequest, nil},
+		{"无效JSON", http.MethodPost, "/v1/rate", "application/json", "{", http.StatusBadRequest, nil},
+		{"无效并发", http.MethodPost, "/v1/concurrency", "application/json", `{"concurrency": 0}`, http.StatusBadRequest, nil},
+		{"未运行时调整并发", http.MethodPost, "/v1/concurrency", "application/json", `{"concurrency": 4}`, http.StatusConflict, nil},
+		{"没有后续阶段", http.MethodPost, "/v1/next-stage", "application/json", "{}", http.StatusConflict, nil},
+		{"停止", http.MethodPost, "/v1/stop", "application/json", "{}", http.StatusOK,
+			func(s Status) bool { return !s.Paused }},
+	} {
+		rec := serve(handler, tc.method, tc.path, tc.contentType, tc.body)
+		if rec.Code != tc.code {
+			t.Errorf("%s: 状态码 %d, 期望 %d: %s", tc.name, rec.Code, tc.code, rec.Body)
+			continue
+		}
+		if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+			t.Errorf("%s: Content-Type %q", tc.name, ct)
+		}
+		if rec.Code == http.StatusMethodNotAllowed && rec.Header().Get("Allow") == "" {
+			t.Errorf("%s: 缺少 Allow 头", tc.name)
+		}
+		if tc.check == nil {
+			continue
+		}
+		var status Status
+		if err := json.Unmarshal(rec.Body.Bytes(), &status); err != nil {
+			t.Errorf("%s: 无效的响应 %q: %v", tc.name, rec.Body, err)
+			continue
+		}
+		if !tc.check(status) {
+			t.Errorf("%s: 运行状态 %+v", tc.name, status)
+		}
+	}
+
+	if got := CurrentStatus(bench); got.Endpoint != bench.Endpoint() || got.Done {
+		t.Errorf("CurrentStatus %+v", got)
+	}
+}
+
+// TestSnapshotNonFinite 测试协议统计含 NaN 和 ±Inf 时快照仍可编码为JSON
+func TestSnapshotNonFinite(t *testing.T) {
+	_, handler := newTestHandler(t, &stubExecutor{stats: map[string]float64{
+		"nan":     math.NaN(),
+		"inf":     math.Inf(1),
+		"neg_inf": math.Inf(-1),
+		"streams": 3,
+	}})
+
+	rec := serve(handler, http.MethodGet, "/v1/snapshot", "", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("状态码 %d", rec.Code)
+	}
+	var results benchmark.Results
+	if err := json.Unmarshal(rec.Body.Bytes(), &results); err != nil {
+		t.Fatalf("无效的快照 %q: %v", rec.Body, err)
+	}
+	if len(results.ProtocolStats) != 1 || results.ProtocolStats["streams"] != 3 {
+		t.Errorf("协议统计 %v, 期望只保留有限值", results.ProtocolStats)
+	}
+}
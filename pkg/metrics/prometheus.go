@@ -0,0 +1,207 @@
+package metrics
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"httpbench/pkg/stats"
+)
+
+// Source 指标数据源
+type Source interface {
+	Stats() *stats.Collector
+	InFlight() int64
+	ActiveWorkers() int64
+	TargetRate() float64
+}
+
+// latencyBuckets Prometheus延迟直方图分桶边界
+var latencyBuckets = []time.Duration{
+	1 * time.Millisecond,
+	2500 * time.Microsecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	1 * time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+// Server Prometheus指标服务
+type Server struct {
+	addr     string
+	source   Source
+	server   *http.Server
+	listener net.Listener
+}
+
+// NewServer 创建指标服务
+func NewServer(addr string, source Source) *Server {
+	s := &Server{
+		addr:   addr,
+		source: source,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", s.handleMetrics)
+	s.server = &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	return s
+}
+
+// Start 启动指标服务(非阻塞)
+func (s *Server) Start() error {
+	lis, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return fmt.Errorf("监听指标地址失败: %w", err)
+	}
+	s.listener = lis
+
+	go func() {
+		if err := s.server.Serve(lis); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fmt.Printf("⚠️  指标服务异常退出: %v\n", err)
+		}
+	}()
+
+	return nil
+}
+
+// Addr 获取实际监听地址
+func (s *Server) Addr() string {
+	if s.listener == nil {
+		return s.addr
+	}
+	return s.listener.Addr().String()
+}
+
+// Close 关闭指标服务
+func (s *Server) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return s.server.Shutdown(ctx)
+}
+
+// handleMetrics 输出Prometheus文本格式指标
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+	bw := bufio.NewWriter(w)
+	defer bw.Flush()
+
+	WriteMetrics(bw, s.source)
+}
+
+// WriteMetrics 以Prometheus文本格式写出指标
+func WriteMetrics(w *bufio.Writer, source Source) {
+	collector := source.Stats()
+	snapshot := collector.Snapshot()
+
+	// 请求计数 (端点 × 状态码)
+	writeHeader(w, "httpbench_requests_total", "counter", "Total requests by endpoint and status code.")
+	for _, endpoint := range sortedKeys(snapshot.Endpoints) {
+		es := snapshot.Endpoints[endpoint]
+		codes := make([]int, 0, len(es.StatusCodes))
+		for code := range es.StatusCodes {
+			codes = append(codes, code)
+		}
+		sort.Ints(codes)
+		for _, code := range codes {
+			fmt.Fprintf(w, "httpbench_requests_total{endpoint=%s,status=%s} %d\n",
+				quote(endpoint), quote(statusLabel(code)), es.StatusCodes[code])
+		}
+	}
+
+	// 成功/失败计数
+	writeHeader(w, "httpbench_request_results_total", "counter", "Total requests by endpoint and result.")
+	for _, endpoint := range sortedKeys(snapshot.Endpoints) {
+		es := snapshot.Endpoints[endpoint]
+		fmt.Fprintf(w, "httpbench_request_results_total{endpoint=%s,result=\"success\"} %d\n", quote(endpoint), es.SuccessRequests)
+		fmt.Fprintf(w, "httpbench_request_results_total{endpoint=%s,result=\"failure\"} %d\n", quote(endpoint), es.FailedRequests)
+	}
+
+	// 错误分类
+	writeHeader(w, "httpbench_errors_total", "counter", "Total errors by type.")
+	for _, errType := range sortedKeys(snapshot.ErrorsByType) {
+		fmt.Fprintf(w, "httpbench_errors_total{type=%s} %d\n", quote(errType), snapshot.ErrorsByType[errType])
+	}
+
+	// 延迟直方图
+	writeHeader(w, "httpbench_request_duration_seconds", "histogram", "Request latency by endpoint.")
+	buckets := collector.EndpointBuckets(latencyBuckets)
+	for _, endpoint := range sortedKeys(buckets) {
+		hb := buckets[endpoint]
+		label := quote(endpoint)
+		for i, bound := range hb.Bounds {
+			fmt.Fprintf(w, "httpbench_request_duration_seconds_bucket{endpoint=%s,le=\"%s\"} %d\n",
+				label, formatFloat(bound.Seconds()), hb.Counts[i])
+		}
+		fmt.Fprintf(w, "httpbench_request_duration_seconds_bucket{endpoint=%s,le=\"+Inf\"} %d\n", label, hb.Count)
+		fmt.Fprintf(w, "httpbench_request_duration_seconds_sum{endpoint=%s} %s\n", label, formatFloat(hb.Sum.Seconds()))
+		fmt.Fprintf(w, "httpbench_request_duration_seconds_count{endpoint=%s} %d\n", label, hb.Count)
+	}
+
+	// 传输字节数
+	writeHeader(w, "httpbench_received_bytes_total", "counter", "Total response bytes received.")
+	fmt.Fprintf(w, "httpbench_received_bytes_total %d\n", snapshot.BytesReceived)
+	writeHeader(w, "httpbench_sent_bytes_total", "counter", "Total request bytes sent.")
+	fmt.Fprintf(w, "httpbench_sent_bytes_total %d\n", snapshot.BytesSent)
+
+	// 运行状态
+	writeHeader(w, "httpbench_in_flight_requests", "gauge", "Requests currently in flight.")
+	fmt.Fprintf(w, "httpbench_in_flight_requests %d\n", source.InFlight())
+	writeHeader(w, "httpbench_active_workers", "gauge", "Workers currently running.")
+	fmt.Fprintf(w, "httpbench_active_workers %d\n", source.ActiveWorkers())
+	writeHeader(w, "httpbench_target_rate", "gauge", "Target request rate in requests per second (0 means unlimited).")
+	fmt.Fprintf(w, "httpbench_target_rate %s\n", formatFloat(source.TargetRate()))
+}
+
+// writeHeader 写出指标的HELP和TYPE行
+func writeHeader(w *bufio.Writer, name, metricType, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(w, "# TYPE %s %s\n", name, metricType)
+}
+
+// statusLabel 状态码标签, 未收到响应时为 none
+func statusLabel(code int) string {
+	if code == 0 {
+		return "none"
+	}
+	return strconv.Itoa(code)
+}
+
+// quote 转义标签值
+func quote(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+	return `"` + replacer.Replace(value) + `"`
+}
+
+// formatFloat 格式化浮点数
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// sortedKeys 获取排序后的键
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,163 @@
+package metrics
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"httpbench/pkg/stats"
+)
+
+var update = flag.Bool("update", false, "更新 testdata 中的期望输出")
+
+// fakeSource 固定数据的指标源
+type fakeSource struct {
+	collector *stats.Collector
+}
+
+func (s fakeSource) Stats() *stats.Collector { return s.collector }
+func (s fakeSource) InFlight() int64         { return 3 }
+func (s fakeSource) ActiveWorkers() int64    { return 4 }
+func (s fakeSource) TargetRate() float64     { return 12.5 }
+
+func newFakeSource() fakeSource {
+	c := stats.NewCollector()
+	record := func(endpoint string, code int, latency time.Duration, success bool) {
+		c.RecordRequest(latency, 100, 10, success)
+		c.RecordStatusCode(code)
+		c.RecordEndpoint(endpoint, code, latency, success)
+	}
+	record("GET /users", 200, 3*time.Millisecond, true)
+	record("GET /users", 200, 40*time.Millisecond, true)
+	record("GET /users", 500, 2*time.Second, false)
+	c.RecordError("status_code", nil)
+	// 标签值中的引号、反斜杠和换行需要转义
+	record("POST /q?\"a\"\\b\n", 0, 20*time.Second, false)
+	c.RecordError("network", nil)
+	return fakeSource{collector: c}
+}
+
+func writeMetrics(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := bufio.NewWriter(&buf)
+	WriteMetrics(w, newFakeSource())
+	if err := w.Flush(); err != nil {
+		t.Fatalf("写出指标失败: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestWriteMetricsGolden(t *testing.T) {
+	got := writeMetrics(t)
+	golden := filepath.Join("testdata", "metrics.golden")
+	if *update {
+		if err := os.WriteFile(golden, got, 0o644); err != nil {
+			t.Fatalf("更新期望输出失败: %v", err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("读取期望输出失败: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("指标输出与 %s 不一致 (go test -update 更新):\n%s", golden, got)
+	}
+}
+
+// TestWriteMetricsFormat 按文本格式逐行检查: 每个样本前有 HELP 和 TYPE, 直方图分桶累计且与计数一致
+func TestWriteMetricsFormat(t *testing.T) {
+	types := make(map[string]string)
+	helps := make(map[string]bool)
+	buckets := make(map[string][]float64)
+
+	scanner := bufio.NewScanner(bytes.NewReader(writeMetrics(t)))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "# HELP ") {
+			helps[strings.Fields(line)[2]] = true
+			continue
+		}
+		if strings.HasPrefix(line, "# TYPE ") {
+			fields := strings.Fields(line)
+			types[fields[2]] = fields[3]
+			continue
+		}
+
+		name, labels, value := parseSample(t, line)
+		family := strings.TrimSuffix(strings.TrimSuffix(strings.TrimSuffix(name, "_bucket"), "_sum"), "_count")
+		if types[family] == "" || !helps[family] {
+			t.Errorf("样本 %s 之前没有 HELP/TYPE", name)
+		}
+		if types[family] == "counter" && !strings.HasSuffix(name, "_total") {
+			t.Errorf("计数器 %s 应以 _total 结尾", name)
+		}
+
+		switch {
+		case strings.HasSuffix(name, "_bucket"):
+			endpoint := labels[:strings.LastIndex(labels, ",le=")]
+			buckets[endpoint] = append(buckets[endpoint], value)
+		case strings.HasSuffix(name, "_count"):
+			// 最后一个分桶是 +Inf
+			b := buckets[labels]
+			if len(b) == 0 || b[len(b)-1] != value {
+				t.Errorf("%s: +Inf 分桶 %v 与计数 %v 不一致", labels, b, value)
+			}
+		}
+	}
+
+	if types["httpbench_request_duration_seconds"] != "histogram" {
+		t.Errorf("延迟应为直方图: %v", types)
+	}
+	if len(buckets) != 2 {
+		t.Errorf("应有 2 个端点的直方图: %v", buckets)
+	}
+	for endpoint, b := range buckets {
+		if len(b) != len(latencyBuckets)+1 {
+			t.Errorf("%s: 分桶数 %d", endpoint, len(b))
+		}
+		for i := 1; i < len(b); i++ {
+			if b[i] < b[i-1] {
+				t.Errorf("%s: 分桶不是累计的: %v", endpoint, b)
+			}
+		}
+	}
+	if want := []float64{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1}; !equalFloats(buckets[`endpoint="POST /q?\"a\"\\b\n"`], want) {
+		t.Errorf("超出最大边界的请求只应计入 +Inf: %v", buckets)
+	}
+}
+
+// parseSample 解析样本行, 返回指标名、花括号内的标签和数值
+func parseSample(t *testing.T, line string) (string, string, float64) {
+	t.Helper()
+	var name, labels, rest string
+	if i := strings.IndexByte(line, '{'); i >= 0 {
+		end := strings.LastIndexByte(line, '}')
+		name, labels, rest = line[:i], line[i+1:end], line[end+1:]
+	} else {
+		name, rest, _ = strings.Cut(line, " ")
+	}
+	value, err := strconv.ParseFloat(strings.TrimSpace(rest), 64)
+	if err != nil {
+		t.Fatalf("无效的样本行 %q: %v", line, err)
+	}
+	return name, labels, value
+}
+
+func equalFloats(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,202 @@
+package mockserver
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config 模拟目标服务配置
+type Config struct {
+	// HTTP/1.1 和 h2c (明文HTTP/2) 监听地址
+	Addr string `yaml:"addr"`
+	// HTTP/1.1 和 HTTP/2 over TLS 监听地址
+	TLSAddr string `yaml:"tls_addr"`
+	// HTTP/3 (QUIC, UDP) 监听地址
+	HTTP3Addr string `yaml:"http3_addr"`
+
+	// 证书文件, 为空时生成自签名证书
+	CertFile string `yaml:"cert_file"`
+	KeyFile  string `yaml:"key_file"`
+
+	// 路由按顺序匹配,第一个匹配的路由生效
+	Routes []Route `yaml:"routes"`
+}
+
+// Route 路由行为配置
+type Route struct {
+	// 路径: 精确匹配, 以 "/" 结尾时按前缀匹配
+	Path string `yaml:"path"`
+	// 请求方法, 为空匹配任意方法
+	Method string `yaml:"method"`
+
+	// 响应
+	Status  int               `yaml:"status"`
+	Headers map[string]string `yaml:"headers"`
+	Body    string            `yaml:"body"`
+	// 响应体大小(字节), 大于0时忽略 Body 并填充至该大小
+	Size int `yaml:"size"`
+
+	// 延迟
+	Latency Latency `yaml:"latency"`
+
+	// 按状态码注入错误
+	Errors []ErrorRate `yaml:"errors"`
+	// 直接断开连接的比例
+	DropRate float64 `yaml:"drop_rate"`
+
+	// 慢速流式响应体
+	Stream Stream `yaml:"stream"`
+
+	// 认证: issue 签发令牌 (JSON + Set-Cookie), require 校验令牌
+	Auth string `yaml:"auth"`
+}
+
+// Latency 延迟分布
+type Latency struct {
+	// 分布: fixed (默认), uniform, normal, exponential
+	Distribution string `yaml:"distribution"`
+	// fixed/normal/exponential 的均值
+	Mean time.Duration `yaml:"mean"`
+	// normal 的标准差
+	StdDev time.Duration `yaml:"stddev"`
+	// uniform 的取值范围, 其他分布的截断范围
+	Min time.Duration `yaml:"min"`
+	Max time.Duration `yaml:"max"`
+}
+
+// ErrorRate 以指定比例返回某状态码
+type ErrorRate struct {
+	Status int     `yaml:"status"`
+	Rate   float64 `yaml:"rate"`
+}
+
+// Stream 分块发送响应体
+type Stream struct {
+	ChunkSize int           `yaml:"chunk_size"`
+	Interval  time.Duration `yaml:"interval"`
+}
+
+// Auth 模式
+const (
+	AuthIssue   = "issue"
+	AuthRequire = "require"
+)
+
+// NewDefault 创建默认配置: 单个 "/" 路由返回 200 OK
+func NewDefault() *Config {
+	return &Config{
+		Addr: ":8080",
+		Routes: []Route{
+			{Path: "/", Status: 200, Body: "OK"},
+		},
+	}
+}
+
+// LoadFromFile 从YAML文件加载配置
+func LoadFromFile(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取配置文件失败: %w", err)
+	}
+
+	cfg := &Config{Addr: ":8080"}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("解析配置文件失败: %w", err)
+	}
+
+	return cfg, nil
+}
+
+// Validate 验证配置
+func (c *Config) Validate() error {
+	if c.Addr == "" && c.TLSAddr == "" && c.HTTP3Addr == "" {
+		return fmt.Errorf("至少需要一个监听地址")
+	}
+	if (c.CertFile == "") != (c.KeyFile == "") {
+		return fmt.Errorf("cert_file 和 key_file 必须同时指定")
+	}
+	if len(c.Routes) == 0 {
+		return fmt.Errorf("至少需要一个路由")
+	}
+
+	for i, route := range c.Routes {
+		if !strings.HasPrefix(route.Path, "/") {
+			return fmt.Errorf("路由 %d: 路径必须以 / 开头: %q", i, route.Path)
+		}
+		if route.Status != 0 && (route.Status < 100 || route.Status > 599) {
+			return fmt.Errorf("路由 %s: 无效的状态码 %d", route.Path, route.Status)
+		}
+
+		total := route.DropRate
+		for _, e := range route.Errors {
+			if e.Status < 100 || e.Status > 599 {
+				return fmt.Errorf("路由 %s: 无效的错误状态码 %d", route.Path, e.Status)
+			}
+			if e.Rate < 0 {
+				return fmt.Errorf("路由 %s: 错误比例不能为负数", route.Path)
+			}
+			total += e.Rate
+		}
+		if route.DropRate < 0 || total > 1 {
+			return fmt.Errorf("路由 %s: 错误比例与断开比例之和必须在 0 到 1 之间", route.Path)
+		}
+
+		switch route.Latency.Distribution {
+		case "", "fixed", "uniform", "normal", "exponential":
+		default:
+			return fmt.Errorf("路由 %s: 未知的延迟分布 %q", route.Path, route.Latency.Distribution)
+		}
+		if route.Latency.Max > 0 && route.Latency.Min > route.Latency.Max {
+			return fmt.Errorf("路由 %s: 延迟 min 不能大于 max", route.Path)
+		}
+
+		switch route.Auth {
+		case "", AuthIssue, AuthRequire:
+		default:
+			return fmt.Errorf("路由 %s: 未知的认证模式 %q", route.Path, route.Auth)
+		}
+	}
+
+	return nil
+}
+
+// match 判断路由是否匹配请求
+func (r *Route) match(method, path string) bool {
+	if r.Method != "" && !strings.EqualFold(r.Method, method) {
+		return false
+	}
+	if strings.HasSuffix(r.Path, "/") {
+		return strings.HasPrefix(path, r.Path)
+	}
+	return path == r.Path
+}
+
+// sample 按分布采样一次延迟
+func (l Latency) sample() time.Duration {
+	var d time.Duration
+	switch l.Distribution {
+	case "uniform":
+		if l.Max <= l.Min {
+			return l.Min
+		}
+		return l.Min + time.Duration(rand.Int63n(int64(l.Max-l.Min)))
+	case "normal":
+		d = l.Mean + time.Duration(rand.NormFloat64()*float64(l.StdDev))
+	case "exponential":
+		d = time.Duration(rand.ExpFloat64() * float64(l.Mean))
+	default:
+		d = l.Mean
+	}
+
+	// 截断
+	d = max(d, l.Min)
+	if l.Max > 0 && d > l.Max {
+		d = l.Max
+	}
+	return max(d, 0)
+}
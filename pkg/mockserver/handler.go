@@ -0,0 +1,251 @@
+package mockserver
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	mathrand "math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// sessionCookie 签发令牌时设置的Cookie名称
+const sessionCookie = "session"
+
+// filler 填充响应体的数据块
+var filler = bytes.Repeat([]byte("x"), 32*1024)
+
+// RouteStats 路由统计
+type RouteStats struct {
+	Path     string
+	Method   string
+	Requests int64
+	Errors   int64
+	Drops    int64
+}
+
+// routeCounter 路由计数器
+type routeCounter struct {
+	requests atomic.Int64
+	errors   atomic.Int64
+	drops    atomic.Int64
+}
+
+// handler 按路由配置响应请求
+type handler struct {
+	routes   []Route
+	counters []routeCounter
+	tokens   sync.Map
+}
+
+func newHandler(routes []Route) *handler {
+	return &handler{
+		routes:   routes,
+		counters: make([]routeCounter, len(routes)),
+	}
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	idx := -1
+	for i := range h.routes {
+		if h.routes[i].match(r.Method, r.URL.Path) {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		http.NotFound(w, r)
+		return
+	}
+
+	route := withQueryOverrides(h.routes[idx], r)
+	counter := &h.counters[idx]
+	counter.requests.Add(1)
+
+	// 延迟
+	if d := route.Latency.sample(); d > 0 {
+		timer := time.NewTimer(d)
+		select {
+		case <-timer.C:
+		case <-r.Context().Done():
+			timer.Stop()
+			return
+		}
+	}
+
+	// 断开连接或注入错误
+	roll := mathrand.Float64()
+	if roll < route.DropRate {
+		counter.drops.Add(1)
+		dropConnection(w)
+		return
+	}
+	roll -= route.DropRate
+	for _, e := range route.Errors {
+		if roll < e.Rate {
+			counter.errors.Add(1)
+			http.Error(w, http.StatusText(e.Status), e.Status)
+			return
+		}
+		roll -= e.Rate
+	}
+
+	for key, value := range route.Headers {
+		w.Header().Set(key, value)
+	}
+
+	body := []byte(route.Body)
+	switch route.Auth {
+	case AuthIssue:
+		token := newToken()
+		h.tokens.Store(token, struct{}{})
+		http.SetCookie(w, &http.Cookie{Name: sessionCookie, Value: token, Path: "/", HttpOnly: true})
+		if route.Body == "" {
+			w.Header().Set("Content-Type", "application/json")
+			body, _ = json.Marshal(map[string]interface{}{
+				"token":      token,
+				"token_type": "Bearer",
+				"expires_in": 3600,
+			})
+		}
+	case AuthRequire:
+		if !h.authorized(r) {
+			counter.errors.Add(1)
+			w.Header().Set("WWW-Authenticate", `Bearer realm="httpbench"`)
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+	}
+
+	size := len(body)
+	if route.Size > 0 {
+		size = route.Size
+	}
+	w.Header().Set("Content-Length", strconv.Itoa(size))
+
+	status := route.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	w.WriteHeader(status)
+	if r.Method == http.MethodHead {
+		return
+	}
+
+	writeBody(w, r, body, route.Size, route.Stream)
+}
+
+// Stats 获取各路由统计
+func (h *handler) Stats() []RouteStats {
+	stats := make([]RouteStats, len(h.routes))
+	for i, route := range h.routes {
+		stats[i] = RouteStats{
+			Path:     route.Path,
+			Method:   route.Method,
+			Requests: h.counters[i].requests.Load(),
+			Errors:   h.counters[i].errors.Load(),
+			Drops:    h.counters[i].drops.Load(),
+		}
+	}
+	return stats
+}
+
+// authorized 校验 Bearer 令牌或会话Cookie
+func (h *handler) authorized(r *http.Request) bool {
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		if _, ok := h.tokens.Load(token); ok {
+			return true
+		}
+	}
+	if cookie, err := r.Cookie(sessionCookie); err == nil {
+		if _, ok := h.tokens.Load(cookie.Value); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// withQueryOverrides 查询参数临时覆盖路由行为: ?latency=50ms&status=503&size=1024
+func withQueryOverrides(route Route, r *http.Request) Route {
+	query := r.URL.Query()
+	if len(query) == 0 {
+		return route
+	}
+
+	if d, err := time.ParseDuration(query.Get("latency")); err == nil {
+		route.Latency = Latency{Mean: d}
+	}
+	if status, err := strconv.Atoi(query.Get("status")); err == nil && status >= 100 && status <= 599 {
+		route.Status = status
+	}
+	if size, err := strconv.Atoi(query.Get("size")); err == nil && size >= 0 {
+		route.Size = size
+	}
+	return route
+}
+
+// writeBody 写入响应体, 配置了 Stream 时分块慢速发送
+func writeBody(w http.ResponseWriter, r *http.Request, body []byte, size int, stream Stream) {
+	total := len(body)
+	if size > 0 {
+		total = size
+	}
+
+	chunkSize := len(filler)
+	if stream.Interval > 0 {
+		chunkSize = stream.ChunkSize
+		if chunkSize <= 0 {
+			chunkSize = 1024
+		}
+	}
+	flusher, _ := w.(http.Flusher)
+
+	for written := 0; written < total; {
+		n := min(chunkSize, total-written)
+		var chunk []byte
+		if size > 0 {
+			chunk = filler[:min(n, len(filler))]
+		} else {
+			chunk = body[written : written+n]
+		}
+		if _, err := w.Write(chunk); err != nil {
+			return
+		}
+		written += len(chunk)
+
+		if stream.Interval > 0 && written < total {
+			if flusher != nil {
+				flusher.Flush()
+			}
+			select {
+			case <-time.After(stream.Interval):
+			case <-r.Context().Done():
+				return
+			}
+		}
+	}
+}
+
+// dropConnection 不发送响应直接断开连接
+func dropConnection(w http.ResponseWriter) {
+	if hijacker, ok := w.(http.Hijacker); ok {
+		if conn, _, err := hijacker.Hijack(); err == nil {
+			conn.Close()
+			return
+		}
+	}
+	// HTTP/2 和 HTTP/3 重置流
+	panic(http.ErrAbortHandler)
+}
+
+// newToken 生成随机令牌
+func newToken() string {
+	buf := make([]byte, 16)
+	rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
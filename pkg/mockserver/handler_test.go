@@ -0,0 +1,173 @@
+package mockserver
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// get 发送 GET 请求, 返回响应和完整响应体
+func get(t *testing.T, client *http.Client, url string, header http.Header) (*http.Response, string, error) {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatalf("创建请求失败: %v", err)
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	return resp, string(body), err
+}
+
+// TestHandler 测试延迟、错误注入、连接断开、查询参数覆盖和路由统计
+func TestHandler(t *testing.T) {
+	mock := NewServer(&Config{
+		Routes: []Route{
+			{Path: "/slow", Latency: Latency{Mean: 20 * time.Millisecond}, Size: 40000},
+			{Path: "/unavailable", Errors: []ErrorRate{{Status: 503, Rate: 1}}},
+			{Path: "/drop", DropRate: 1},
+			{Path: "/stream", Body: "abcdef", Stream: Stream{ChunkSize: 2, Interval: 10 * time.Millisecond}},
+			{Path: "/api/", Method: "GET", Headers: map[string]string{"X-Route": "api"}, Body: "api"},
+		},
+	})
+	server := httptest.NewServer(mock.Handler())
+	defer server.Close()
+	client := server.Client()
+
+	start := time.Now()
+	resp, body, err := get(t, client, server.URL+"/slow", nil)
+	if err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("响应应晚于注入的延迟: %v", elapsed)
+	}
+	if resp.StatusCode != http.StatusOK || len(body) != 40000 || resp.ContentLength != 40000 {
+		t.Errorf("填充响应体: 状态 %d, 长度 %d, Content-Length %d", resp.StatusCode, len(body), resp.ContentLength)
+	}
+
+	// 查询参数覆盖延迟、状态码和大小
+	start = time.Now()
+	resp, body, err = get(t, client, server.URL+"/slow?latency=0s&status=201&size=10", nil)
+	if err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+	if time.Since(start) >= 20*time.Millisecond || resp.StatusCode != http.StatusCreated || len(body) != 10 {
+		t.Errorf("查询参数覆盖: 状态 %d, 长度 %d", resp.StatusCode, len(body))
+	}
+
+	resp, _, err = get(t, client, server.URL+"/unavailable", nil)
+	if err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("应返回503: %d", resp.StatusCode)
+	}
+	// 新连接上断开, 避免客户端在复用的连接上自动重试
+	fresh := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+	if _, _, err := get(t, fresh, server.URL+"/drop", nil); err == nil {
+		t.Error("连接断开应返回错误")
+	}
+
+	start = time.Now()
+	if _, body, err := get(t, client, server.URL+"/stream", nil); err != nil || body != "abcdef" {
+		t.Errorf("分块响应体: %q %v", body, err)
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("分块之间应等待间隔: %v", elapsed)
+	}
+
+	// 以 "/" 结尾的路径按前缀匹配, 方法不匹配时返回404
+	resp, body, err = get(t, client, server.URL+"/api/users/1", nil)
+	if err != nil || body != "api" || resp.Header.Get("X-Route") != "api" {
+		t.Errorf("前缀匹配: %q %v", body, err)
+	}
+	resp, err = client.Post(server.URL+"/api/users", "text/plain", nil)
+	if err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("方法不匹配应返回404: %d", resp.StatusCode)
+	}
+
+	want := map[string]RouteStats{
+		"/slow":        {Requests: 2},
+		"/unavailable": {Requests: 1, Errors: 1},
+		"/drop":        {Requests: 1, Drops: 1},
+		"/stream":      {Requests: 1},
+		"/api/":        {Requests: 1},
+	}
+	for _, rs := range mock.Stats() {
+		w := want[rs.Path]
+		if rs.Requests != w.Requests || rs.Errors != w.Errors || rs.Drops != w.Drops {
+			t.Errorf("路由 %s 统计 %+v, 期望 %+v", rs.Path, rs, w)
+		}
+	}
+}
+
+// TestHandlerAuth 测试签发的令牌可通过 Authorization 头或会话Cookie认证
+func TestHandlerAuth(t *testing.T) {
+	mock := NewServer(&Config{
+		Routes: []Route{
+			{Path: "/login", Auth: AuthIssue},
+			{Path: "/private", Auth: AuthRequire, Body: "secret"},
+		},
+	})
+	server := httptest.NewServer(mock.Handler())
+	defer server.Close()
+	client := server.Client()
+
+	resp, _, err := get(t, client, server.URL+"/private", nil)
+	if err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("未认证应返回401: %d", resp.StatusCode)
+	}
+
+	resp, body, err := get(t, client, server.URL+"/login", nil)
+	if err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+	var issued struct {
+		Token string `json:"token"`
+	}
+	if err := json.Unmarshal([]byte(body), &issued); err != nil || issued.Token == "" {
+		t.Fatalf("签发令牌的响应无效: %q %v", body, err)
+	}
+	token := issued.Token
+	cookies := resp.Cookies()
+	if len(cookies) != 1 || cookies[0].Name != sessionCookie || cookies[0].Value != token {
+		t.Fatalf("签发的令牌 %q 与Cookie不一致: %v", token, cookies)
+	}
+
+	for _, header := range []http.Header{
+		{"Authorization": {"Bearer " + token}},
+		{"Cookie": {sessionCookie + "=" + token}},
+	} {
+		resp, body, err := get(t, client, server.URL+"/private", header)
+		if err != nil {
+			t.Fatalf("请求失败: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK || body != "secret" {
+			t.Errorf("%v: 状态 %d, 响应体 %q", header, resp.StatusCode, body)
+		}
+	}
+	resp, _, err = get(t, client, server.URL+"/private", http.Header{"Authorization": {"Bearer forged"}})
+	if err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("伪造的令牌应返回401: %d", resp.StatusCode)
+	}
+}
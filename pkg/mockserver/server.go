@@ -0,0 +1,188 @@
+package mockserver
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/quic-go/quic-go/http3"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+// Server 可配置的模拟目标服务
+//
+// 同时提供 HTTP/1.1 + h2c、HTTP/1.1 + HTTP/2 over TLS 和 HTTP/3 三种监听,
+// 各路由的延迟、错误率、响应大小等行为由 Config 描述。
+type Server struct {
+	cfg     *Config
+	handler *handler
+
+	httpServer  *http.Server
+	tlsServer   *http.Server
+	http3Server *http3.Server
+
+	listener    net.Listener
+	tlsListener net.Listener
+	packetConn  net.PacketConn
+}
+
+// NewServer 创建模拟目标服务
+func NewServer(cfg *Config) *Server {
+	return &Server{
+		cfg:     cfg,
+		handler: newHandler(cfg.Routes),
+	}
+}
+
+// Handler 获取请求处理器, 可直接用于 httptest.Server
+func (s *Server) Handler() http.Handler {
+	return s.handler
+}
+
+// Start 启动所有已配置的监听(非阻塞)
+func (s *Server) Start() error {
+	if err := s.cfg.Validate(); err != nil {
+		return err
+	}
+
+	if s.cfg.Addr != "" {
+		lis, err := net.Listen("tcp", s.cfg.Addr)
+		if err != nil {
+			return fmt.Errorf("监听地址失败: %w", err)
+		}
+		s.listener = lis
+		s.httpServer = &http.Server{
+			Handler:           h2c.NewHandler(s.handler, &http2.Server{}),
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+		go serve("HTTP", func() error { return s.httpServer.Serve(lis) })
+	}
+
+	if s.cfg.TLSAddr == "" && s.cfg.HTTP3Addr == "" {
+		return nil
+	}
+
+	tlsConfig, err := s.tlsConfig()
+	if err != nil {
+		s.Close()
+		return err
+	}
+
+	if s.cfg.TLSAddr != "" {
+		lis, err := net.Listen("tcp", s.cfg.TLSAddr)
+		if err != nil {
+			s.Close()
+			return fmt.Errorf("监听TLS地址失败: %w", err)
+		}
+		s.tlsListener = lis
+		s.tlsServer = &http.Server{
+			Handler:           s.handler,
+			TLSConfig:         tlsConfig.Clone(),
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+		if err := http2.ConfigureServer(s.tlsServer, &http2.Server{}); err != nil {
+			s.Close()
+			return fmt.Errorf("配置HTTP/2失败: %w", err)
+		}
+		go serve("TLS", func() error { return s.tlsServer.ServeTLS(lis, "", "") })
+	}
+
+	if s.cfg.HTTP3Addr != "" {
+		conn, err := net.ListenPacket("udp", s.cfg.HTTP3Addr)
+		if err != nil {
+			s.Close()
+			return fmt.Errorf("监听HTTP/3地址失败: %w", err)
+		}
+		s.packetConn = conn
+		s.http3Server = &http3.Server{
+			Handler:   s.handler,
+			TLSConfig: tlsConfig.Clone(),
+		}
+		go serve("HTTP/3", func() error { return s.http3Server.Serve(conn) })
+	}
+
+	return nil
+}
+
+// Addr 获取 HTTP/1.1 + h2c 实际监听地址
+func (s *Server) Addr() string {
+	if s.listener == nil {
+		return s.cfg.Addr
+	}
+	return s.listener.Addr().String()
+}
+
+// TLSAddr 获取 TLS 实际监听地址
+func (s *Server) TLSAddr() string {
+	if s.tlsListener == nil {
+		return s.cfg.TLSAddr
+	}
+	return s.tlsListener.Addr().String()
+}
+
+// HTTP3Addr 获取 HTTP/3 实际监听地址
+func (s *Server) HTTP3Addr() string {
+	if s.packetConn == nil {
+		return s.cfg.HTTP3Addr
+	}
+	return s.packetConn.LocalAddr().String()
+}
+
+// Stats 获取各路由统计
+func (s *Server) Stats() []RouteStats {
+	return s.handler.Stats()
+}
+
+// Close 关闭所有监听
+func (s *Server) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var firstErr error
+	if s.httpServer != nil {
+		firstErr = s.httpServer.Shutdown(ctx)
+	}
+	if s.tlsServer != nil {
+		if err := s.tlsServer.Shutdown(ctx); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	if s.http3Server != nil {
+		if err := s.http3Server.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		s.packetConn.Close()
+	}
+	return firstErr
+}
+
+// tlsConfig 加载证书, 未指定时生成自签名证书
+func (s *Server) tlsConfig() (*tls.Config, error) {
+	var cert tls.Certificate
+	var err error
+	if s.cfg.CertFile != "" {
+		cert, err = tls.LoadX509KeyPair(s.cfg.CertFile, s.cfg.KeyFile)
+	} else {
+		cert, err = selfSignedCert()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("加载证书失败: %w", err)
+	}
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
+// serve 运行服务, 异常退出时打印错误
+func serve(name string, fn func() error) {
+	if err := fn(); err != nil && !errors.Is(err, http.ErrServerClosed) && !errors.Is(err, net.ErrClosed) {
+		fmt.Printf("⚠️  %s服务异常退出: %v\n", name, err)
+	}
+}
@@ -0,0 +1,147 @@
+package rawhttp
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"unsafe"
+)
+
+// defaultUserAgent 与标准库客户端一致
+const defaultUserAgent = "Go-http-client/1.1"
+
+// headCache 缓存最近一次序列化的请求行和请求头
+//
+// 压测中同一原型派生的请求共享 URL 和请求头, 命中时直接写出缓存的字节。
+type headCache struct {
+	last atomic.Pointer[headEntry]
+}
+
+// headEntry 序列化结果及其来源
+type headEntry struct {
+	method string
+	url    *url.URL
+	host   string
+	header unsafe.Pointer
+	head   []byte
+}
+
+// get 获取请求的序列化请求头 (不含 Content-Length 和结束空行)
+func (hc *headCache) get(req *http.Request) []byte {
+	header := reflect.ValueOf(req.Header).UnsafePointer()
+	if e := hc.last.Load(); e != nil && e.url == req.URL && e.header == header &&
+		e.method == req.Method && e.host == req.Host {
+		return e.head
+	}
+
+	e := &headEntry{
+		method: req.Method,
+		url:    req.URL,
+		host:   req.Host,
+		header: header,
+		head:   serializeHead(req),
+	}
+	hc.last.Store(e)
+	return e.head
+}
+
+// serializeHead 序列化请求行和请求头
+func serializeHead(req *http.Request) []byte {
+	var buf bytes.Buffer
+
+	method := req.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+
+	buf.WriteString(method)
+	buf.WriteByte(' ')
+	buf.WriteString(req.URL.RequestURI())
+	buf.WriteString(" HTTP/1.1\r\nHost: ")
+	buf.WriteString(host)
+	buf.WriteString("\r\n")
+	if _, ok := req.Header["User-Agent"]; !ok {
+		buf.WriteString("User-Agent: " + defaultUserAgent + "\r\n")
+	}
+	req.Header.WriteSubset(&buf, map[string]bool{
+		"Host":              true,
+		"Content-Length":    true,
+		"Transfer-Encoding": true,
+		"Connection":        true,
+	})
+
+	return buf.Bytes()
+}
+
+// writeRequest 写出请求
+func (t *Transport) writeRequest(bw *bufio.Writer, req *http.Request, body io.Reader, length int64) error {
+	bw.Write(t.head.get(req))
+
+	// 与标准库一致: 有请求体或方法通常带请求体时发送 Content-Length
+	if length > 0 || req.Method == http.MethodPost || req.Method == http.MethodPut || req.Method == http.MethodPatch {
+		var num [20]byte
+		bw.WriteString("Content-Length: ")
+		bw.Write(strconv.AppendInt(num[:0], length, 10))
+		bw.WriteString("\r\n")
+	}
+	if t.DisableKeepAlives || wantsClose(req) {
+		bw.WriteString("Connection: close\r\n")
+	}
+	bw.WriteString("\r\n")
+
+	if body != nil {
+		if _, err := io.CopyN(bw, body, length); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// wantsClose 请求是否要求在响应后关闭连接
+func wantsClose(req *http.Request) bool {
+	if req.Close {
+		return true
+	}
+	for _, v := range req.Header["Connection"] {
+		for _, token := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(token), "close") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// requestBody 获取请求体及长度, 长度未知时读入内存
+func requestBody(req *http.Request) (io.Reader, int64, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, 0, nil
+	}
+	if req.ContentLength > 0 {
+		return req.Body, req.ContentLength, nil
+	}
+
+	data, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, 0, err
+	}
+	return bytes.NewReader(data), int64(len(data)), nil
+}
+
+// closeBody 关闭请求体, RoundTripper 须在任何情况下关闭请求体
+func closeBody(req *http.Request) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+}
@@ -0,0 +1,255 @@
+package rawhttp
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"net/textproto"
+	"strconv"
+	"strings"
+)
+
+// errLineTooLong 状态行或响应头超过读缓冲
+var errLineTooLong = errors.New("rawhttp: 响应头行过长")
+
+// readResponse 读取状态行和响应头, 跳过 1xx 中间响应
+func readResponse(br *bufio.Reader, req *http.Request) (*http.Response, error) {
+	for {
+		resp, err := readResponseHead(br, req)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode >= 200 || resp.StatusCode == http.StatusSwitchingProtocols {
+			return resp, nil
+		}
+	}
+}
+
+// readResponseHead 读取一个响应的状态行和响应头
+func readResponseHead(br *bufio.Reader, req *http.Request) (*http.Response, error) {
+	line, err := readLine(br)
+	if err != nil {
+		return nil, err
+	}
+
+	// HTTP/1.1 200 OK
+	proto, status, ok := bytes.Cut(line, []byte{' '})
+	if !ok {
+		return nil, fmt.Errorf("rawhttp: 无效的状态行 %q", line)
+	}
+	major, minor, ok := http.ParseHTTPVersion(string(proto))
+	if !ok {
+		return nil, fmt.Errorf("rawhttp: 无效的协议版本 %q", proto)
+	}
+	code, _, _ := bytes.Cut(status, []byte{' '})
+	statusCode, err := strconv.Atoi(string(code))
+	if err != nil || len(code) != 3 {
+		return nil, fmt.Errorf("rawhttp: 无效的状态码 %q", code)
+	}
+
+	resp := &http.Response{
+		Status:        string(status),
+		StatusCode:    statusCode,
+		Proto:         string(proto),
+		ProtoMajor:    major,
+		ProtoMinor:    minor,
+		Header:        make(http.Header),
+		ContentLength: -1,
+		Request:       req,
+	}
+
+	for {
+		line, err := readLine(br)
+		if err != nil {
+			return nil, err
+		}
+		if len(line) == 0 {
+			break
+		}
+		key, value, ok := bytes.Cut(line, []byte{':'})
+		if !ok {
+			return nil, fmt.Errorf("rawhttp: 无效的响应头 %q", line)
+		}
+		name := textproto.CanonicalMIMEHeaderKey(string(key))
+		resp.Header[name] = append(resp.Header[name], string(bytes.TrimSpace(value)))
+	}
+
+	// 连接是否可复用
+	connection := resp.Header.Get("Connection")
+	if major == 1 && minor == 0 {
+		resp.Close = !hasToken(connection, "keep-alive")
+	} else {
+		resp.Close = hasToken(connection, "close")
+	}
+
+	// 响应体长度
+	switch {
+	case hasToken(resp.Header.Get("Transfer-Encoding"), "chunked"):
+		resp.TransferEncoding = []string{"chunked"}
+		resp.Header.Del("Transfer-Encoding")
+	case resp.Header.Get("Content-Length") != "":
+		n, err := strconv.ParseInt(textproto.TrimString(resp.Header.Get("Content-Length")), 10, 64)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("rawhttp: 无效的 Content-Length %q", resp.Header.Get("Content-Length"))
+		}
+		resp.ContentLength = n
+	}
+	if !hasBody(resp) {
+		resp.TransferEncoding = nil
+		if req.Method != http.MethodHead {
+			resp.ContentLength = 0
+		}
+	}
+
+	return resp, nil
+}
+
+// hasBody 响应是否带响应体
+func hasBody(resp *http.Response) bool {
+	if resp.Request != nil && resp.Request.Method == http.MethodHead {
+		return false
+	}
+	switch {
+	case resp.StatusCode < 200, resp.StatusCode == http.StatusNoContent, resp.StatusCode == http.StatusNotModified:
+		return false
+	}
+	return true
+}
+
+// readLine 读取一行, 去掉行尾的 CRLF
+func readLine(br *bufio.Reader) ([]byte, error) {
+	line, err := br.ReadSlice('\n')
+	if err == bufio.ErrBufferFull {
+		return nil, errLineTooLong
+	}
+	if err != nil {
+		if err == io.EOF && len(line) > 0 {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	line = line[:len(line)-1]
+	if n := len(line); n > 0 && line[n-1] == '\r' {
+		line = line[:n-1]
+	}
+	return line, nil
+}
+
+// hasToken 逗号分隔的头部值中是否包含 token (不区分大小写)
+func hasToken(value, token string) bool {
+	for value != "" {
+		var part string
+		part, value, _ = strings.Cut(value, ",")
+		if strings.EqualFold(textproto.TrimString(part), token) {
+			return true
+		}
+	}
+	return false
+}
+
+// body 响应体, 读到结尾或关闭时通过 done 归还或关闭连接
+type body struct {
+	br *bufio.Reader
+
+	// 剩余长度, 为 -1 时读到连接关闭
+	remaining int64
+	chunked   io.Reader
+
+	done     func(reusable bool)
+	finished bool
+	err      error
+}
+
+// newBody 创建响应体
+func newBody(resp *http.Response, br *bufio.Reader, done func(reusable bool)) io.ReadCloser {
+	b := &body{br: br, done: done, remaining: resp.ContentLength}
+	switch {
+	case !hasBody(resp):
+		b.remaining = 0
+	case resp.TransferEncoding != nil:
+		b.chunked = httputil.NewChunkedReader(br)
+	case b.remaining < 0:
+		// 既无长度也非 chunked, 以连接关闭作为结束
+		resp.Close = true
+	}
+	return b
+}
+
+// Read 读取响应体
+func (b *body) Read(p []byte) (int, error) {
+	if b.err != nil {
+		return 0, b.err
+	}
+
+	var n int
+	var err error
+	switch {
+	case b.chunked != nil:
+		n, err = b.chunked.Read(p)
+		if err == io.EOF {
+			err = b.readTrailer()
+		}
+	case b.remaining >= 0:
+		if b.remaining == 0 {
+			err = io.EOF
+			break
+		}
+		if int64(len(p)) > b.remaining {
+			p = p[:b.remaining]
+		}
+		n, err = b.br.Read(p)
+		b.remaining -= int64(n)
+		if b.remaining == 0 {
+			err = io.EOF
+		} else if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+	default:
+		n, err = b.br.Read(p)
+		if err == io.EOF {
+			b.finish(false)
+			b.err = io.EOF
+			return n, io.EOF
+		}
+	}
+
+	if err != nil {
+		b.finish(err == io.EOF)
+		b.err = err
+	}
+	return n, err
+}
+
+// readTrailer 读取 chunked 结尾的 trailer 直到空行
+func (b *body) readTrailer() error {
+	for {
+		line, err := readLine(b.br)
+		if err != nil {
+			return err
+		}
+		if len(line) == 0 {
+			return io.EOF
+		}
+	}
+}
+
+// Close 关闭响应体, 未读完时关闭连接
+func (b *body) Close() error {
+	b.finish(b.remaining == 0 && b.chunked == nil)
+	if b.err == nil {
+		b.err = errors.New("rawhttp: 读取已关闭的响应体")
+	}
+	return nil
+}
+
+// finish 结束响应体, 只生效一次
+func (b *body) finish(reusable bool) {
+	if !b.finished {
+		b.finished = true
+		b.done(reusable)
+	}
+}
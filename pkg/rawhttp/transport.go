@@ -0,0 +1,454 @@
+// Package rawhttp 直接基于 TCP/TLS 连接收发 HTTP/1.1 的精简传输层
+//
+// 只实现压测所需的子集: 长连接复用、预序列化请求头、Content-Length 和 chunked 响应体,
+// 以及 httptrace 中连接、TLS 握手、写出请求和首字节的钩子。
+// 不支持代理、压缩、协议升级、100-continue 和 HTTP/2。
+package rawhttp
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+)
+
+// Transport HTTP/1.1 传输层, 实现 http.RoundTripper
+type Transport struct {
+	// TLS配置, 为 nil 时使用默认配置
+	TLSClientConfig *tls.Config
+
+	// 每个主机保留的空闲连接数, 0 表示 2
+	MaxIdleConnsPerHost int
+	// 连接总数和每个主机的连接数上限, 0 表示不限制; 达到上限时等待空闲连接或已有连接关闭
+	MaxConns        int
+	MaxConnsPerHost int
+	// 空闲连接超时, 0 表示不限制
+	IdleConnTimeout time.Duration
+	// 每个请求使用新连接
+	DisableKeepAlives bool
+
+	// 建立TCP连接, 为 nil 时使用 net.Dialer
+	DialContext func(ctx context.Context, network, addr string) (net.Conn, error)
+	// 建立连接超时, 0 表示只受请求上下文限制
+	DialTimeout time.Duration
+
+	mu   sync.Mutex
+	idle map[string][]*conn
+
+	// 现有连接数及等待连接的请求, 仅在配置了上限时维护
+	conns   int
+	perHost map[string]int
+	waiters []*connWaiter
+
+	// 最近一次序列化的请求头
+	head headCache
+}
+
+// conn 一个 HTTP/1.1 连接
+type conn struct {
+	net.Conn
+	br *bufio.Reader
+	bw *bufio.Writer
+
+	key      string
+	idleAt   time.Time
+	reused   bool
+	deadline bool
+
+	// 计入连接数上限时关闭后释放名额
+	t         *Transport
+	closeOnce sync.Once
+}
+
+// Close 关闭连接并释放名额
+func (c *conn) Close() error {
+	err := c.Conn.Close()
+	if c.t != nil {
+		c.closeOnce.Do(func() { c.t.releaseSlot(c.key) })
+	}
+	return err
+}
+
+// connWaiter 等待连接名额的请求, 收到空闲连接或 nil (可以新建连接)
+type connWaiter struct {
+	key string
+	ch  chan *conn
+}
+
+// errServerClosedIdle 复用的空闲连接已被服务端关闭
+var errServerClosedIdle = errors.New("rawhttp: 服务端关闭了空闲连接")
+
+// RoundTrip 发送请求并读取响应头, 响应体在读取完毕后归还连接
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL == nil {
+		closeBody(req)
+		return nil, errors.New("rawhttp: 请求缺少URL")
+	}
+	if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+		closeBody(req)
+		return nil, fmt.Errorf("rawhttp: 不支持的协议 %q", req.URL.Scheme)
+	}
+
+	body, length, err := requestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(req)
+
+	ctx := req.Context()
+	for {
+		c, err := t.getConn(ctx, req.URL)
+		if err != nil {
+			return nil, err
+		}
+		if trace := httptrace.ContextClientTrace(ctx); trace != nil && trace.GotConn != nil {
+			trace.GotConn(httptrace.GotConnInfo{Conn: c.Conn, Reused: c.reused, WasIdle: c.reused})
+		}
+
+		resp, err := t.roundTrip(ctx, c, req, body, length)
+		if err == nil {
+			return resp, nil
+		}
+		c.Close()
+
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		// 复用的连接可能已被服务端关闭, 在未收到任何响应时换新连接重试
+		if !errors.Is(err, errServerClosedIdle) {
+			return nil, err
+		}
+		if body != nil {
+			if req.GetBody == nil {
+				return nil, err
+			}
+			rc, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			defer rc.Close()
+			body = rc
+		}
+	}
+}
+
+// roundTrip 在指定连接上完成一次请求
+func (t *Transport) roundTrip(ctx context.Context, c *conn, req *http.Request, body io.Reader, length int64) (*http.Response, error) {
+	// 上下文取消时中断阻塞的读写
+	stop := context.AfterFunc(ctx, func() {
+		c.SetDeadline(time.Unix(1, 0))
+	})
+	if d, ok := ctx.Deadline(); ok {
+		c.SetDeadline(d)
+		c.deadline = true
+	} else if c.deadline {
+		c.SetDeadline(time.Time{})
+		c.deadline = false
+	}
+
+	trace := httptrace.ContextClientTrace(ctx)
+	err := t.writeRequest(c.bw, req, body, length)
+	if trace != nil && trace.WroteRequest != nil {
+		trace.WroteRequest(httptrace.WroteRequestInfo{Err: err})
+	}
+	if err == nil {
+		_, err = c.br.Peek(1)
+		if err == nil && trace != nil && trace.GotFirstResponseByte != nil {
+			trace.GotFirstResponseByte()
+		}
+	}
+	if err != nil {
+		stop()
+		if c.reused && !errors.Is(err, os.ErrDeadlineExceeded) {
+			return nil, errServerClosedIdle
+		}
+		return nil, err
+	}
+
+	resp, err := readResponse(c.br, req)
+	if err != nil {
+		stop()
+		return nil, err
+	}
+
+	reuse := !t.DisableKeepAlives && !resp.Close && !wantsClose(req)
+	resp.Body = newBody(resp, c.br, func(ok bool) {
+		stop()
+		if ok && reuse && ctx.Err() == nil {
+			t.putConn(c)
+		} else {
+			c.Close()
+		}
+	})
+	return resp, nil
+}
+
+// getConn 获取空闲连接或建立新连接, 达到连接数上限时等待
+func (t *Transport) getConn(ctx context.Context, u *url.URL) (*conn, error) {
+	addr := canonicalAddr(u)
+	key := u.Scheme + "://" + addr
+
+	for {
+		var expired []*conn
+		t.mu.Lock()
+		if !t.DisableKeepAlives {
+			for conns := t.idle[key]; len(conns) > 0; conns = t.idle[key] {
+				c := conns[len(conns)-1]
+				t.idle[key] = conns[:len(conns)-1]
+				if t.IdleConnTimeout > 0 && time.Since(c.idleAt) > t.IdleConnTimeout {
+					expired = append(expired, c)
+					continue
+				}
+				t.mu.Unlock()
+				closeConns(expired)
+				c.reused = true
+				return c, nil
+			}
+		}
+
+		if !t.limited() {
+			t.mu.Unlock()
+			closeConns(expired)
+			return t.dial(ctx, u, addr, key)
+		}
+		if t.canDialLocked(key) {
+			t.conns++
+			t.perHost[key]++
+			t.mu.Unlock()
+			closeConns(expired)
+
+			c, err := t.dial(ctx, u, addr, key)
+			if err != nil {
+				t.releaseSlot(key)
+				return nil, err
+			}
+			c.t = t
+			return c, nil
+		}
+
+		// 总数达到上限时关闭其他主机的空闲连接腾出名额
+		if victim := t.evictIdleLocked(key); victim != nil {
+			t.mu.Unlock()
+			closeConns(append(expired, victim))
+			continue
+		}
+
+		w := &connWaiter{key: key, ch: make(chan *conn, 1)}
+		t.waiters = append(t.waiters, w)
+		t.mu.Unlock()
+		closeConns(expired)
+
+		select {
+		case c := <-w.ch:
+			if c != nil {
+				c.reused = true
+				return c, nil
+			}
+		case <-ctx.Done():
+			t.mu.Lock()
+			removed := t.removeWaiterLocked(w)
+			t.mu.Unlock()
+			if !removed {
+				// 已被分配连接或名额, 转交给其他请求
+				if c := <-w.ch; c != nil {
+					t.putConn(c)
+				} else {
+					t.mu.Lock()
+					t.wakeLocked()
+					t.mu.Unlock()
+				}
+			}
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// limited 是否配置了连接数上限
+func (t *Transport) limited() bool {
+	return t.MaxConns > 0 || t.MaxConnsPerHost > 0
+}
+
+// canDialLocked 是否还有新建连接的名额
+func (t *Transport) canDialLocked(key string) bool {
+	if t.perHost == nil {
+		t.perHost = make(map[string]int)
+	}
+	return (t.MaxConns <= 0 || t.conns < t.MaxConns) &&
+		(t.MaxConnsPerHost <= 0 || t.perHost[key] < t.MaxConnsPerHost)
+}
+
+// evictIdleLocked 总数达到上限而主机名额未满时, 取出其他主机的一个空闲连接
+func (t *Transport) evictIdleLocked(key string) *conn {
+	if t.MaxConns <= 0 || t.conns < t.MaxConns ||
+		t.MaxConnsPerHost > 0 && t.perHost[key] >= t.MaxConnsPerHost {
+		return nil
+	}
+	for k, conns := range t.idle {
+		if k != key && len(conns) > 0 {
+			c := conns[0]
+			t.idle[k] = conns[1:]
+			return c
+		}
+	}
+	return nil
+}
+
+// releaseSlot 连接关闭后释放名额并唤醒等待的请求
+func (t *Transport) releaseSlot(key string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.conns--
+	if t.perHost[key]--; t.perHost[key] <= 0 {
+		delete(t.perHost, key)
+	}
+	t.wakeLocked()
+}
+
+// wakeLocked 通知第一个可以新建连接的等待者
+func (t *Transport) wakeLocked() {
+	for i, w := range t.waiters {
+		if t.canDialLocked(w.key) {
+			t.waiters = append(t.waiters[:i], t.waiters[i+1:]...)
+			w.ch <- nil
+			return
+		}
+	}
+}
+
+// removeWaiterLocked 移除等待者, 已被通知时返回 false
+func (t *Transport) removeWaiterLocked(w *connWaiter) bool {
+	for i, other := range t.waiters {
+		if other == w {
+			t.waiters = append(t.waiters[:i], t.waiters[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// closeConns 在锁外关闭连接, 关闭时会释放名额
+func closeConns(conns []*conn) {
+	for _, c := range conns {
+		c.Close()
+	}
+}
+
+// dial 建立新连接
+func (t *Transport) dial(ctx context.Context, u *url.URL, addr, key string) (*conn, error) {
+	if t.DialTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, t.DialTimeout)
+		defer cancel()
+	}
+
+	dial := t.DialContext
+	if dial == nil {
+		var dialer net.Dialer
+		dial = dialer.DialContext
+	}
+	// 连接的开始和完成由 net.Dialer 通过上下文中的 httptrace 报告
+	nc, err := dial(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	if u.Scheme == "https" {
+		cfg := &tls.Config{}
+		if t.TLSClientConfig != nil {
+			cfg = t.TLSClientConfig.Clone()
+		}
+		if cfg.ServerName == "" {
+			cfg.ServerName = u.Hostname()
+		}
+		cfg.NextProtos = []string{"http/1.1"}
+
+		trace := httptrace.ContextClientTrace(ctx)
+		if trace != nil && trace.TLSHandshakeStart != nil {
+			trace.TLSHandshakeStart()
+		}
+		tc := tls.Client(nc, cfg)
+		err := tc.HandshakeContext(ctx)
+		if trace != nil && trace.TLSHandshakeDone != nil {
+			trace.TLSHandshakeDone(tc.ConnectionState(), err)
+		}
+		if err != nil {
+			nc.Close()
+			return nil, err
+		}
+		nc = tc
+	}
+
+	return &conn{
+		Conn: nc,
+		br:   bufio.NewReaderSize(nc, 16<<10),
+		bw:   bufio.NewWriterSize(nc, 4<<10),
+		key:  key,
+	}, nil
+}
+
+// putConn 归还空闲连接, 有请求在等待时直接转交
+func (t *Transport) putConn(c *conn) {
+	maxIdle := t.MaxIdleConnsPerHost
+	if maxIdle <= 0 {
+		maxIdle = 2
+	}
+
+	t.mu.Lock()
+	for i, w := range t.waiters {
+		if w.key == c.key {
+			t.waiters = append(t.waiters[:i], t.waiters[i+1:]...)
+			t.mu.Unlock()
+			w.ch <- c
+			return
+		}
+	}
+
+	// 其他主机在等待总数名额时不保留空闲连接
+	if t.idle == nil {
+		t.idle = make(map[string][]*conn)
+	}
+	if len(t.idle[c.key]) >= maxIdle || len(t.waiters) > 0 {
+		t.mu.Unlock()
+		c.Close()
+		return
+	}
+	c.idleAt = time.Now()
+	t.idle[c.key] = append(t.idle[c.key], c)
+	t.mu.Unlock()
+}
+
+// CloseIdleConnections 关闭所有空闲连接
+func (t *Transport) CloseIdleConnections() {
+	t.mu.Lock()
+	idle := t.idle
+	t.idle = nil
+	t.mu.Unlock()
+
+	for _, conns := range idle {
+		for _, c := range conns {
+			c.Close()
+		}
+	}
+}
+
+// canonicalAddr 补全默认端口
+func canonicalAddr(u *url.URL) string {
+	port := u.Port()
+	if port == "" {
+		port = "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+	}
+	return net.JoinHostPort(u.Hostname(), port)
+}
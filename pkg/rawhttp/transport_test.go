@@ -0,0 +1,134 @@
+package rawhttp
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// TestTransport 测试与标准客户端的收发结果一致
+func TestTransport(t *testing.T) {
+	mux := http.NewServeMux()
+	// 回显服务端看到的请求
+	echo := func(w http.ResponseWriter, r *http.Request) string {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("X-Method", r.Method)
+		w.Header().Set("X-URI", r.RequestURI)
+		w.Header().Set("X-Host", r.Host)
+		w.Header().Set("X-Length", strconv.FormatInt(r.ContentLength, 10))
+		w.Header().Set("X-User-Agent", r.UserAgent())
+		w.Header().Set("X-Client", r.Header.Get("X-Client"))
+		w.Header().Add("X-Multi", "a")
+		w.Header().Add("X-Multi", "b")
+		return string(body)
+	}
+	mux.HandleFunc("/length", func(w http.ResponseWriter, r *http.Request) {
+		body := "echo:" + echo(w, r)
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		io.WriteString(w, body)
+	})
+	mux.HandleFunc("/chunked", func(w http.ResponseWriter, r *http.Request) {
+		echo(w, r)
+		for i := 0; i < 5; i++ {
+			fmt.Fprintf(w, "chunk-%d;", i)
+			w.(http.Flusher).Flush()
+		}
+	})
+	mux.HandleFunc("/close", func(w http.ResponseWriter, r *http.Request) {
+		echo(w, r)
+		w.Header().Set("Connection", "close")
+		io.WriteString(w, strings.Repeat("z", 10000))
+	})
+	mux.HandleFunc("/empty", func(w http.ResponseWriter, r *http.Request) {
+		echo(w, r)
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
+		echo(w, r)
+		w.Header().Set("Content-Length", "4")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		io.WriteString(w, "busy")
+	})
+
+	cases := []struct {
+		method, path, body string
+	}{
+		{"GET", "/length?a=1&b=%20x", ""},
+		{"POST", "/length", `{"k":"v"}`},
+		{"POST", "/length", ""},
+		{"PUT", "/chunked", strings.Repeat("p", 100000)},
+		{"GET", "/chunked", ""},
+		{"GET", "/close", ""},
+		{"DELETE", "/empty", ""},
+		{"HEAD", "/length", ""},
+		{"GET", "/status", ""},
+	}
+
+	do := func(client *http.Client, base, method, path, body string) (*http.Response, string) {
+		var reqBody io.Reader
+		if body != "" {
+			reqBody = strings.NewReader(body)
+		}
+		req, err := http.NewRequest(method, base+path, reqBody)
+		if err != nil {
+			t.Fatalf("创建请求失败: %v", err)
+		}
+		req.Header.Set("X-Client", "httpbench")
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s 失败: %v", method, path, err)
+		}
+		defer resp.Body.Close()
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatalf("%s %s 读取响应失败: %v", method, path, err)
+		}
+		return resp, string(data)
+	}
+
+	for _, tls := range []bool{false, true} {
+		var server *httptest.Server
+		if tls {
+			server = httptest.NewTLSServer(mux)
+		} else {
+			server = httptest.NewServer(mux)
+		}
+
+		std := &http.Client{Transport: &http.Transport{
+			TLSClientConfig:    server.Client().Transport.(*http.Transport).TLSClientConfig,
+			DisableCompression: true,
+		}}
+		raw := &http.Client{Transport: &Transport{
+			TLSClientConfig: server.Client().Transport.(*http.Transport).TLSClientConfig,
+		}}
+
+		// 重复两轮以覆盖连接复用
+		for round := 0; round < 2; round++ {
+			for _, tc := range cases {
+				want, wantBody := do(std, server.URL, tc.method, tc.path, tc.body)
+				got, gotBody := do(raw, server.URL, tc.method, tc.path, tc.body)
+
+				name := fmt.Sprintf("tls=%v %s %s", tls, tc.method, tc.path)
+				if got.StatusCode != want.StatusCode || got.Status != want.Status {
+					t.Errorf("%s: 状态 %q, 期望 %q", name, got.Status, want.Status)
+				}
+				if gotBody != wantBody {
+					t.Errorf("%s: 响应体长度 %d, 期望 %d", name, len(gotBody), len(wantBody))
+				}
+				if got.ContentLength != want.ContentLength {
+					t.Errorf("%s: ContentLength %d, 期望 %d", name, got.ContentLength, want.ContentLength)
+				}
+				for _, key := range []string{"X-Method", "X-URI", "X-Host", "X-Length", "X-User-Agent", "X-Client", "X-Multi", "Content-Type"} {
+					if g, w := strings.Join(got.Header.Values(key), ","), strings.Join(want.Header.Values(key), ","); g != w {
+						t.Errorf("%s: %s = %q, 期望 %q", name, key, g, w)
+					}
+				}
+			}
+		}
+		server.Close()
+	}
+}
@@ -0,0 +1,439 @@
+package rawlog
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// csvHeader CSV列定义, 耗时单位为微秒
+var csvHeader = []string{
+	"timestamp", "endpoint", "worker_id", "status_code", "success",
+	"latency_us", "dns_us", "connect_us", "tls_us", "first_byte_us",
+	"bytes_received", "bytes_sent", "conn_reused", "error_type", "error",
+}
+
+// csvEncoder CSV编码器
+type csvEncoder struct {
+	writer *csv.Writer
+	row    []string
+}
+
+func newCSVEncoder(w io.Writer) (*csvEncoder, error) {
+	e := &csvEncoder{
+		writer: csv.NewWriter(w),
+		row:    make([]string, len(csvHeader)),
+	}
+	if err := e.writer.Write(csvHeader); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (e *csvEncoder) Encode(r *Record) error {
+	e.row[0] = r.Timestamp.Format(time.RFC3339Nano)
+	e.row[1] = r.Endpoint
+	e.row[2] = strconv.Itoa(r.WorkerID)
+	e.row[3] = strconv.Itoa(r.StatusCode)
+	e.row[4] = strconv.FormatBool(r.Success)
+	e.row[5] = strconv.FormatInt(r.Latency.Microseconds(), 10)
+	e.row[6] = strconv.FormatInt(r.DNS.Microseconds(), 10)
+	e.row[7] = strconv.FormatInt(r.Connect.Microseconds(), 10)
+	e.row[8] = strconv.FormatInt(r.TLS.Microseconds(), 10)
+	e.row[9] = strconv.FormatInt(r.FirstByte.Microseconds(), 10)
+	e.row[10] = strconv.FormatInt(r.BytesReceived, 10)
+	e.row[11] = strconv.FormatInt(r.BytesSent, 10)
+	e.row[12] = strconv.FormatBool(r.ConnReused)
+	e.row[13] = r.ErrorType
+	e.row[14] = r.Error
+	return e.writer.Write(e.row)
+}
+
+func (e *csvEncoder) Flush() error {
+	e.writer.Flush()
+	return e.writer.Error()
+}
+
+// csvDecoder CSV解码器
+type csvDecoder struct {
+	reader *csv.Reader
+}
+
+func newCSVDecoder(r io.Reader) (*csvDecoder, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = len(csvHeader)
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("读取CSV表头失败: %w", err)
+	}
+	if header[0] != csvHeader[0] {
+		return nil, fmt.Errorf("无法识别的CSV表头: %v", header)
+	}
+
+	return &csvDecoder{reader: reader}, nil
+}
+
+func (d *csvDecoder) Decode(r *Record) error {
+	row, err := d.reader.Read()
+	if err != nil {
+		return err
+	}
+
+	var parseErr error
+	parseInt := func(s string) int64 {
+		v, err := strconv.ParseInt(s, 10, 64)
+		if err != nil && parseErr == nil {
+			parseErr = err
+		}
+		return v
+	}
+	parseBool := func(s string) bool {
+		v, err := strconv.ParseBool(s)
+		if err != nil && parseErr == nil {
+			parseErr = err
+		}
+		return v
+	}
+
+	ts, err := time.Parse(time.RFC3339Nano, row[0])
+	if err != nil {
+		return fmt.Errorf("解析时间戳失败: %w", err)
+	}
+
+	*r = Record{
+		Timestamp:     ts,
+		Endpoint:      row[1],
+		WorkerID:      int(parseInt(row[2])),
+		StatusCode:    int(parseInt(row[3])),
+		Success:       parseBool(row[4]),
+		Latency:       time.Duration(parseInt(row[5])) * time.Microsecond,
+		DNS:           time.Duration(parseInt(row[6])) * time.Microsecond,
+		Connect:       time.Duration(parseInt(row[7])) * time.Microsecond,
+		TLS:           time.Duration(parseInt(row[8])) * time.Microsecond,
+		FirstByte:     time.Duration(parseInt(row[9])) * time.Microsecond,
+		BytesReceived: parseInt(row[10]),
+		BytesSent:     parseInt(row[11]),
+		ConnReused:    parseBool(row[12]),
+		ErrorType:     row[13],
+		Error:         row[14],
+	}
+	if parseErr != nil {
+		return fmt.Errorf("解析CSV记录失败: %w", parseErr)
+	}
+	return nil
+}
+
+// jsonRecord NDJSON记录, 耗时单位为微秒
+type jsonRecord struct {
+	Timestamp     time.Time `json:"timestamp"`
+	Endpoint      string    `json:"endpoint"`
+	WorkerID      int       `json:"worker_id"`
+	StatusCode    int       `json:"status_code"`
+	Success       bool      `json:"success"`
+	LatencyUs     int64     `json:"latency_us"`
+	DNSUs         int64     `json:"dns_us,omitempty"`
+	ConnectUs     int64     `json:"connect_us,omitempty"`
+	TLSUs         int64     `json:"tls_us,omitempty"`
+	FirstByteUs   int64     `json:"first_byte_us,omitempty"`
+	BytesReceived int64     `json:"bytes_received"`
+	BytesSent     int64     `json:"bytes_sent"`
+	ConnReused    bool      `json:"conn_reused"`
+	ErrorType     string    `json:"error_type,omitempty"`
+	Error         string    `json:"error,omitempty"`
+}
+
+// ndjsonEncoder NDJSON编码器
+type ndjsonEncoder struct {
+	encoder *json.Encoder
+}
+
+func newNDJSONEncoder(w io.Writer) *ndjsonEncoder {
+	return &ndjsonEncoder{encoder: json.NewEncoder(w)}
+}
+
+func (e *ndjsonEncoder) Encode(r *Record) error {
+	return e.encoder.Encode(jsonRecord{
+		Timestamp:     r.Timestamp,
+		Endpoint:      r.Endpoint,
+		WorkerID:      r.WorkerID,
+		StatusCode:    r.StatusCode,
+		Success:       r.Success,
+		LatencyUs:     r.Latency.Microseconds(),
+		DNSUs:         r.DNS.Microseconds(),
+		ConnectUs:     r.Connect.Microseconds(),
+		TLSUs:         r.TLS.Microseconds(),
+		FirstByteUs:   r.FirstByte.Microseconds(),
+		BytesReceived: r.BytesReceived,
+		BytesSent:     r.BytesSent,
+		ConnReused:    r.ConnReused,
+		ErrorType:     r.ErrorType,
+		Error:         r.Error,
+	})
+}
+
+func (e *ndjsonEncoder) Flush() error {
+	return nil
+}
+
+// ndjsonDecoder NDJSON解码器
+type ndjsonDecoder struct {
+	decoder *json.Decoder
+}
+
+func newNDJSONDecoder(r io.Reader) *ndjsonDecoder {
+	return &ndjsonDecoder{decoder: json.NewDecoder(r)}
+}
+
+func (d *ndjsonDecoder) Decode(r *Record) error {
+	var jr jsonRecord
+	if err := d.decoder.Decode(&jr); err != nil {
+		return err
+	}
+
+	*r = Record{
+		Timestamp:     jr.Timestamp,
+		Endpoint:      jr.Endpoint,
+		WorkerID:      jr.WorkerID,
+		StatusCode:    jr.StatusCode,
+		Success:       jr.Success,
+		Latency:       time.Duration(jr.LatencyUs) * time.Microsecond,
+		DNS:           time.Duration(jr.DNSUs) * time.Microsecond,
+		Connect:       time.Duration(jr.ConnectUs) * time.Microsecond,
+		TLS:           time.Duration(jr.TLSUs) * time.Microsecond,
+		FirstByte:     time.Duration(jr.FirstByteUs) * time.Microsecond,
+		BytesReceived: jr.BytesReceived,
+		BytesSent:     jr.BytesSent,
+		ConnReused:    jr.ConnReused,
+		ErrorType:     jr.ErrorType,
+		Error:         jr.Error,
+	}
+	return nil
+}
+
+// 紧凑二进制格式
+//
+// 文件以 binaryMagic 开头,之后每条记录依次为:
+//
+//	varint   与上条记录的时间戳差值 (纳秒)
+//	string   端点
+//	uvarint  工作协程ID, 状态码
+//	byte     标志位 (bit0 成功, bit1 连接复用)
+//	uvarint  总延迟, DNS, 建连, TLS, 首字节 (微秒)
+//	uvarint  接收字节数, 发送字节数
+//	string   错误类型, 错误信息
+//
+// 字符串以 uvarint 标签开头: 0 为空串, 1 为新字典项(随后为长度和内容),
+// 2 为不入字典的字面量(字典已满时使用), n>=3 引用字典第 n-3 项。
+const binaryMagic = "HBRAW\x01"
+
+const (
+	strEmpty   = 0
+	strDefine  = 1
+	strLiteral = 2
+	strRefBase = 3
+
+	// maxDictSize 字符串字典上限,避免唯一错误信息导致内存无限增长
+	maxDictSize = 4096
+)
+
+const (
+	flagSuccess    = 1 << 0
+	flagConnReused = 1 << 1
+)
+
+// binaryEncoder 二进制编码器
+type binaryEncoder struct {
+	w      io.Writer
+	buf    []byte
+	lastTS int64
+	dict   map[string]uint64
+}
+
+func newBinaryEncoder(w io.Writer) (*binaryEncoder, error) {
+	if _, err := io.WriteString(w, binaryMagic); err != nil {
+		return nil, err
+	}
+	return &binaryEncoder{
+		w:    w,
+		buf:  make([]byte, 0, 256),
+		dict: make(map[string]uint64),
+	}, nil
+}
+
+func (e *binaryEncoder) Encode(r *Record) error {
+	b := e.buf[:0]
+
+	ts := r.Timestamp.UnixNano()
+	b = binary.AppendVarint(b, ts-e.lastTS)
+	e.lastTS = ts
+
+	b = e.appendString(b, r.Endpoint)
+	b = binary.AppendUvarint(b, uint64(r.WorkerID))
+	b = binary.AppendUvarint(b, uint64(r.StatusCode))
+
+	var flags byte
+	if r.Success {
+		flags |= flagSuccess
+	}
+	if r.ConnReused {
+		flags |= flagConnReused
+	}
+	b = append(b, flags)
+
+	for _, d := range []time.Duration{r.Latency, r.DNS, r.Connect, r.TLS, r.FirstByte} {
+		b = binary.AppendUvarint(b, uint64(d.Microseconds()))
+	}
+	b = binary.AppendUvarint(b, uint64(r.BytesReceived))
+	b = binary.AppendUvarint(b, uint64(r.BytesSent))
+	b = e.appendString(b, r.ErrorType)
+	b = e.appendString(b, r.Error)
+
+	e.buf = b
+	_, err := e.w.Write(b)
+	return err
+}
+
+// appendString 追加字符串 (优先使用字典引用)
+func (e *binaryEncoder) appendString(b []byte, s string) []byte {
+	if s == "" {
+		return binary.AppendUvarint(b, strEmpty)
+	}
+	if idx, ok := e.dict[s]; ok {
+		return binary.AppendUvarint(b, strRefBase+idx)
+	}
+
+	tag := uint64(strLiteral)
+	if len(e.dict) < maxDictSize {
+		e.dict[s] = uint64(len(e.dict))
+		tag = strDefine
+	}
+	b = binary.AppendUvarint(b, tag)
+	b = binary.AppendUvarint(b, uint64(len(s)))
+	return append(b, s...)
+}
+
+func (e *binaryEncoder) Flush() error {
+	return nil
+}
+
+// binaryDecoder 二进制解码器
+type binaryDecoder struct {
+	r      *bufio.Reader
+	lastTS int64
+	dict   []string
+}
+
+func newBinaryDecoder(r *bufio.Reader) (*binaryDecoder, error) {
+	magic := make([]byte, len(binaryMagic))
+	if _, err := io.ReadFull(r, magic); err != nil || string(magic) != binaryMagic {
+		return nil, fmt.Errorf("不是有效的原始结果二进制文件")
+	}
+	return &binaryDecoder{r: r}, nil
+}
+
+func (d *binaryDecoder) Decode(r *Record) error {
+	delta, err := binary.ReadVarint(d.r)
+	if err != nil {
+		return err // 记录边界处的 io.EOF 表示正常结束
+	}
+	d.lastTS += delta
+
+	rec := Record{Timestamp: time.Unix(0, d.lastTS)}
+	if err := d.decodeBody(&rec); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return fmt.Errorf("解析二进制记录失败: %w", err)
+	}
+
+	*r = rec
+	return nil
+}
+
+// decodeBody 解析时间戳之后的字段
+func (d *binaryDecoder) decodeBody(r *Record) error {
+	var err error
+	if r.Endpoint, err = d.readString(); err != nil {
+		return err
+	}
+
+	var values [9]uint64
+	for i := 0; i < 2; i++ {
+		if values[i], err = binary.ReadUvarint(d.r); err != nil {
+			return err
+		}
+	}
+	r.WorkerID = int(values[0])
+	r.StatusCode = int(values[1])
+
+	flags, err := d.r.ReadByte()
+	if err != nil {
+		return err
+	}
+	r.Success = flags&flagSuccess != 0
+	r.ConnReused = flags&flagConnReused != 0
+
+	for i := 0; i < 7; i++ {
+		if values[i], err = binary.ReadUvarint(d.r); err != nil {
+			return err
+		}
+	}
+	r.Latency = time.Duration(values[0]) * time.Microsecond
+	r.DNS = time.Duration(values[1]) * time.Microsecond
+	r.Connect = time.Duration(values[2]) * time.Microsecond
+	r.TLS = time.Duration(values[3]) * time.Microsecond
+	r.FirstByte = time.Duration(values[4]) * time.Microsecond
+	r.BytesReceived = int64(values[5])
+	r.BytesSent = int64(values[6])
+
+	if r.ErrorType, err = d.readString(); err != nil {
+		return err
+	}
+	r.Error, err = d.readString()
+	return err
+}
+
+// readString 读取字符串
+func (d *binaryDecoder) readString() (string, error) {
+	tag, err := binary.ReadUvarint(d.r)
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case tag == strEmpty:
+		return "", nil
+	case tag >= strRefBase:
+		idx := tag - strRefBase
+		if idx >= uint64(len(d.dict)) {
+			return "", fmt.Errorf("无效的字典引用 %d", idx)
+		}
+		return d.dict[idx], nil
+	}
+
+	n, err := binary.ReadUvarint(d.r)
+	if err != nil {
+		return "", err
+	}
+	if n > 1<<20 {
+		return "", fmt.Errorf("字符串长度异常: %d", n)
+	}
+	data := make([]byte, n)
+	if _, err := io.ReadFull(d.r, data); err != nil {
+		return "", err
+	}
+
+	s := string(data)
+	if tag == strDefine {
+		d.dict = append(d.dict, s)
+	}
+	return s, nil
+}
@@ -0,0 +1,201 @@
+package rawlog
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// 原始结果文件格式
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+	FormatBinary = "binary"
+)
+
+// defaultBufferSize 默认异步缓冲的记录数
+const defaultBufferSize = 8192
+
+// Record 单个请求的原始结果
+type Record struct {
+	Timestamp time.Time // 请求开始时间
+	Endpoint  string
+	WorkerID  int
+
+	StatusCode int // 0 表示未收到响应
+	Success    bool
+
+	// 延迟及各阶段耗时 (未发生的阶段为0)
+	Latency   time.Duration
+	DNS       time.Duration
+	Connect   time.Duration
+	TLS       time.Duration
+	FirstByte time.Duration
+
+	BytesReceived int64
+	BytesSent     int64
+	ConnReused    bool
+
+	ErrorType string
+	Error     string
+}
+
+// encoder 记录编码器
+type encoder interface {
+	Encode(r *Record) error
+	Flush() error
+}
+
+// Writer 原始结果异步写入器
+//
+// Write 从不阻塞调用方:缓冲区满时直接丢弃记录并计数。
+type Writer struct {
+	path    string
+	file    *os.File
+	buf     *bufio.Writer
+	encoder encoder
+
+	records chan Record
+	done    chan struct{}
+	mu      sync.RWMutex
+	closed  bool
+
+	written atomic.Int64
+	dropped atomic.Int64
+	err     error
+}
+
+// DetectFormat 根据文件扩展名推断格式
+func DetectFormat(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return FormatCSV
+	case ".ndjson", ".jsonl", ".json":
+		return FormatNDJSON
+	default:
+		return FormatBinary
+	}
+}
+
+// NewWriter 创建原始结果写入器, format为空时根据扩展名推断
+func NewWriter(path, format string, bufferSize int) (*Writer, error) {
+	if format == "" {
+		format = DetectFormat(path)
+	}
+	if bufferSize <= 0 {
+		bufferSize = defaultBufferSize
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("创建原始结果文件失败: %w", err)
+	}
+
+	w := &Writer{
+		path:    path,
+		file:    file,
+		buf:     bufio.NewWriterSize(file, 64*1024),
+		records: make(chan Record, bufferSize),
+		done:    make(chan struct{}),
+	}
+
+	switch format {
+	case FormatCSV:
+		w.encoder, err = newCSVEncoder(w.buf)
+	case FormatNDJSON:
+		w.encoder = newNDJSONEncoder(w.buf)
+	case FormatBinary:
+		w.encoder, err = newBinaryEncoder(w.buf)
+	default:
+		err = fmt.Errorf("不支持的原始结果格式: %s", format)
+	}
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	go w.run()
+
+	return w, nil
+}
+
+// Write 异步写入一条记录
+func (w *Writer) Write(r Record) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if w.closed {
+		w.dropped.Add(1)
+		return
+	}
+
+	select {
+	case w.records <- r:
+	default:
+		w.dropped.Add(1)
+	}
+}
+
+// run 写入循环
+func (w *Writer) run() {
+	defer close(w.done)
+
+	for r := range w.records {
+		if w.err != nil {
+			w.dropped.Add(1)
+			continue
+		}
+		if err := w.encoder.Encode(&r); err != nil {
+			w.err = fmt.Errorf("写入原始结果失败: %w", err)
+			w.dropped.Add(1)
+			continue
+		}
+		w.written.Add(1)
+	}
+}
+
+// Written 获取已写入的记录数
+func (w *Writer) Written() int64 {
+	return w.written.Load()
+}
+
+// Dropped 获取因缓冲区满或写入失败丢弃的记录数
+func (w *Writer) Dropped() int64 {
+	return w.dropped.Load()
+}
+
+// Path 获取文件路径
+func (w *Writer) Path() string {
+	return w.path
+}
+
+// Close 写入剩余记录并关闭文件
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	close(w.records)
+	w.mu.Unlock()
+
+	<-w.done
+
+	err := w.err
+	if flushErr := w.encoder.Flush(); flushErr != nil && err == nil {
+		err = flushErr
+	}
+	if flushErr := w.buf.Flush(); flushErr != nil && err == nil {
+		err = flushErr
+	}
+	if closeErr := w.file.Close(); closeErr != nil && err == nil {
+		err = closeErr
+	}
+	return err
+}
@@ -0,0 +1,309 @@
+package rawlog
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testRecords 覆盖各字段的记录, 耗时为整微秒以便各格式无损往返
+func testRecords() []Record {
+	start := time.Date(2024, 5, 1, 12, 0, 0, 123456789, time.UTC)
+	return []Record{
+		{
+			Timestamp: start, Endpoint: "GET /api", WorkerID: 0, StatusCode: 200, Success: true,
+			Latency: 1500 * time.Microsecond, DNS: 10 * time.Microsecond, Connect: 200 * time.Microsecond,
+			TLS: 300 * time.Microsecond, FirstByte: time.Millisecond,
+			BytesReceived: 4096, BytesSent: 128,
+		},
+		{
+			Timestamp: start.Add(2 * time.Millisecond), Endpoint: "GET /api", WorkerID: 3, StatusCode: 503,
+			Latency: 7 * time.Millisecond, FirstByte: 6 * time.Millisecond, BytesReceived: 11, ConnReused: true,
+			ErrorType: "validation", Error: `状态码 503, "quoted", a,b` + "\nsecond line",
+		},
+		{
+			// 时间戳可以早于上一条 (写入顺序为完成顺序)
+			Timestamp: start.Add(time.Millisecond), Endpoint: "POST /orders", WorkerID: 1000,
+			Latency: 5 * time.Second, ErrorType: "timeout", Error: "context deadline exceeded",
+		},
+	}
+}
+
+// equalRecord 比较两条记录, 时间戳按时刻比较
+func equalRecord(a, b Record) bool {
+	if !a.Timestamp.Equal(b.Timestamp) {
+		return false
+	}
+	a.Timestamp, b.Timestamp = time.Time{}, time.Time{}
+	return a == b
+}
+
+// readAll 读取全部记录直到 io.EOF
+func readAll(t *testing.T, path string) (string, []Record) {
+	t.Helper()
+	reader, err := Open(path)
+	if err != nil {
+		t.Fatalf("打开原始结果失败: %v", err)
+	}
+	defer reader.Close()
+
+	var records []Record
+	for {
+		rec, err := reader.Next()
+		if err == io.EOF {
+			return reader.Format(), records
+		}
+		if err != nil {
+			t.Fatalf("读取第 %d 条记录失败: %v", len(records)+1, err)
+		}
+		records = append(records, rec)
+	}
+}
+
+// TestRoundTrip 测试各格式写入后读回的记录不变, 读取时按内容识别格式
+func TestRoundTrip(t *testing.T) {
+	want := testRecords()
+	// 二进制格式的字符串字典写满后改用字面量
+	var many []Record
+	for i := 0; i < maxDictSize+10; i++ {
+		many = append(many, Record{
+			Timestamp: want[0].Timestamp.Add(time.Duration(i) * time.Microsecond),
+			Endpoint:  "GET /api",
+			Error:     fmt.Sprintf("error %d", i),
+		})
+	}
+	// 长度恰好为上限的字符串
+	long := Record{Timestamp: want[0].Timestamp, Endpoint: strings.Repeat("e", 1<<20)}
+
+	for _, tc := range []struct {
+		name, format string
+		records      []Record
+	}{
+		{"raw.bin", FormatBinary, want},
+		{"raw.csv", FormatCSV, want},
+		{"raw.ndjson", FormatNDJSON, want},
+		{"dict.bin", FormatBinary, many},
+		{"long.bin", FormatBinary, []Record{long, long}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tc.name)
+			w, err := NewWriter(path, "", len(tc.records))
+			if err != nil {
+				t.Fatalf("创建写入器失败: %v", err)
+			}
+			for _, r := range tc.records {
+				w.Write(r)
+			}
+			if err := w.Close(); err != nil {
+				t.Fatalf("关闭写入器失败: %v", err)
+			}
+			if w.Written() != int64(len(tc.records)) || w.Dropped() != 0 {
+				t.Fatalf("写入 %d, 丢弃 %d", w.Written(), w.Dropped())
+			}
+
+			format, got := readAll(t, path)
+			if format != tc.format {
+				t.Errorf("识别的格式 %s, 期望 %s", format, tc.format)
+			}
+			if len(got) != len(tc.records) {
+				t.Fatalf("读回 %d 条记录, 期望 %d", len(got), len(tc.records))
+			}
+			for i := range got {
+				if !equalRecord(got[i], tc.records[i]) {
+					t.Fatalf("第 %d 条记录:\n got %+v\nwant %+v", i, got[i], tc.records[i])
+				}
+			}
+		})
+	}
+}
+
+// TestWriterClosed 测试关闭后写入的记录计为丢弃, 重复关闭无副作用
+func TestWriterClosed(t *testing.T) {
+	w, err := NewWriter(filepath.Join(t.TempDir(), "raw.csv"), "", 0)
+	if err != nil {
+		t.Fatalf("创建写入器失败: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("关闭写入器失败: %v", err)
+	}
+	w.Write(testRecords()[0])
+	if w.Dropped() != 1 || w.Written() != 0 {
+		t.Errorf("写入 %d, 丢弃 %d", w.Written(), w.Dropped())
+	}
+	if err := w.Close(); err != nil {
+		t.Errorf("重复关闭失败: %v", err)
+	}
+
+	if _, err := NewWriter(filepath.Join(t.TempDir(), "raw.out"), "xml", 0); err == nil {
+		t.Error("不支持的格式应报错")
+	}
+}
+
+// TestDetectFormat 测试按扩展名推断格式
+func TestDetectFormat(t *testing.T) {
+	for path, want := range map[string]string{
+		"raw.csv":    FormatCSV,
+		"RAW.CSV":    FormatCSV,
+		"raw.ndjson": FormatNDJSON,
+		"raw.jsonl":  FormatNDJSON,
+		"raw.json":   FormatNDJSON,
+		"raw.bin":    FormatBinary,
+		"raw":        FormatBinary,
+	} {
+		if got := DetectFormat(path); got != want {
+			t.Errorf("DetectFormat(%s) = %s, 期望 %s", path, got, want)
+		}
+	}
+}
+
+// encodeBinary 编码为二进制格式, 返回数据和每条记录结束时的偏移
+func encodeBinary(t *testing.T, records []Record) ([]byte, []int) {
+	t.Helper()
+	var buf bytes.Buffer
+	enc, err := newBinaryEncoder(&buf)
+	if err != nil {
+		t.Fatalf("创建编码器失败: %v", err)
+	}
+	var ends []int
+	for i := range records {
+		if err := enc.Encode(&records[i]); err != nil {
+			t.Fatalf("编码失败: %v", err)
+		}
+		ends = append(ends, buf.Len())
+	}
+	return buf.Bytes(), ends
+}
+
+// decodeBinary 解码到第一个错误, 返回解码出的记录数和该错误
+func decodeBinary(data []byte) (int, error) {
+	dec, err := newBinaryDecoder(bufio.NewReader(bytes.NewReader(data)))
+	if err != nil {
+		return 0, err
+	}
+	for n := 0; ; n++ {
+		var rec Record
+		if err := dec.Decode(&rec); err != nil {
+			return n, err
+		}
+	}
+}
+
+// TestBinaryTruncated 测试在任意位置截断的二进制文件: 记录边界处正常结束, 记录中间报告不完整
+func TestBinaryTruncated(t *testing.T) {
+	data, ends := encodeBinary(t, testRecords())
+
+	for cut := len(binaryMagic); cut <= len(data); cut++ {
+		complete := 0
+		boundary := cut == len(binaryMagic)
+		for _, end := range ends {
+			if end <= cut {
+				complete++
+			}
+			boundary = boundary || end == cut
+		}
+
+		n, err := decodeBinary(data[:cut])
+		if n != complete {
+			t.Fatalf("截断于 %d: 解码出 %d 条记录, 期望 %d", cut, n, complete)
+		}
+		if boundary && err != io.EOF {
+			t.Fatalf("截断于记录边界 %d 应返回 io.EOF, 得到 %v", cut, err)
+		}
+		if !boundary && !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Fatalf("截断于记录中间 %d 应返回 io.ErrUnexpectedEOF, 得到 %v", cut, err)
+		}
+	}
+
+	if _, err := decodeBinary([]byte(binaryMagic[:3])); err == nil {
+		t.Error("不完整的文件头应报错")
+	}
+}
+
+// TestBinaryCorrupt 测试损坏的二进制记录
+func TestBinaryCorrupt(t *testing.T) {
+	record := func(tag uint64, rest ...uint64) []byte {
+		b := []byte(binaryMagic)
+		b = binary.AppendVarint(b, 1)
+		b = binary.AppendUvarint(b, tag)
+		for _, v := range rest {
+			b = binary.AppendUvarint(b, v)
+		}
+		return b
+	}
+
+	for _, tc := range []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"文件头", []byte("HBRAW\x02"), "不是有效的原始结果二进制文件"},
+		{"字典引用", record(strRefBase + 5), "无效的字典引用 5"},
+		{"字面量长度", record(strLiteral, 1<<20+1), "字符串长度异常"},
+		{"字典项长度", record(strDefine, 1<<62), "字符串长度异常"},
+	} {
+		_, err := decodeBinary(tc.data)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: 错误 %v, 期望包含 %q", tc.name, err, tc.want)
+		}
+	}
+}
+
+// TestRebuild 测试由原始结果重建统计和时间序列
+func TestRebuild(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "raw.ndjson")
+	w, err := NewWriter(path, "", 0)
+	if err != nil {
+		t.Fatalf("创建写入器失败: %v", err)
+	}
+	records := testRecords()
+	for _, r := range records {
+		w.Write(r)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("关闭写入器失败: %v", err)
+	}
+
+	reader, err := Open(path)
+	if err != nil {
+		t.Fatalf("打开原始结果失败: %v", err)
+	}
+	defer reader.Close()
+	collector, duration, err := Rebuild(reader, time.Second)
+	if err != nil {
+		t.Fatalf("重建统计失败: %v", err)
+	}
+
+	// 最后一条记录 5s 后完成
+	if want := time.Millisecond + 5*time.Second; duration != want {
+		t.Errorf("测试时长 %v, 期望 %v", duration, want)
+	}
+	snap := collector.Snapshot()
+	if snap.TotalRequests != 3 || snap.SuccessRequests != 1 || snap.StatusCodes[503] != 1 {
+		t.Errorf("请求 %d, 成功 %d, 状态码 %v", snap.TotalRequests, snap.SuccessRequests, snap.StatusCodes)
+	}
+	if snap.ErrorsByType["timeout"] != 1 || snap.ErrorsByType["validation"] != 1 {
+		t.Errorf("错误统计 %v", snap.ErrorsByType)
+	}
+	if series := collector.GetTimeSeries(); len(series) != 6 {
+		t.Errorf("时间序列 %d 个点, 期望 6", len(series))
+	}
+
+	empty := filepath.Join(t.TempDir(), "empty.bin")
+	w, _ = NewWriter(empty, "", 0)
+	w.Close()
+	reader, err = Open(empty)
+	if err != nil {
+		t.Fatalf("打开原始结果失败: %v", err)
+	}
+	defer reader.Close()
+	if _, _, err := Rebuild(reader, time.Second); err == nil {
+		t.Error("空文件应报错")
+	}
+}
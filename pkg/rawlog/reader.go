@@ -0,0 +1,126 @@
+package rawlog
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"httpbench/pkg/stats"
+)
+
+// decoder 记录解码器
+type decoder interface {
+	Decode(r *Record) error
+}
+
+// Reader 原始结果读取器
+type Reader struct {
+	file    *os.File
+	decoder decoder
+	format  string
+}
+
+// Open 打开原始结果文件,根据文件内容自动识别格式
+func Open(path string) (*Reader, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("打开原始结果文件失败: %w", err)
+	}
+
+	br := bufio.NewReaderSize(file, 64*1024)
+	r := &Reader{file: file}
+
+	head, _ := br.Peek(len(binaryMagic))
+	switch {
+	case string(head) == binaryMagic:
+		r.format = FormatBinary
+		r.decoder, err = newBinaryDecoder(br)
+	case len(head) > 0 && head[0] == '{':
+		r.format = FormatNDJSON
+		r.decoder = newNDJSONDecoder(br)
+	default:
+		r.format = FormatCSV
+		r.decoder, err = newCSVDecoder(br)
+	}
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Format 获取文件格式
+func (r *Reader) Format() string {
+	return r.format
+}
+
+// Next 读取下一条记录,读完时返回 io.EOF
+func (r *Reader) Next() (Record, error) {
+	var rec Record
+	err := r.decoder.Decode(&rec)
+	return rec, err
+}
+
+// Close 关闭文件
+func (r *Reader) Close() error {
+	return r.file.Close()
+}
+
+// Rebuild 根据原始结果重建统计,按interval重建时间序列,返回收集器和测试时长
+func Rebuild(r *Reader, interval time.Duration) (*stats.Collector, time.Duration, error) {
+	first, err := r.Next()
+	if err == io.EOF {
+		return nil, 0, fmt.Errorf("原始结果文件为空")
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	start := first.Timestamp
+	collector := stats.NewCollectorAt(start)
+	end := start
+	nextSample := start.Add(interval)
+
+	for rec := first; ; {
+		// 以请求完成时间归入采样间隔 (写入顺序即完成顺序)
+		finished := rec.Timestamp.Add(rec.Latency)
+		for interval > 0 && !finished.Before(nextSample) {
+			collector.SampleAt(nextSample)
+			nextSample = nextSample.Add(interval)
+		}
+		if finished.After(end) {
+			end = finished
+		}
+
+		replay(collector, &rec)
+
+		rec, err = r.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+
+	if interval > 0 {
+		collector.SampleAt(end)
+	}
+
+	return collector, end.Sub(start), nil
+}
+
+// replay 将记录重放到收集器
+func replay(c *stats.Collector, rec *Record) {
+	if rec.ErrorType != "" {
+		c.RecordError(rec.ErrorType, nil)
+	}
+	c.RecordRequest(rec.Latency, rec.BytesReceived, rec.BytesSent, rec.Success)
+	c.RecordEndpoint(rec.Endpoint, rec.StatusCode, rec.Latency, rec.Success)
+	if rec.StatusCode > 0 {
+		c.RecordStatusCode(rec.StatusCode)
+	}
+}
@@ -0,0 +1,196 @@
+package reporter
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"httpbench/pkg/benchmark"
+)
+
+// JUnitReporter JUnit XML报告
+//
+// 每个阈值和每个端点各对应一个testcase,便于CI系统原生展示压测结果。
+type JUnitReporter struct{}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Time      string          `xml:"time,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+func (r *JUnitReporter) Generate(results *benchmark.Results, outputPath string) error {
+	duration := fmt.Sprintf("%.3f", results.Duration.Seconds())
+
+	suites := []junitTestSuite{
+		r.thresholdSuite(results, duration),
+		r.endpointSuite(results, duration),
+	}
+
+	report := junitTestSuites{
+		Name: "httpbench",
+		Time: duration,
+	}
+	for _, suite := range suites {
+		if suite.Tests == 0 {
+			continue
+		}
+		report.Tests += suite.Tests
+		report.Failures += suite.Failures
+		report.Suites = append(report.Suites, suite)
+	}
+
+	data, err := xml.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("JUnit序列化失败: %w", err)
+	}
+	data = append([]byte(xml.Header), data...)
+	data = append(data, '\n')
+
+	if outputPath != "" {
+		if err := os.WriteFile(outputPath, data, 0644); err != nil {
+			return fmt.Errorf("写入文件失败: %w", err)
+		}
+		fmt.Printf("\n🧪 JUnit报告已保存: %s\n", outputPath)
+	} else {
+		fmt.Print(string(data))
+	}
+
+	return nil
+}
+
+// thresholdSuite 阈值检查
+func (r *JUnitReporter) thresholdSuite(results *benchmark.Results, duration string) junitTestSuite {
+	suite := junitTestSuite{Name: "thresholds", Time: duration}
+
+	// 测试被中断时结果不完整
+	if results.Partial {
+		suite.TestCases = append(suite.TestCases, junitTestCase{
+			Name:      "run_completed",
+			ClassName: "httpbench.thresholds",
+			Time:      duration,
+			Failure: &junitFailure{
+				Message: "测试被中断, 结果不完整",
+				Type:    "partial",
+				Text:    fmt.Sprintf("interrupted after %ss, %d in-flight requests canceled", duration, results.Canceled),
+			},
+		})
+		suite.Failures++
+	}
+
+	// 压测客户端成为瓶颈时结果不可信
+	if results.Client != nil && results.Client.Saturated {
+		suite.TestCases = append(suite.TestCases, junitTestCase{
+			Name:      "client_not_saturated",
+			ClassName: "httpbench.thresholds",
+			Time:      duration,
+			Failure: &junitFailure{
+				Message: "压测客户端可能是瓶颈",
+				Type:    "client_saturation",
+				Text:    strings.Join(results.Client.Warnings, "\n"),
+			},
+		})
+		suite.Failures++
+	}
+
+	for _, threshold := range results.Thresholds {
+		tc := junitTestCase{
+			Name:      threshold.Name,
+			ClassName: "httpbench.thresholds",
+			Time:      duration,
+			SystemOut: fmt.Sprintf("limit=%s actual=%s", threshold.Limit, threshold.Actual),
+		}
+		if !threshold.Passed {
+			tc.Failure = &junitFailure{
+				Message: threshold.Message,
+				Type:    "threshold",
+				Text:    fmt.Sprintf("%s: limit %s, actual %s", threshold.Name, threshold.Limit, threshold.Actual),
+			}
+			suite.Failures++
+		}
+		suite.TestCases = append(suite.TestCases, tc)
+	}
+
+	suite.Tests = len(suite.TestCases)
+	return suite
+}
+
+// endpointSuite 端点检查: 存在失败请求即视为失败
+func (r *JUnitReporter) endpointSuite(results *benchmark.Results, duration string) junitTestSuite {
+	suite := junitTestSuite{Name: "endpoints", Time: duration}
+
+	names := make([]string, 0, len(results.Endpoints))
+	for name := range results.Endpoints {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		es := results.Endpoints[name]
+		tc := junitTestCase{
+			Name:      name,
+			ClassName: "httpbench.endpoints",
+			Time:      fmt.Sprintf("%.3f", es.Latency.Mean.Seconds()),
+			SystemOut: fmt.Sprintf("requests=%d success=%d p50=%v p99=%v max=%v",
+				es.TotalRequests, es.SuccessRequests, es.Latency.P50, es.Latency.P99, es.Latency.Max),
+		}
+		if es.FailedRequests > 0 {
+			tc.Failure = &junitFailure{
+				Message: fmt.Sprintf("%d/%d 个请求失败", es.FailedRequests, es.TotalRequests),
+				Type:    "request_failures",
+				Text:    "status codes: " + formatStatusCodes(es.StatusCodes),
+			}
+			suite.Failures++
+		}
+		suite.TestCases = append(suite.TestCases, tc)
+	}
+
+	suite.Tests = len(suite.TestCases)
+	return suite
+}
+
+// formatStatusCodes 格式化状态码分布, 0 表示未收到响应
+func formatStatusCodes(codes map[int]int64) string {
+	keys := make([]int, 0, len(codes))
+	for code := range codes {
+		keys = append(keys, code)
+	}
+	sort.Ints(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, code := range keys {
+		label := fmt.Sprintf("%d", code)
+		if code == 0 {
+			label = "no_response"
+		}
+		parts = append(parts, fmt.Sprintf("%s=%d", label, codes[code]))
+	}
+	return strings.Join(parts, ", ")
+}
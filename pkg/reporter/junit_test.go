@@ -0,0 +1,139 @@
+package reporter
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"httpbench/pkg/benchmark"
+	"httpbench/pkg/stats"
+)
+
+// testResults 含通过和失败阈值、正常和失败端点的测试结果
+func testResults() *benchmark.Results {
+	return &benchmark.Results{
+		TotalRequests:   100,
+		SuccessRequests: 97,
+		FailedRequests:  3,
+		Duration:        10 * time.Second,
+		Throughput:      10,
+		Latency:         stats.LatencyStats{P50: 20 * time.Millisecond, P95: 80 * time.Millisecond, P99: 120 * time.Millisecond, Max: 300 * time.Millisecond},
+		ErrorsByType:    map[string]int64{"status_code": 2, "network": 1},
+		Thresholds: []benchmark.ThresholdResult{
+			{Name: "p99 < 200ms", Limit: "200ms", Actual: "120ms", Passed: true},
+			{Name: "error_rate < 1%", Limit: "1%", Actual: "3%", Passed: false, Message: "error rate 3% exceeds 1%"},
+		},
+		Endpoints: map[string]stats.EndpointStats{
+			"GET /users": {
+				TotalRequests: 60, SuccessRequests: 60,
+				StatusCodes: map[int]int64{200: 60},
+				Latency:     stats.LatencyStats{Mean: 15 * time.Millisecond, P50: 10 * time.Millisecond, P99: 90 * time.Millisecond},
+			},
+			"POST /orders|bulk <v2>": {
+				TotalRequests: 40, SuccessRequests: 37, FailedRequests: 3,
+				StatusCodes: map[int]int64{201: 37, 500: 2, 0: 1},
+				Latency:     stats.LatencyStats{Mean: 30 * time.Millisecond, P50: 25 * time.Millisecond, P99: 150 * time.Millisecond},
+			},
+		},
+	}
+}
+
+// generate 生成报告并返回文件内容
+func generate(t *testing.T, r Reporter, results *benchmark.Results) []byte {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "report")
+	if err := r.Generate(results, path); err != nil {
+		t.Fatalf("生成报告失败: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("读取报告失败: %v", err)
+	}
+	return data
+}
+
+func TestJUnitReport(t *testing.T) {
+	data := generate(t, &JUnitReporter{}, testResults())
+
+	// 格式正确的 XML
+	if !bytes.HasPrefix(data, []byte(xml.Header)) {
+		t.Errorf("缺少 XML 声明")
+	}
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		if _, err := decoder.Token(); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("XML 格式错误: %v\n%s", err, data)
+		}
+	}
+
+	var report junitTestSuites
+	if err := xml.Unmarshal(data, &report); err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	if report.Tests != 4 || report.Failures != 2 || report.Time != "10.000" {
+		t.Errorf("汇总 tests=%d failures=%d time=%s", report.Tests, report.Failures, report.Time)
+	}
+	if len(report.Suites) != 2 || report.Suites[0].Name != "thresholds" || report.Suites[1].Name != "endpoints" {
+		t.Fatalf("测试套件 %+v", report.Suites)
+	}
+
+	// 阈值: 失败的阈值为 <failure>
+	thresholds := report.Suites[0]
+	if thresholds.Tests != 2 || thresholds.Failures != 1 {
+		t.Errorf("阈值套件 tests=%d failures=%d", thresholds.Tests, thresholds.Failures)
+	}
+	if tc := thresholds.TestCases[0]; tc.Failure != nil || tc.SystemOut != "limit=200ms actual=120ms" {
+		t.Errorf("通过的阈值 %+v", tc)
+	}
+	failed := thresholds.TestCases[1]
+	if failed.Failure == nil || failed.Failure.Type != "threshold" || failed.Failure.Message != "error rate 3% exceeds 1%" ||
+		failed.Failure.Text != "error_rate < 1%: limit 1%, actual 3%" {
+		t.Errorf("失败的阈值 %+v", failed.Failure)
+	}
+
+	// 端点: 按名称排序, 有失败请求的端点为 <failure>, 特殊字符经过转义后原样还原
+	endpoints := report.Suites[1]
+	if endpoints.TestCases[0].Name != "GET /users" || endpoints.TestCases[0].Failure != nil {
+		t.Errorf("正常端点 %+v", endpoints.TestCases[0])
+	}
+	bad := endpoints.TestCases[1]
+	if bad.Name != "POST /orders|bulk <v2>" || bad.ClassName != "httpbench.endpoints" || bad.Time != "0.030" {
+		t.Errorf("失败端点 %+v", bad)
+	}
+	if bad.Failure == nil || bad.Failure.Message != "3/40 个请求失败" ||
+		bad.Failure.Text != "status codes: no_response=1, 201=37, 500=2" {
+		t.Errorf("失败端点 %+v", bad.Failure)
+	}
+}
+
+func TestJUnitReportPartial(t *testing.T) {
+	results := testResults()
+	results.Thresholds = nil
+	results.Endpoints = nil
+	results.Partial = true
+	results.Canceled = 5
+	results.Client = &benchmark.ClientStats{Saturated: true, Warnings: []string{"CPU 98%"}}
+
+	var report junitTestSuites
+	if err := xml.Unmarshal(generate(t, &JUnitReporter{}, results), &report); err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	// 没有端点时省略空的测试套件
+	if len(report.Suites) != 1 || report.Tests != 2 || report.Failures != 2 {
+		t.Fatalf("测试套件 %+v", report)
+	}
+	cases := report.Suites[0].TestCases
+	if cases[0].Name != "run_completed" || cases[0].Failure == nil || cases[0].Failure.Type != "partial" ||
+		cases[0].Failure.Text != "interrupted after 10.000s, 5 in-flight requests canceled" {
+		t.Errorf("部分结果 %+v", cases[0])
+	}
+	if cases[1].Name != "client_not_saturated" || cases[1].Failure == nil || cases[1].Failure.Text != "CPU 98%" {
+		t.Errorf("客户端饱和 %+v", cases[1])
+	}
+}
@@ -0,0 +1,135 @@
+package reporter
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"httpbench/pkg/benchmark"
+)
+
+// MarkdownReporter Markdown摘要报告, 适用于PR评论和CI任务摘要
+type MarkdownReporter struct{}
+
+func (r *MarkdownReporter) Generate(results *benchmark.Results, outputPath string) error {
+	content := r.generateMarkdown(results)
+
+	if outputPath != "" {
+		if err := os.WriteFile(outputPath, []byte(content), 0644); err != nil {
+			return fmt.Errorf("写入文件失败: %w", err)
+		}
+		fmt.Printf("\n📝 Markdown报告已保存: %s\n", outputPath)
+	} else {
+		fmt.Print(content)
+	}
+
+	return nil
+}
+
+func (r *MarkdownReporter) generateMarkdown(results *benchmark.Results) string {
+	var sb strings.Builder
+
+	// 防止除以零
+	successRate := 0.0
+	if results.TotalRequests > 0 {
+		successRate = float64(results.SuccessRequests) / float64(results.TotalRequests) * 100
+	}
+
+	passed := results.FailedRequests == 0
+	for _, threshold := range results.Thresholds {
+		passed = passed && threshold.Passed
+	}
+	status := "✅ Passed"
+	if !passed {
+		status = "❌ Failed"
+	}
+
+	fmt.Fprintf(&sb, "## HTTP Benchmark Summary — %s\n\n", status)
+
+	if results.Partial {
+		fmt.Fprintf(&sb, "> ⚠️ **Partial results** — the run was interrupted after %.2fs", results.Duration.Seconds())
+		if results.Canceled > 0 {
+			fmt.Fprintf(&sb, "; %d in-flight requests were canceled and excluded", results.Canceled)
+		}
+		sb.WriteString(".\n\n")
+	}
+
+	if results.Client != nil && results.Client.Saturated {
+		sb.WriteString("> 🚨 **Load generator saturated** — results may reflect the client rather than the target:\n")
+		for _, warning := range results.Client.Warnings {
+			fmt.Fprintf(&sb, "> - %s\n", warning)
+		}
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("| Requests | Success Rate | Throughput | Duration | P50 | P95 | P99 | Max |\n")
+	sb.WriteString("| ---: | ---: | ---: | ---: | ---: | ---: | ---: | ---: |\n")
+	fmt.Fprintf(&sb, "| %d | %.2f%% | %.2f req/s | %.2fs | %v | %v | %v | %v |\n\n",
+		results.TotalRequests, successRate, results.Throughput, results.Duration.Seconds(),
+		results.Latency.P50, results.Latency.P95, results.Latency.P99, results.Latency.Max)
+
+	// 阈值
+	if len(results.Thresholds) > 0 {
+		sb.WriteString("### Thresholds\n\n")
+		sb.WriteString("| | Threshold | Limit | Actual |\n")
+		sb.WriteString("| --- | --- | ---: | ---: |\n")
+		for _, threshold := range results.Thresholds {
+			fmt.Fprintf(&sb, "| %s | `%s` | %s | %s |\n",
+				passMark(threshold.Passed), threshold.Name, threshold.Limit, threshold.Actual)
+		}
+		sb.WriteString("\n")
+	}
+
+	// 端点
+	if len(results.Endpoints) > 0 {
+		names := make([]string, 0, len(results.Endpoints))
+		for name := range results.Endpoints {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		sb.WriteString("### Endpoints\n\n")
+		sb.WriteString("| | Endpoint | Requests | Failed | P50 | P99 | Status Codes |\n")
+		sb.WriteString("| --- | --- | ---: | ---: | ---: | ---: | --- |\n")
+		for _, name := range names {
+			es := results.Endpoints[name]
+			fmt.Fprintf(&sb, "| %s | `%s` | %d | %d | %v | %v | %s |\n",
+				passMark(es.FailedRequests == 0), escapeMarkdown(name), es.TotalRequests,
+				es.FailedRequests, es.Latency.P50, es.Latency.P99, formatStatusCodes(es.StatusCodes))
+		}
+		sb.WriteString("\n")
+	}
+
+	// 错误
+	if len(results.ErrorsByType) > 0 {
+		types := make([]string, 0, len(results.ErrorsByType))
+		for errType := range results.ErrorsByType {
+			types = append(types, errType)
+		}
+		sort.Strings(types)
+
+		sb.WriteString("<details><summary>Errors</summary>\n\n")
+		sb.WriteString("| Type | Count |\n")
+		sb.WriteString("| --- | ---: |\n")
+		for _, errType := range types {
+			fmt.Fprintf(&sb, "| %s | %d |\n", errType, results.ErrorsByType[errType])
+		}
+		sb.WriteString("\n</details>\n")
+	}
+
+	return sb.String()
+}
+
+// passMark 通过/失败标记
+func passMark(passed bool) string {
+	if passed {
+		return "✅"
+	}
+	return "❌"
+}
+
+// escapeMarkdown 转义表格中的竖线
+func escapeMarkdown(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
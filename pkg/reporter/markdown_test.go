@@ -0,0 +1,76 @@
+package reporter
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"httpbench/pkg/benchmark"
+	"httpbench/pkg/stats"
+)
+
+func TestMarkdownReport(t *testing.T) {
+	md := string(generate(t, &MarkdownReporter{}, testResults()))
+
+	for _, want := range []string{
+		"## HTTP Benchmark Summary — ❌ Failed\n",
+		"| 100 | 97.00% | 10.00 req/s | 10.00s | 20ms | 80ms | 120ms | 300ms |\n",
+		"### Thresholds\n",
+		"| ✅ | `p99 < 200ms` | 200ms | 120ms |\n",
+		"| ❌ | `error_rate < 1%` | 1% | 3% |\n",
+		"| ✅ | `GET /users` | 60 | 0 | 10ms | 90ms | 200=60 |\n",
+		// 表格中的竖线需要转义
+		"| ❌ | `POST /orders\\|bulk <v2>` | 40 | 3 | 25ms | 150ms | no_response=1, 201=37, 500=2 |\n",
+		"| network | 1 |\n| status_code | 2 |\n",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("缺少 %q:\n%s", want, md)
+		}
+	}
+	if strings.Contains(md, "Partial results") || strings.Contains(md, "saturated") {
+		t.Errorf("不应有警告:\n%s", md)
+	}
+
+	// 表格每行的列数一致
+	var columns int
+	for _, line := range strings.Split(md, "\n") {
+		if !strings.HasPrefix(line, "| ") && !strings.HasPrefix(line, "| -") {
+			columns = 0
+			continue
+		}
+		n := strings.Count(strings.ReplaceAll(line, `\|`, ""), "|")
+		if columns == 0 {
+			columns = n
+		} else if n != columns {
+			t.Errorf("列数不一致: %q", line)
+		}
+	}
+}
+
+func TestMarkdownReportPassed(t *testing.T) {
+	results := &benchmark.Results{
+		TotalRequests:   10,
+		SuccessRequests: 10,
+		Duration:        time.Second,
+		Partial:         true,
+		Canceled:        2,
+		Client:          &benchmark.ClientStats{Saturated: true, Warnings: []string{"GC 暂停过长"}},
+		Endpoints:       map[string]stats.EndpointStats{},
+	}
+	md := string(generate(t, &MarkdownReporter{}, results))
+
+	for _, want := range []string{
+		"## HTTP Benchmark Summary — ✅ Passed\n",
+		"> ⚠️ **Partial results** — the run was interrupted after 1.00s; 2 in-flight requests were canceled and excluded.\n",
+		"> - GC 暂停过长\n",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("缺少 %q:\n%s", want, md)
+		}
+	}
+	for _, unwanted := range []string{"### Thresholds", "### Endpoints", "<details>"} {
+		if strings.Contains(md, unwanted) {
+			t.Errorf("不应包含 %q:\n%s", unwanted, md)
+		}
+	}
+}
@@ -23,6 +23,10 @@ func New(format string) Reporter {
 		return &JSONReporter{}
 	case "csv":
 		return &CSVReporter{}
+	case "junit":
+		return &JUnitReporter{}
+	case "markdown", "md":
+		return &MarkdownReporter{}
 	default:
 		return &ConsoleReporter{}
 	}
@@ -61,6 +65,8 @@ func (r *JSONReporter) Generate(results *benchmark.Results, outputPath string) e
 			"success_rate":     successRate,
 			"duration_seconds": results.Duration.Seconds(),
 			"throughput_rps":   results.Throughput,
+			"partial":          results.Partial,
+			"canceled":         results.Canceled,
 		},
 		"latency": map[string]interface{}{
 			"min_ms":    results.Latency.Min.Milliseconds(),
@@ -82,6 +88,13 @@ func (r *JSONReporter) Generate(results *benchmark.Results, outputPath string) e
 		},
 		"errors":       results.ErrorsByType,
 		"status_codes": results.StatusCodes,
+		"endpoints":    r.formatEndpoints(results.Endpoints),
+		"thresholds":   results.Thresholds,
+		"client":       r.formatClient(results.Client),
+		"protocol": map[string]interface{}{
+			"name":  results.Protocol,
+			"stats": results.ProtocolStats,
+		},
 		"time_series":  r.formatTimeSeries(results.TimeSeries),
 		"generated_at": time.Now().Format(time.RFC3339),
 	}
@@ -103,14 +116,68 @@ func (r *JSONReporter) Generate(results *benchmark.Results, outputPath string) e
 	return nil
 }
 
+func (r *JSONReporter) formatEndpoints(endpoints map[string]stats.EndpointStats) map[string]interface{} {
+	result := make(map[string]interface{}, len(endpoints))
+	for name, es := range endpoints {
+		result[name] = map[string]interface{}{
+			"total_requests":   es.TotalRequests,
+			"success_requests": es.SuccessRequests,
+			"failed_requests":  es.FailedRequests,
+			"status_codes":     es.StatusCodes,
+			"mean_ms":          es.Latency.Mean.Milliseconds(),
+			"p50_ms":           es.Latency.P50.Milliseconds(),
+			"p90_ms":           es.Latency.P90.Milliseconds(),
+			"p99_ms":           es.Latency.P99.Milliseconds(),
+			"max_ms":           es.Latency.Max.Milliseconds(),
+		}
+	}
+	return result
+}
+
+func (r *JSONReporter) formatClient(cs *benchmark.ClientStats) map[string]interface{} {
+	if cs == nil {
+		return nil
+	}
+	return map[string]interface{}{
+		"cpu_percent":        cs.CPUPercent,
+		"peak_cpu_percent":   cs.PeakCPUPercent,
+		"gomaxprocs":         cs.GOMAXPROCS,
+		"num_gc":             cs.NumGC,
+		"gc_pause_total_ms":  float64(cs.GCPauseTotal.Microseconds()) / 1000,
+		"gc_pause_max_ms":    float64(cs.GCPauseMax.Microseconds()) / 1000,
+		"peak_goroutines":    cs.PeakGoroutines,
+		"peak_open_fds":      cs.PeakOpenFDs,
+		"fd_limit":           cs.FDLimit,
+		"sched_samples":      cs.SchedSamples,
+		"sched_lag_mean_ms":  float64(cs.SchedLagMean.Microseconds()) / 1000,
+		"sched_lag_max_ms":   float64(cs.SchedLagMax.Microseconds()) / 1000,
+		"late_requests":      cs.LateRequests,
+		"queue_wait_mean_ms": float64(cs.QueueWaitMean.Microseconds()) / 1000,
+		"queue_wait_max_ms":  float64(cs.QueueWaitMax.Microseconds()) / 1000,
+		"saturated":          cs.Saturated,
+		"warnings":           cs.Warnings,
+	}
+}
+
 func (r *JSONReporter) formatTimeSeries(series []stats.TimePoint) []map[string]interface{} {
 	result := make([]map[string]interface{}, len(series))
 	for i, point := range series {
 		result[i] = map[string]interface{}{
-			"timestamp":      point.Timestamp.Format(time.RFC3339),
-			"rps":            point.RPS,
-			"avg_latency_ms": point.AvgLatency.Milliseconds(),
-			"error_rate":     point.ErrorRate,
+			"timestamp":        point.Timestamp.Format(time.RFC3339),
+			"interval_seconds": point.Interval.Seconds(),
+			"requests":         point.Requests,
+			"success":          point.Success,
+			"errors":           point.Errors,
+			"rps":              point.RPS,
+			"error_rate":       point.ErrorRate,
+			"bytes_received":   point.BytesReceived,
+			"bytes_sent":       point.BytesSent,
+			"throughput_bps":   point.Throughput,
+			"avg_latency_ms":   point.AvgLatency.Milliseconds(),
+			"p50_latency_ms":   point.P50Latency.Milliseconds(),
+			"p90_latency_ms":   point.P90Latency.Milliseconds(),
+			"p99_latency_ms":   point.P99Latency.Milliseconds(),
+			"max_latency_ms":   point.MaxLatency.Milliseconds(),
 		}
 	}
 	return result
@@ -196,13 +263,23 @@ func (r *CSVReporter) Generate(results *benchmark.Results, outputPath string) er
 
 	// 时间序列数据
 	if len(results.TimeSeries) > 0 {
-		writer.Write([]string{"Timestamp", "RPS", "Avg Latency (ms)", "Error Rate"})
+		writer.Write([]string{"Timestamp", "Requests", "Success", "Errors", "RPS", "Error Rate",
+			"Bytes Received", "Throughput (bytes/s)", "Avg Latency (ms)", "P50 (ms)", "P90 (ms)", "P99 (ms)", "Max (ms)"})
 		for _, point := range results.TimeSeries {
 			writer.Write([]string{
 				point.Timestamp.Format(time.RFC3339),
+				fmt.Sprintf("%d", point.Requests),
+				fmt.Sprintf("%d", point.Success),
+				fmt.Sprintf("%d", point.Errors),
 				fmt.Sprintf("%.2f", point.RPS),
-				fmt.Sprintf("%.2f", float64(point.AvgLatency.Microseconds())/1000),
 				fmt.Sprintf("%.4f", point.ErrorRate),
+				fmt.Sprintf("%d", point.BytesReceived),
+				fmt.Sprintf("%.2f", point.Throughput),
+				fmt.Sprintf("%.2f", float64(point.AvgLatency.Microseconds())/1000),
+				fmt.Sprintf("%.2f", float64(point.P50Latency.Microseconds())/1000),
+				fmt.Sprintf("%.2f", float64(point.P90Latency.Microseconds())/1000),
+				fmt.Sprintf("%.2f", float64(point.P99Latency.Microseconds())/1000),
+				fmt.Sprintf("%.2f", float64(point.MaxLatency.Microseconds())/1000),
 			})
 		}
 	}
@@ -0,0 +1,147 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"httpbench/pkg/config"
+	"httpbench/pkg/stats"
+)
+
+// InfluxSink InfluxDB行协议推送 (HTTP)
+type InfluxSink struct {
+	config   config.SinkConfig
+	writeURL string
+	tags     string
+	client   *http.Client
+}
+
+// NewInfluxSink 创建InfluxDB推送目标
+func NewInfluxSink(cfg config.SinkConfig) *InfluxSink {
+	base := strings.TrimRight(cfg.Address, "/")
+	query := url.Values{}
+	query.Set("precision", "ns")
+
+	var writeURL string
+	if cfg.Bucket != "" {
+		// InfluxDB 2.x
+		query.Set("bucket", cfg.Bucket)
+		if cfg.Org != "" {
+			query.Set("org", cfg.Org)
+		}
+		writeURL = base + "/api/v2/write?" + query.Encode()
+	} else {
+		// InfluxDB 1.x
+		if cfg.Database != "" {
+			query.Set("db", cfg.Database)
+		}
+		writeURL = base + "/write?" + query.Encode()
+	}
+
+	return &InfluxSink{
+		config:   cfg,
+		writeURL: writeURL,
+		tags:     formatInfluxTags(cfg.Tags),
+		client:   &http.Client{},
+	}
+}
+
+// Name 推送目标名称
+func (s *InfluxSink) Name() string {
+	return "influxdb " + s.config.Address
+}
+
+// Send 推送区间指标
+func (s *InfluxSink) Send(ctx context.Context, points []stats.TimePoint) error {
+	var buf bytes.Buffer
+	for _, point := range points {
+		s.writeLine(&buf, point)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.writeURL, &buf)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if s.config.Token != "" {
+		req.Header.Set("Authorization", "Token "+s.config.Token)
+	}
+	for key, value := range s.config.Headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("InfluxDB返回状态码 %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+// writeLine 写出单个数据点的行协议
+func (s *InfluxSink) writeLine(buf *bytes.Buffer, point stats.TimePoint) {
+	buf.WriteString(escapeInflux(s.config.Prefix))
+	buf.WriteString(s.tags)
+	buf.WriteByte(' ')
+
+	fmt.Fprintf(buf, "requests=%di,success=%di,errors=%di,rps=%s,error_rate=%s,"+
+		"bytes_received=%di,bytes_sent=%di,throughput=%s,"+
+		"latency_avg_us=%di,latency_p50_us=%di,latency_p90_us=%di,latency_p99_us=%di,latency_max_us=%di",
+		point.Requests, point.Success, point.Errors,
+		strconv.FormatFloat(point.RPS, 'f', -1, 64),
+		strconv.FormatFloat(point.ErrorRate, 'f', -1, 64),
+		point.BytesReceived, point.BytesSent,
+		strconv.FormatFloat(point.Throughput, 'f', -1, 64),
+		point.AvgLatency.Microseconds(), point.P50Latency.Microseconds(),
+		point.P90Latency.Microseconds(), point.P99Latency.Microseconds(),
+		point.MaxLatency.Microseconds(),
+	)
+
+	buf.WriteByte(' ')
+	buf.WriteString(strconv.FormatInt(point.Timestamp.UnixNano(), 10))
+	buf.WriteByte('\n')
+}
+
+// Close 关闭推送目标
+func (s *InfluxSink) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
+
+// formatInfluxTags 格式化标签集 (按键排序)
+func formatInfluxTags(tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	for _, k := range keys {
+		sb.WriteByte(',')
+		sb.WriteString(escapeInflux(k))
+		sb.WriteByte('=')
+		sb.WriteString(escapeInflux(tags[k]))
+	}
+	return sb.String()
+}
+
+// escapeInflux 转义度量名、标签键和标签值中的特殊字符
+func escapeInflux(s string) string {
+	return influxEscaper.Replace(s)
+}
+
+var influxEscaper = strings.NewReplacer(",", `\,`, "=", `\=`, " ", `\ `)
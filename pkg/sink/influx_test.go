@@ -0,0 +1,85 @@
+package sink
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"httpbench/pkg/config"
+	"httpbench/pkg/stats"
+)
+
+func TestInfluxSink(t *testing.T) {
+	var body, path, auth, custom string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		body, path = string(data), r.URL.String()
+		auth, custom = r.Header.Get("Authorization"), r.Header.Get("X-Custom")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	s := NewInfluxSink(config.SinkConfig{
+		Address: server.URL + "/",
+		Bucket:  "bench",
+		Org:     "acme",
+		Token:   "secret",
+		Prefix:  "http bench,v=1",
+		Tags:    map[string]string{"run id": "a=b,c", "env": "ci"},
+		Headers: map[string]string{"X-Custom": "yes"},
+	})
+	defer s.Close()
+
+	if err := s.Send(context.Background(), []stats.TimePoint{testPoint(0), testPoint(1)}); err != nil {
+		t.Fatalf("推送失败: %v", err)
+	}
+
+	if path != "/api/v2/write?bucket=bench&org=acme&precision=ns" {
+		t.Errorf("写入地址 %s", path)
+	}
+	if auth != "Token secret" || custom != "yes" {
+		t.Errorf("请求头 Authorization=%q X-Custom=%q", auth, custom)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(body, "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("应有 2 行: %q", body)
+	}
+	// 度量名和标签中的空格、逗号和等号需要转义, 标签按键排序
+	wantPrefix := `http\ bench\,v\=1,env=ci,run\ id=a\=b\,c requests=10i,success=9i,errors=1i,rps=10,`
+	if !strings.HasPrefix(lines[0], wantPrefix) {
+		t.Errorf("行协议\n%s\n期望前缀\n%s", lines[0], wantPrefix)
+	}
+	if !strings.Contains(lines[0], ",latency_p99_us=1000i,") {
+		t.Errorf("延迟应为微秒整数: %s", lines[0])
+	}
+	// 时间戳为纳秒
+	if !strings.HasSuffix(lines[0], " 1700000000000000000") || !strings.HasSuffix(lines[1], " 1700000001000000000") {
+		t.Errorf("时间戳错误:\n%s", body)
+	}
+
+	// 1.x 接口
+	v1 := NewInfluxSink(config.SinkConfig{Address: server.URL, Database: "bench", Prefix: "m"})
+	if err := v1.Send(context.Background(), []stats.TimePoint{testPoint(0)}); err != nil {
+		t.Fatalf("推送失败: %v", err)
+	}
+	if path != "/write?db=bench&precision=ns" {
+		t.Errorf("1.x 写入地址 %s", path)
+	}
+}
+
+func TestInfluxSinkError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "database not found", http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	s := NewInfluxSink(config.SinkConfig{Address: server.URL, Prefix: "m"})
+	err := s.Send(context.Background(), []stats.TimePoint{testPoint(0)})
+	if err == nil || !strings.Contains(err.Error(), "404") || !strings.Contains(err.Error(), "database not found") {
+		t.Errorf("错误应包含状态码和响应内容: %v", err)
+	}
+}
@@ -0,0 +1,239 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"httpbench/pkg/config"
+	"httpbench/pkg/stats"
+)
+
+// OTLP 聚合时间性: 区间增量
+const otlpTemporalityDelta = 1
+
+// OTLPSink OpenTelemetry OTLP/HTTP指标推送 (JSON编码)
+type OTLPSink struct {
+	config   config.SinkConfig
+	endpoint string
+	resource otlpResource
+	client   *http.Client
+}
+
+// NewOTLPSink 创建OTLP推送目标
+func NewOTLPSink(cfg config.SinkConfig) *OTLPSink {
+	endpoint := strings.TrimRight(cfg.Address, "/")
+	if !strings.HasSuffix(endpoint, "/v1/metrics") {
+		endpoint += "/v1/metrics"
+	}
+
+	attrs := []otlpAttribute{stringAttribute("service.name", cfg.Prefix)}
+	keys := make([]string, 0, len(cfg.Tags))
+	for k := range cfg.Tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		attrs = append(attrs, stringAttribute(k, cfg.Tags[k]))
+	}
+
+	return &OTLPSink{
+		config:   cfg,
+		endpoint: endpoint,
+		resource: otlpResource{Attributes: attrs},
+		client:   &http.Client{},
+	}
+}
+
+// Name 推送目标名称
+func (s *OTLPSink) Name() string {
+	return "otlp " + s.endpoint
+}
+
+// Send 推送区间指标
+func (s *OTLPSink) Send(ctx context.Context, points []stats.TimePoint) error {
+	data, err := json.Marshal(s.buildRequest(points))
+	if err != nil {
+		return fmt.Errorf("OTLP序列化失败: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range s.config.Headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("OTLP接收端返回状态码 %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+// Close 关闭推送目标
+func (s *OTLPSink) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
+
+// buildRequest 构建ExportMetricsServiceRequest
+func (s *OTLPSink) buildRequest(points []stats.TimePoint) otlpRequest {
+	prefix := s.config.Prefix + "."
+
+	counters := []struct {
+		name, unit string
+		value      func(stats.TimePoint) int64
+	}{
+		{"requests", "{request}", func(p stats.TimePoint) int64 { return p.Requests }},
+		{"success", "{request}", func(p stats.TimePoint) int64 { return p.Success }},
+		{"errors", "{request}", func(p stats.TimePoint) int64 { return p.Errors }},
+		{"bytes_received", "By", func(p stats.TimePoint) int64 { return p.BytesReceived }},
+		{"bytes_sent", "By", func(p stats.TimePoint) int64 { return p.BytesSent }},
+	}
+	gauges := []struct {
+		name, unit string
+		value      func(stats.TimePoint) float64
+	}{
+		{"rps", "{request}/s", func(p stats.TimePoint) float64 { return p.RPS }},
+		{"error_rate", "1", func(p stats.TimePoint) float64 { return p.ErrorRate }},
+		{"throughput", "By/s", func(p stats.TimePoint) float64 { return p.Throughput }},
+		{"latency.avg", "ms", func(p stats.TimePoint) float64 { return millis(p.AvgLatency.Microseconds()) }},
+		{"latency.p50", "ms", func(p stats.TimePoint) float64 { return millis(p.P50Latency.Microseconds()) }},
+		{"latency.p90", "ms", func(p stats.TimePoint) float64 { return millis(p.P90Latency.Microseconds()) }},
+		{"latency.p99", "ms", func(p stats.TimePoint) float64 { return millis(p.P99Latency.Microseconds()) }},
+		{"latency.max", "ms", func(p stats.TimePoint) float64 { return millis(p.MaxLatency.Microseconds()) }},
+	}
+
+	metrics := make([]otlpMetric, 0, len(counters)+len(gauges))
+	for _, c := range counters {
+		dataPoints := make([]otlpDataPoint, len(points))
+		for i, point := range points {
+			value := strconv.FormatInt(c.value(point), 10)
+			dataPoints[i] = otlpDataPoint{
+				StartTimeUnixNano: unixNano(point.Timestamp.Add(-point.Interval).UnixNano()),
+				TimeUnixNano:      unixNano(point.Timestamp.UnixNano()),
+				AsInt:             &value,
+			}
+		}
+		metrics = append(metrics, otlpMetric{
+			Name: prefix + c.name,
+			Unit: c.unit,
+			Sum: &otlpSum{
+				AggregationTemporality: otlpTemporalityDelta,
+				IsMonotonic:            true,
+				DataPoints:             dataPoints,
+			},
+		})
+	}
+	for _, g := range gauges {
+		dataPoints := make([]otlpDataPoint, len(points))
+		for i, point := range points {
+			value := g.value(point)
+			dataPoints[i] = otlpDataPoint{
+				TimeUnixNano: unixNano(point.Timestamp.UnixNano()),
+				AsDouble:     &value,
+			}
+		}
+		metrics = append(metrics, otlpMetric{
+			Name:  prefix + g.name,
+			Unit:  g.unit,
+			Gauge: &otlpGauge{DataPoints: dataPoints},
+		})
+	}
+
+	return otlpRequest{
+		ResourceMetrics: []otlpResourceMetrics{{
+			Resource: s.resource,
+			ScopeMetrics: []otlpScopeMetrics{{
+				Scope:   otlpScope{Name: "httpbench"},
+				Metrics: metrics,
+			}},
+		}},
+	}
+}
+
+// millis 将微秒转换为毫秒
+func millis(us int64) float64 {
+	return float64(us) / 1000
+}
+
+// unixNano OTLP JSON中64位整数以字符串表示
+func unixNano(ns int64) string {
+	return strconv.FormatInt(ns, 10)
+}
+
+func stringAttribute(key, value string) otlpAttribute {
+	return otlpAttribute{Key: key, Value: otlpAnyValue{StringValue: value}}
+}
+
+// OTLP JSON 结构 (opentelemetry-proto metrics/v1)
+type otlpRequest struct {
+	ResourceMetrics []otlpResourceMetrics `json:"resourceMetrics"`
+}
+
+type otlpResourceMetrics struct {
+	Resource     otlpResource       `json:"resource"`
+	ScopeMetrics []otlpScopeMetrics `json:"scopeMetrics"`
+}
+
+type otlpResource struct {
+	Attributes []otlpAttribute `json:"attributes"`
+}
+
+type otlpAttribute struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpAnyValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+type otlpScopeMetrics struct {
+	Scope   otlpScope    `json:"scope"`
+	Metrics []otlpMetric `json:"metrics"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpMetric struct {
+	Name  string     `json:"name"`
+	Unit  string     `json:"unit,omitempty"`
+	Sum   *otlpSum   `json:"sum,omitempty"`
+	Gauge *otlpGauge `json:"gauge,omitempty"`
+}
+
+type otlpSum struct {
+	AggregationTemporality int             `json:"aggregationTemporality"`
+	IsMonotonic            bool            `json:"isMonotonic"`
+	DataPoints             []otlpDataPoint `json:"dataPoints"`
+}
+
+type otlpGauge struct {
+	DataPoints []otlpDataPoint `json:"dataPoints"`
+}
+
+type otlpDataPoint struct {
+	StartTimeUnixNano string   `json:"startTimeUnixNano,omitempty"`
+	TimeUnixNano      string   `json:"timeUnixNano"`
+	AsInt             *string  `json:"asInt,omitempty"`
+	AsDouble          *float64 `json:"asDouble,omitempty"`
+}
@@ -0,0 +1,94 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"httpbench/pkg/config"
+	"httpbench/pkg/stats"
+)
+
+func TestOTLPSink(t *testing.T) {
+	var body map[string]interface{}
+	var path, contentType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, contentType = r.URL.Path, r.Header.Get("Content-Type")
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("请求体不是 JSON: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	s := NewOTLPSink(config.SinkConfig{Address: server.URL, Prefix: "httpbench", Tags: map[string]string{"env": "ci"}})
+	defer s.Close()
+	if err := s.Send(context.Background(), []stats.TimePoint{testPoint(0), testPoint(1)}); err != nil {
+		t.Fatalf("推送失败: %v", err)
+	}
+	if path != "/v1/metrics" || contentType != "application/json" {
+		t.Errorf("路径 %s, Content-Type %s", path, contentType)
+	}
+
+	resourceMetrics := body["resourceMetrics"].([]interface{})[0].(map[string]interface{})
+	attrs := resourceMetrics["resource"].(map[string]interface{})["attributes"].([]interface{})
+	wantAttrs := [][2]string{{"service.name", "httpbench"}, {"env", "ci"}}
+	if len(attrs) != len(wantAttrs) {
+		t.Fatalf("资源属性 %v", attrs)
+	}
+	for i, want := range wantAttrs {
+		attr := attrs[i].(map[string]interface{})
+		if attr["key"] != want[0] || attr["value"].(map[string]interface{})["stringValue"] != want[1] {
+			t.Errorf("资源属性 %d: %v", i, attr)
+		}
+	}
+
+	scope := resourceMetrics["scopeMetrics"].([]interface{})[0].(map[string]interface{})
+	if scope["scope"].(map[string]interface{})["name"] != "httpbench" {
+		t.Errorf("scope %v", scope["scope"])
+	}
+	metrics := make(map[string]map[string]interface{})
+	for _, m := range scope["metrics"].([]interface{}) {
+		metric := m.(map[string]interface{})
+		metrics[metric["name"].(string)] = metric
+	}
+	if len(metrics) != 13 {
+		t.Errorf("指标数 %d", len(metrics))
+	}
+
+	// 计数为增量的单调 Sum, 64 位整数以字符串表示, 起始时间为间隔开始
+	requests := metrics["httpbench.requests"]["sum"].(map[string]interface{})
+	if requests["aggregationTemporality"] != float64(otlpTemporalityDelta) || requests["isMonotonic"] != true {
+		t.Errorf("requests 应为增量单调 Sum: %v", requests)
+	}
+	points := requests["dataPoints"].([]interface{})
+	first := points[0].(map[string]interface{})
+	if len(points) != 2 || first["asInt"] != "10" ||
+		first["startTimeUnixNano"] != "1699999999000000000" || first["timeUnixNano"] != "1700000000000000000" {
+		t.Errorf("requests 数据点 %v", points)
+	}
+
+	// 速率和延迟为 Gauge
+	p99 := metrics["httpbench.latency.p99"]
+	if p99["unit"] != "ms" || p99["sum"] != nil {
+		t.Errorf("latency.p99 %v", p99)
+	}
+	gaugePoints := p99["gauge"].(map[string]interface{})["dataPoints"].([]interface{})
+	if last := gaugePoints[1].(map[string]interface{}); last["asDouble"] != 2.0 || last["startTimeUnixNano"] != nil {
+		t.Errorf("latency.p99 数据点 %v", gaugePoints)
+	}
+}
+
+func TestOTLPSinkEndpoint(t *testing.T) {
+	for address, want := range map[string]string{
+		"http://collector:4318":            "http://collector:4318/v1/metrics",
+		"http://collector:4318/":           "http://collector:4318/v1/metrics",
+		"http://collector:4318/v1/metrics": "http://collector:4318/v1/metrics",
+		"https://otel.example.com/otlp/":   "https://otel.example.com/otlp/v1/metrics",
+	} {
+		if got := NewOTLPSink(config.SinkConfig{Address: address}).endpoint; got != want {
+			t.Errorf("%s: 推送地址 %s, 期望 %s", address, got, want)
+		}
+	}
+}
@@ -0,0 +1,253 @@
+package sink
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"httpbench/pkg/config"
+	"httpbench/pkg/stats"
+)
+
+// 默认批量与容错参数
+const (
+	defaultBatchSize     = 10
+	defaultFlushInterval = 5 * time.Second
+	defaultTimeout       = 5 * time.Second
+	defaultQueueSize     = 1024
+	defaultPrefix        = "httpbench"
+
+	// maxPending 推送失败时最多保留等待重试的点数
+	maxPending = 1000
+)
+
+// Sink 区间指标推送目标
+type Sink interface {
+	Name() string
+	Send(ctx context.Context, points []stats.TimePoint) error
+	Close() error
+}
+
+// New 根据配置创建推送目标
+func New(cfg config.SinkConfig) (Sink, error) {
+	if cfg.Address == "" {
+		return nil, fmt.Errorf("指标推送目标 %s 缺少地址", cfg.Type)
+	}
+	if cfg.Prefix == "" {
+		cfg.Prefix = defaultPrefix
+	}
+
+	switch cfg.Type {
+	case "influxdb", "influx":
+		return NewInfluxSink(cfg), nil
+	case "statsd":
+		return NewStatsDSink(cfg, false)
+	case "dogstatsd":
+		return NewStatsDSink(cfg, true)
+	case "otlp":
+		return NewOTLPSink(cfg), nil
+	default:
+		return nil, fmt.Errorf("不支持的指标推送类型: %s", cfg.Type)
+	}
+}
+
+// Dispatcher 指标分发器
+//
+// 每个推送目标拥有独立的队列和协程,Push 从不阻塞:队列满时丢弃并计数,
+// 推送失败的数据在限额内保留到下次批量重试。
+type Dispatcher struct {
+	workers []*sinkWorker
+	logf    func(format string, args ...interface{})
+	wg      sync.WaitGroup
+	mu      sync.RWMutex
+	closed  bool
+}
+
+// sinkWorker 单个推送目标的批量推送协程
+type sinkWorker struct {
+	sink          Sink
+	queue         chan stats.TimePoint
+	batchSize     int
+	flushInterval time.Duration
+	timeout       time.Duration
+	logf          func(format string, args ...interface{})
+
+	pending  []stats.TimePoint
+	failing  bool
+	dropped  atomic.Int64
+	failures atomic.Int64
+}
+
+// NewDispatcher 根据配置创建分发器, 推送失败、恢复和丢弃的提示通过 logf 输出 (nil 时输出到标准输出)
+func NewDispatcher(configs []config.SinkConfig, logf func(format string, args ...interface{})) (*Dispatcher, error) {
+	d := &Dispatcher{logf: logf}
+
+	for _, cfg := range configs {
+		s, err := New(cfg)
+		if err != nil {
+			d.Close()
+			return nil, err
+		}
+		d.Add(s, cfg)
+	}
+
+	return d, nil
+}
+
+// Add 添加推送目标
+func (d *Dispatcher) Add(s Sink, cfg config.SinkConfig) {
+	w := &sinkWorker{
+		sink:          s,
+		batchSize:     cfg.BatchSize,
+		flushInterval: cfg.FlushInterval,
+		timeout:       cfg.Timeout,
+		logf:          d.logf,
+	}
+	if w.batchSize <= 0 {
+		w.batchSize = defaultBatchSize
+	}
+	if w.flushInterval <= 0 {
+		w.flushInterval = defaultFlushInterval
+	}
+	if w.timeout <= 0 {
+		w.timeout = defaultTimeout
+	}
+	queueSize := cfg.QueueSize
+	if queueSize <= 0 {
+		queueSize = defaultQueueSize
+	}
+	w.queue = make(chan stats.TimePoint, queueSize)
+
+	d.workers = append(d.workers, w)
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		w.run()
+	}()
+}
+
+// Len 获取推送目标数量
+func (d *Dispatcher) Len() int {
+	return len(d.workers)
+}
+
+// Push 分发区间指标,不会阻塞调用方
+func (d *Dispatcher) Push(point stats.TimePoint) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if d.closed {
+		return
+	}
+	for _, w := range d.workers {
+		select {
+		case w.queue <- point:
+		default:
+			w.dropped.Add(1)
+		}
+	}
+}
+
+// Dropped 获取各推送目标因队列满或重试超限丢弃的点数
+func (d *Dispatcher) Dropped() map[string]int64 {
+	result := make(map[string]int64, len(d.workers))
+	for _, w := range d.workers {
+		result[w.sink.Name()] += w.dropped.Load()
+	}
+	return result
+}
+
+// Close 推送剩余数据并关闭所有推送目标
+func (d *Dispatcher) Close() error {
+	d.mu.Lock()
+	if d.closed {
+		d.mu.Unlock()
+		return nil
+	}
+	d.closed = true
+	for _, w := range d.workers {
+		close(w.queue)
+	}
+	d.mu.Unlock()
+
+	d.wg.Wait()
+
+	var firstErr error
+	for _, w := range d.workers {
+		if err := w.sink.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		if dropped := w.dropped.Load(); dropped > 0 {
+			w.printf("⚠️  指标推送 [%s] 共丢弃 %d 个数据点 (失败 %d 次)\n",
+				w.sink.Name(), dropped, w.failures.Load())
+		}
+	}
+	return firstErr
+}
+
+// printf 输出提示, 未设置 logf 时输出到标准输出
+func (w *sinkWorker) printf(format string, args ...interface{}) {
+	if w.logf == nil {
+		fmt.Printf(format, args...)
+		return
+	}
+	w.logf(format, args...)
+}
+
+// run 批量推送循环
+func (w *sinkWorker) run() {
+	ticker := time.NewTicker(w.flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case point, ok := <-w.queue:
+			if !ok {
+				w.flush()
+				w.dropped.Add(int64(len(w.pending)))
+				return
+			}
+			w.pending = append(w.pending, point)
+			// 失败期间只按刷新间隔重试,避免频繁请求故障目标
+			if len(w.pending) >= w.batchSize && !w.failing {
+				w.flush()
+			}
+		case <-ticker.C:
+			w.flush()
+		}
+	}
+}
+
+// flush 推送待发送数据,失败时保留到下次重试
+func (w *sinkWorker) flush() {
+	if len(w.pending) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), w.timeout)
+	err := w.sink.Send(ctx, w.pending)
+	cancel()
+
+	if err == nil {
+		if w.failing {
+			w.printf("✓ 指标推送 [%s] 已恢复\n", w.sink.Name())
+			w.failing = false
+		}
+		w.pending = w.pending[:0]
+		return
+	}
+
+	w.failures.Add(1)
+	if !w.failing {
+		w.printf("⚠️  指标推送 [%s] 失败,稍后重试: %v\n", w.sink.Name(), err)
+		w.failing = true
+	}
+
+	// 超出重试限额时丢弃最早的数据
+	if overflow := len(w.pending) - maxPending; overflow > 0 {
+		w.dropped.Add(int64(overflow))
+		w.pending = append(w.pending[:0], w.pending[overflow:]...)
+	}
+}
@@ -0,0 +1,189 @@
+package sink
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"httpbench/pkg/config"
+	"httpbench/pkg/stats"
+)
+
+// testPoint 第 i 个间隔的数据点
+func testPoint(i int) stats.TimePoint {
+	return stats.TimePoint{
+		Timestamp:  time.Unix(1700000000+int64(i), 0),
+		Interval:   time.Second,
+		Requests:   int64(10 + i),
+		Success:    int64(9 + i),
+		Errors:     1,
+		RPS:        float64(10 + i),
+		P99Latency: time.Duration(i+1) * time.Millisecond,
+	}
+}
+
+// fakeSink 记录收到的数据点, 可以阻塞或让推送失败
+type fakeSink struct {
+	mu     sync.Mutex
+	points []stats.TimePoint
+	sends  int
+
+	// 非 nil 时每次推送先等待该通道
+	block   chan struct{}
+	entered chan struct{}
+	// 前 fail 次推送失败
+	fail int
+}
+
+func (s *fakeSink) Name() string { return "fake" }
+
+func (s *fakeSink) Send(ctx context.Context, points []stats.TimePoint) error {
+	if s.entered != nil {
+		select {
+		case s.entered <- struct{}{}:
+		default:
+		}
+	}
+	if s.block != nil {
+		<-s.block
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sends++
+	if s.sends <= s.fail {
+		return errors.New("unavailable")
+	}
+	s.points = append(s.points, points...)
+	return nil
+}
+
+func (s *fakeSink) Close() error { return nil }
+
+func (s *fakeSink) received() []stats.TimePoint {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]stats.TimePoint(nil), s.points...)
+}
+
+func TestDispatcherDropsWhenSinkBlocks(t *testing.T) {
+	sink := &fakeSink{block: make(chan struct{}), entered: make(chan struct{}, 1)}
+	d := &Dispatcher{}
+	d.Add(sink, config.SinkConfig{BatchSize: 1, QueueSize: 2, FlushInterval: time.Hour})
+
+	// 第一个点被取出后推送阻塞, 之后只有队列中的 2 个点能保留
+	d.Push(testPoint(0))
+	<-sink.entered
+
+	done := make(chan struct{})
+	go func() {
+		for i := 1; i <= 5; i++ {
+			d.Push(testPoint(i))
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("推送目标阻塞时 Push 不应阻塞")
+	}
+	if dropped := d.Dropped()["fake"]; dropped != 3 {
+		t.Errorf("丢弃 %d 个点, 期望 3", dropped)
+	}
+
+	close(sink.block)
+	if err := d.Close(); err != nil {
+		t.Fatalf("关闭失败: %v", err)
+	}
+	got := sink.received()
+	if len(got) != 3 || got[0].Requests != 10 || got[1].Requests != 11 || got[2].Requests != 12 {
+		t.Errorf("收到 %d 个点: %v", len(got), got)
+	}
+
+	// 关闭后的点直接忽略
+	d.Push(testPoint(9))
+	if dropped := d.Dropped()["fake"]; dropped != 3 {
+		t.Errorf("关闭后丢弃计数变为 %d", dropped)
+	}
+}
+
+func TestDispatcherBatchesAndRetries(t *testing.T) {
+	sink := &fakeSink{fail: 2}
+	var logs strings.Builder
+	d := &Dispatcher{logf: func(format string, args ...interface{}) {
+		fmt.Fprintf(&logs, format, args...)
+	}}
+	d.Add(sink, config.SinkConfig{BatchSize: 3, FlushInterval: 10 * time.Millisecond})
+
+	for i := 0; i < 6; i++ {
+		d.Push(testPoint(i))
+	}
+
+	// 失败期间数据保留, 按刷新间隔重试直到成功
+	deadline := time.Now().Add(2 * time.Second)
+	for len(sink.received()) < 6 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	d.Close()
+
+	got := sink.received()
+	if len(got) != 6 {
+		t.Fatalf("收到 %d 个点, 期望 6", len(got))
+	}
+	for i, point := range got {
+		if point.Requests != int64(10+i) {
+			t.Errorf("第 %d 个点顺序错误: %d", i, point.Requests)
+		}
+	}
+	if dropped := d.Dropped()["fake"]; dropped != 0 {
+		t.Errorf("重试成功后不应丢弃: %d", dropped)
+	}
+	// 失败和恢复只各提示一次, 通过 logf 输出
+	if log := logs.String(); strings.Count(log, "失败") != 1 || strings.Count(log, "已恢复") != 1 {
+		t.Errorf("提示输出 %q", log)
+	}
+}
+
+func TestSinkWorkerPendingLimit(t *testing.T) {
+	w := &sinkWorker{sink: &fakeSink{fail: 1}, timeout: time.Second}
+	for i := 0; i < maxPending+100; i++ {
+		w.pending = append(w.pending, testPoint(i))
+	}
+
+	// 失败后超出重试限额的最早数据被丢弃
+	w.flush()
+	if len(w.pending) != maxPending || w.dropped.Load() != 100 || w.failures.Load() != 1 {
+		t.Fatalf("保留 %d 个点, 丢弃 %d, 失败 %d", len(w.pending), w.dropped.Load(), w.failures.Load())
+	}
+	if w.pending[0].Requests != testPoint(100).Requests {
+		t.Errorf("应丢弃最早的点, 剩余第一个为 %d", w.pending[0].Requests)
+	}
+	if !w.failing {
+		t.Errorf("失败后应处于重试状态")
+	}
+
+	w.flush()
+	if len(w.pending) != 0 || w.failing {
+		t.Errorf("恢复后应清空待发送数据: %d", len(w.pending))
+	}
+}
+
+func TestNew(t *testing.T) {
+	if _, err := New(config.SinkConfig{Type: "influxdb"}); err == nil {
+		t.Errorf("缺少地址应报错")
+	}
+	if _, err := New(config.SinkConfig{Type: "graphite", Address: "localhost:2003"}); err == nil {
+		t.Errorf("不支持的类型应报错")
+	}
+	s, err := New(config.SinkConfig{Type: "otlp", Address: "http://collector:4318"})
+	if err != nil {
+		t.Fatalf("创建失败: %v", err)
+	}
+	if s.(*OTLPSink).config.Prefix != defaultPrefix {
+		t.Errorf("默认前缀 %q", s.(*OTLPSink).config.Prefix)
+	}
+}
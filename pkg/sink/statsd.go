@@ -0,0 +1,141 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+
+	"httpbench/pkg/config"
+	"httpbench/pkg/stats"
+)
+
+// statsdMaxPacket 单个UDP包的最大负载,避免IP分片
+const statsdMaxPacket = 1432
+
+// StatsDSink StatsD/DogStatsD推送 (UDP)
+type StatsDSink struct {
+	config config.SinkConfig
+	conn   net.Conn
+	tags   string
+	dog    bool
+}
+
+// NewStatsDSink 创建StatsD推送目标, dog为true时使用DogStatsD标签扩展
+func NewStatsDSink(cfg config.SinkConfig, dog bool) (*StatsDSink, error) {
+	conn, err := net.Dial("udp", cfg.Address)
+	if err != nil {
+		return nil, fmt.Errorf("连接StatsD失败: %w", err)
+	}
+
+	s := &StatsDSink{
+		config: cfg,
+		conn:   conn,
+		dog:    dog,
+	}
+	if dog && len(cfg.Tags) > 0 {
+		s.tags = formatDogTags(cfg.Tags)
+	}
+
+	return s, nil
+}
+
+// Name 推送目标名称
+func (s *StatsDSink) Name() string {
+	if s.dog {
+		return "dogstatsd " + s.config.Address
+	}
+	return "statsd " + s.config.Address
+}
+
+// Send 推送区间指标,计数使用counter,速率和延迟使用gauge
+//
+// StatsD没有时间戳,同一批中较早的gauge会被后面的覆盖,因此计数累加整批,gauge只取最新的点。
+func (s *StatsDSink) Send(ctx context.Context, points []stats.TimePoint) error {
+	if len(points) == 0 {
+		return nil
+	}
+
+	var total stats.TimePoint
+	for _, point := range points {
+		total.Requests += point.Requests
+		total.Success += point.Success
+		total.Errors += point.Errors
+		total.BytesReceived += point.BytesReceived
+		total.BytesSent += point.BytesSent
+	}
+	latest := points[len(points)-1]
+
+	lines := []string{
+		s.line("requests", strconv.FormatInt(total.Requests, 10), "c"),
+		s.line("success", strconv.FormatInt(total.Success, 10), "c"),
+		s.line("errors", strconv.FormatInt(total.Errors, 10), "c"),
+		s.line("bytes_received", strconv.FormatInt(total.BytesReceived, 10), "c"),
+		s.line("bytes_sent", strconv.FormatInt(total.BytesSent, 10), "c"),
+		s.line("rps", strconv.FormatFloat(latest.RPS, 'f', 2, 64), "g"),
+		s.line("error_rate", strconv.FormatFloat(latest.ErrorRate, 'f', 4, 64), "g"),
+		s.line("throughput", strconv.FormatFloat(latest.Throughput, 'f', 2, 64), "g"),
+		s.line("latency.avg", formatMillis(latest.AvgLatency.Microseconds()), "g"),
+		s.line("latency.p50", formatMillis(latest.P50Latency.Microseconds()), "g"),
+		s.line("latency.p90", formatMillis(latest.P90Latency.Microseconds()), "g"),
+		s.line("latency.p99", formatMillis(latest.P99Latency.Microseconds()), "g"),
+		s.line("latency.max", formatMillis(latest.MaxLatency.Microseconds()), "g"),
+	}
+
+	var packet bytes.Buffer
+	for _, line := range lines {
+		if packet.Len() > 0 && packet.Len()+len(line)+1 > statsdMaxPacket {
+			if err := s.write(ctx, packet.Bytes()); err != nil {
+				return err
+			}
+			packet.Reset()
+		}
+		if packet.Len() > 0 {
+			packet.WriteByte('\n')
+		}
+		packet.WriteString(line)
+	}
+	return s.write(ctx, packet.Bytes())
+}
+
+// line 格式化单条指标
+func (s *StatsDSink) line(name, value, metricType string) string {
+	return s.config.Prefix + "." + name + ":" + value + "|" + metricType + s.tags
+}
+
+// write 发送UDP包
+func (s *StatsDSink) write(ctx context.Context, packet []byte) error {
+	if deadline, ok := ctx.Deadline(); ok {
+		s.conn.SetWriteDeadline(deadline)
+	}
+	_, err := s.conn.Write(packet)
+	return err
+}
+
+// Close 关闭推送目标
+func (s *StatsDSink) Close() error {
+	return s.conn.Close()
+}
+
+// formatDogTags 格式化DogStatsD标签 (按键排序)
+func formatDogTags(tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, k+":"+tags[k])
+	}
+	return "|#" + strings.Join(parts, ",")
+}
+
+// formatMillis 将微秒格式化为毫秒
+func formatMillis(us int64) string {
+	return strconv.FormatFloat(float64(us)/1000, 'f', 3, 64)
+}
@@ -0,0 +1,120 @@
+package sink
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"httpbench/pkg/config"
+	"httpbench/pkg/stats"
+)
+
+// listenStatsD 接收 UDP 包
+func listenStatsD(t *testing.T) (net.PacketConn, func() []string) {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("监听失败: %v", err)
+	}
+	read := func() []string {
+		var packets []string
+		buf := make([]byte, 65536)
+		for {
+			conn.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+			n, _, err := conn.ReadFrom(buf)
+			if err != nil {
+				return packets
+			}
+			packets = append(packets, string(buf[:n]))
+		}
+	}
+	return conn, read
+}
+
+func TestStatsDSink(t *testing.T) {
+	conn, read := listenStatsD(t)
+	defer conn.Close()
+
+	s, err := NewStatsDSink(config.SinkConfig{Address: conn.LocalAddr().String(), Prefix: "hb"}, false)
+	if err != nil {
+		t.Fatalf("创建失败: %v", err)
+	}
+	defer s.Close()
+
+	// 一批多个点: 计数累加, gauge 只取最新的点
+	if err := s.Send(context.Background(), []stats.TimePoint{testPoint(0), testPoint(1), testPoint(2)}); err != nil {
+		t.Fatalf("推送失败: %v", err)
+	}
+	packets := read()
+	if len(packets) != 1 {
+		t.Fatalf("应只有 1 个包: %q", packets)
+	}
+	lines := strings.Split(packets[0], "\n")
+	want := map[string]bool{
+		"hb.requests:33|c":       true,
+		"hb.errors:3|c":          true,
+		"hb.rps:12.00|g":         true,
+		"hb.latency.p99:3.000|g": true,
+	}
+	count := make(map[string]int)
+	for _, line := range lines {
+		name := line[:strings.IndexByte(line, ':')]
+		count[name]++
+		delete(want, line)
+	}
+	if len(want) != 0 {
+		t.Errorf("缺少 %v:\n%s", want, packets[0])
+	}
+	for name, n := range count {
+		if n != 1 {
+			t.Errorf("%s 出现 %d 次", name, n)
+		}
+	}
+	if len(lines) != 13 {
+		t.Errorf("指标数 %d", len(lines))
+	}
+}
+
+func TestStatsDSinkPacketSplit(t *testing.T) {
+	conn, read := listenStatsD(t)
+	defer conn.Close()
+
+	// 较长的 DogStatsD 标签使整批指标超过一个包
+	s, err := NewStatsDSink(config.SinkConfig{
+		Address: conn.LocalAddr().String(),
+		Prefix:  "hb",
+		Tags:    map[string]string{"run": strings.Repeat("x", 200), "env": "ci"},
+	}, true)
+	if err != nil {
+		t.Fatalf("创建失败: %v", err)
+	}
+	defer s.Close()
+
+	if err := s.Send(context.Background(), []stats.TimePoint{testPoint(0)}); err != nil {
+		t.Fatalf("推送失败: %v", err)
+	}
+	packets := read()
+	if len(packets) < 2 {
+		t.Fatalf("应拆分为多个包, 实际 %d 个", len(packets))
+	}
+
+	tags := "|#env:ci,run:" + strings.Repeat("x", 200)
+	var lines int
+	for _, packet := range packets {
+		if len(packet) > statsdMaxPacket {
+			t.Errorf("包长度 %d 超过 %d", len(packet), statsdMaxPacket)
+		}
+		// 每行完整且带标签, 不会跨包
+		for _, line := range strings.Split(packet, "\n") {
+			if !strings.HasPrefix(line, "hb.") || !strings.HasSuffix(line, tags) {
+				t.Errorf("不完整的行: %q", line)
+			}
+			lines++
+		}
+	}
+	if lines != 13 {
+		t.Errorf("共 %d 行, 期望 13", lines)
+	}
+}
@@ -2,46 +2,54 @@ package stats
 
 import (
 	"sync"
-	"sync/atomic"
 	"time"
 
 	"github.com/HdrHistogram/hdrhistogram-go"
 )
 
-// TimePoint 时间点数据
+// TimePoint 时间点数据 (单个采样间隔内的指标)
 type TimePoint struct {
-	Timestamp  time.Time
-	RPS        float64
+	Timestamp time.Time
+	Interval  time.Duration
+
+	// 间隔内请求计数
+	Requests  int64
+	Success   int64
+	Errors    int64
+	RPS       float64
+	ErrorRate float64
+
+	// 间隔内传输字节数及接收吞吐量(字节/秒)
+	BytesReceived int64
+	BytesSent     int64
+	Throughput    float64
+
+	// 间隔内延迟分布
 	AvgLatency time.Duration
-	ErrorRate  float64
+	P50Latency time.Duration
+	P90Latency time.Duration
+	P99Latency time.Duration
+	MaxLatency time.Duration
 }
 
 // Collector 统计收集器
+//
+// 记录按分片进行, 快照时合并各分片, 高并发下记录不会争用同一把锁。
 type Collector struct {
-	// 基础计数
-	totalRequests   atomic.Int64
-	successRequests atomic.Int64
-	totalErrors     atomic.Int64
+	// 分片, 数量为2的幂
+	shards []*shard
 
-	bytesReceived atomic.Int64
-	bytesSent     atomic.Int64
-
-	// 延迟直方图 (使用HDR Histogram)
-	latencyHistogram *hdrhistogram.Histogram
-	histogramMu      sync.RWMutex
-
-	// 错误分类
-	errorsByType map[string]*atomic.Int64
-	errorsMu     sync.RWMutex
-
-	// 状态码统计
-	statusCodes map[int]*atomic.Int64
-	statusMu    sync.RWMutex
+	// 端点维度统计
+	endpoints   map[string]*endpointCollector
+	endpointsMu sync.RWMutex
 
 	// 时间序列数据
 	timeSeries   []TimePoint
 	timeSeriesMu sync.RWMutex
-	lastSnapshot time.Time
+
+	// 上次采样时的累计值
+	lastSample counterSample
+	sampleMu   sync.Mutex
 
 	startTime time.Time
 }
@@ -77,116 +85,121 @@ type Snapshot struct {
 
 	ErrorsByType map[string]int64
 	StatusCodes  map[int]int64
+	Endpoints    map[string]EndpointStats
 
 	Timestamp time.Time
 }
 
+// counterSample 某一时刻的累计计数
+type counterSample struct {
+	timestamp     time.Time
+	totalRequests int64
+	success       int64
+	errors        int64
+	bytesReceived int64
+	bytesSent     int64
+}
+
 // NewCollector 创建统计收集器
 func NewCollector() *Collector {
-	// HDR Histogram: 1微秒到1小时的范围,精度3位有效数字
-	histogram := hdrhistogram.New(1, 3600000000, 3)
-
-	return &Collector{
-		latencyHistogram: histogram,
-		errorsByType:     make(map[string]*atomic.Int64),
-		statusCodes:      make(map[int]*atomic.Int64),
-		timeSeries:       make([]TimePoint, 0),
-		startTime:        time.Now(),
-		lastSnapshot:     time.Now(),
-	}
+	return NewCollectorAt(time.Now())
 }
 
-// RecordRequest 记录请求
-func (c *Collector) RecordRequest(latency time.Duration, bytesReceived, bytesSent int64, success bool) {
-	c.totalRequests.Add(1)
-
-	if success {
-		c.successRequests.Add(1)
-	} else {
-		c.totalErrors.Add(1)
+// NewCollectorAt 创建以指定时刻为起点的统计收集器 (用于离线重建)
+func NewCollectorAt(now time.Time) *Collector {
+	c := &Collector{
+		shards:     make([]*shard, shardCount()),
+		endpoints:  make(map[string]*endpointCollector),
+		timeSeries: make([]TimePoint, 0),
+		startTime:  now,
+		lastSample: counterSample{timestamp: now},
+	}
+	for i := range c.shards {
+		c.shards[i] = &shard{}
 	}
+	return c
+}
 
-	c.bytesReceived.Add(bytesReceived)
-	c.bytesSent.Add(bytesSent)
+// newLatencyHistogram 创建延迟直方图
+func newLatencyHistogram() *hdrhistogram.Histogram {
+	// HDR Histogram: 1微秒到1小时的范围,精度3位有效数字
+	return hdrhistogram.New(1, 3600000000, 3)
+}
 
-	// 记录延迟到直方图 (转换为微秒)
-	c.histogramMu.Lock()
-	c.latencyHistogram.RecordValue(latency.Microseconds())
-	c.histogramMu.Unlock()
+// RecordRequest 记录请求, 工作协程应使用 Recorder 以固定分片
+func (c *Collector) RecordRequest(latency time.Duration, bytesReceived, bytesSent int64, success bool) {
+	c.anyRecorder().RecordRequest(latency, bytesReceived, bytesSent, success)
 }
 
 // RecordError 记录错误
 func (c *Collector) RecordError(errorType string, err error) {
-	c.totalErrors.Add(1)
-
-	c.errorsMu.Lock()
-	counter, exists := c.errorsByType[errorType]
-	if !exists {
-		counter = &atomic.Int64{}
-		c.errorsByType[errorType] = counter
-	}
-	c.errorsMu.Unlock()
-
-	counter.Add(1)
+	c.anyRecorder().RecordError(errorType, err)
 }
 
 // RecordStatusCode 记录状态码
 func (c *Collector) RecordStatusCode(code int) {
-	c.statusMu.Lock()
-	counter, exists := c.statusCodes[code]
-	if !exists {
-		counter = &atomic.Int64{}
-		c.statusCodes[code] = counter
+	c.anyRecorder().RecordStatusCode(code)
+}
+
+// counters 汇总各分片的累计计数
+func (c *Collector) counters(now time.Time) counterSample {
+	sample := counterSample{timestamp: now}
+	for _, s := range c.shards {
+		sample.totalRequests += s.totalRequests.Load()
+		sample.success += s.successRequests.Load()
+		sample.errors += s.totalErrors.Load()
+		sample.bytesReceived += s.bytesReceived.Load()
+		sample.bytesSent += s.bytesSent.Load()
 	}
-	c.statusMu.Unlock()
+	return sample
+}
 
-	counter.Add(1)
+// latency 合并各分片的延迟直方图
+func (c *Collector) latency() *hdrhistogram.Histogram {
+	merged := newLatencyHistogram()
+	for _, s := range c.shards {
+		s.mu.Lock()
+		if s.latency != nil {
+			merged.Merge(s.latency)
+		}
+		s.mu.Unlock()
+	}
+	return merged
 }
 
 // Snapshot 获取当前快照
 func (c *Collector) Snapshot() Snapshot {
+	counters := c.counters(time.Now())
 	snapshot := Snapshot{
-		TotalRequests:   c.totalRequests.Load(),
-		SuccessRequests: c.successRequests.Load(),
-		TotalErrors:     c.totalErrors.Load(),
-		BytesReceived:   c.bytesReceived.Load(),
-		BytesSent:       c.bytesSent.Load(),
+		TotalRequests:   counters.totalRequests,
+		SuccessRequests: counters.success,
+		TotalErrors:     counters.errors,
+		BytesReceived:   counters.bytesReceived,
+		BytesSent:       counters.bytesSent,
 		ErrorsByType:    make(map[string]int64),
 		StatusCodes:     make(map[int]int64),
-		Timestamp:       time.Now(),
+		Timestamp:       counters.timestamp,
 	}
 
-	// 复制错误统计
-	c.errorsMu.RLock()
-	for errType, counter := range c.errorsByType {
-		snapshot.ErrorsByType[errType] = counter.Load()
+	// 合并错误及状态码统计
+	for _, s := range c.shards {
+		s.errors.collect(snapshot.ErrorsByType)
+		s.statusCodes.collect(snapshot.StatusCodes)
 	}
-	c.errorsMu.RUnlock()
 
-	// 复制状态码统计
-	c.statusMu.RLock()
-	for code, counter := range c.statusCodes {
-		snapshot.StatusCodes[code] = counter.Load()
-	}
-	c.statusMu.RUnlock()
+	snapshot.Endpoints = c.endpointStats()
 
 	// 计算延迟统计
-	c.histogramMu.RLock()
-	snapshot.Latency = c.calculateLatencyStats()
-	snapshot.AvgLatency = time.Duration(c.latencyHistogram.Mean()) * time.Microsecond
-	snapshot.P99Latency = time.Duration(c.latencyHistogram.ValueAtQuantile(99.0)) * time.Microsecond
-	c.histogramMu.RUnlock()
-
-	// 记录时间点
-	c.recordTimePoint(snapshot)
+	hist := c.latency()
+	snapshot.Latency = latencyStatsOf(hist)
+	snapshot.AvgLatency = time.Duration(hist.Mean()) * time.Microsecond
+	snapshot.P99Latency = time.Duration(hist.ValueAtQuantile(99.0)) * time.Microsecond
 
 	return snapshot
 }
 
-// calculateLatencyStats 计算延迟统计
-func (c *Collector) calculateLatencyStats() LatencyStats {
-	hist := c.latencyHistogram
-
+// latencyStatsOf 根据直方图计算延迟统计
+func latencyStatsOf(hist *hdrhistogram.Histogram) LatencyStats {
 	return LatencyStats{
 		Min:    time.Duration(hist.Min()) * time.Microsecond,
 		Max:    time.Duration(hist.Max()) * time.Microsecond,
@@ -201,37 +214,68 @@ func (c *Collector) calculateLatencyStats() LatencyStats {
 	}
 }
 
-// recordTimePoint 记录时间点数据
-func (c *Collector) recordTimePoint(snapshot Snapshot) {
-	now := time.Now()
-	duration := now.Sub(c.lastSnapshot).Seconds()
+// StartSampling 以当前时刻开始第一个采样间隔, 之前的计数不计入区间指标
+func (c *Collector) StartSampling() {
+	c.sampleMu.Lock()
+	defer c.sampleMu.Unlock()
 
-	if duration < 1.0 {
-		return // 至少1秒间隔
-	}
+	c.startTime = time.Now()
+	c.lastSample = c.counters(c.startTime)
+}
+
+// Sample 结束当前采样间隔,返回间隔内的指标并追加到时间序列
+func (c *Collector) Sample() TimePoint {
+	return c.SampleAt(time.Now())
+}
 
-	// 计算RPS
-	requestsDelta := snapshot.TotalRequests - c.getLastTotalRequests()
-	rps := float64(requestsDelta) / duration
+// SampleAt 以指定时刻结束当前采样间隔
+func (c *Collector) SampleAt(now time.Time) TimePoint {
+	c.sampleMu.Lock()
+	defer c.sampleMu.Unlock()
 
-	// 计算错误率
-	errorRate := 0.0
-	if snapshot.TotalRequests > 0 {
-		errorRate = float64(snapshot.TotalErrors) / float64(snapshot.TotalRequests)
-	}
+	current := c.counters(now)
+	last := c.lastSample
+	c.lastSample = current
 
 	point := TimePoint{
-		Timestamp:  now,
-		RPS:        rps,
-		AvgLatency: snapshot.AvgLatency,
-		ErrorRate:  errorRate,
+		Timestamp:     current.timestamp,
+		Interval:      current.timestamp.Sub(last.timestamp),
+		Requests:      current.totalRequests - last.totalRequests,
+		Success:       current.success - last.success,
+		BytesReceived: current.bytesReceived - last.bytesReceived,
+		BytesSent:     current.bytesSent - last.bytesSent,
+	}
+	point.Errors = point.Requests - point.Success
+
+	if seconds := point.Interval.Seconds(); seconds > 0 {
+		point.RPS = float64(point.Requests) / seconds
+		point.Throughput = float64(point.BytesReceived) / seconds
+	}
+	if point.Requests > 0 {
+		point.ErrorRate = float64(point.Errors) / float64(point.Requests)
 	}
 
+	// 读取并重置各分片的间隔直方图
+	hist := newLatencyHistogram()
+	for _, s := range c.shards {
+		s.mu.Lock()
+		if s.intervals != nil {
+			hist.Merge(s.intervals)
+			s.intervals.Reset()
+		}
+		s.mu.Unlock()
+	}
+	point.AvgLatency = time.Duration(hist.Mean()) * time.Microsecond
+	point.P50Latency = time.Duration(hist.ValueAtQuantile(50.0)) * time.Microsecond
+	point.P90Latency = time.Duration(hist.ValueAtQuantile(90.0)) * time.Microsecond
+	point.P99Latency = time.Duration(hist.ValueAtQuantile(99.0)) * time.Microsecond
+	point.MaxLatency = time.Duration(hist.Max()) * time.Microsecond
+
 	c.timeSeriesMu.Lock()
 	c.timeSeries = append(c.timeSeries, point)
 	c.timeSeriesMu.Unlock()
 
-	c.lastSnapshot = now
+	return point
 }
 
 // GetTimeSeries 获取时间序列数据
@@ -244,64 +288,38 @@ func (c *Collector) GetTimeSeries() []TimePoint {
 	return result
 }
 
-// getLastTotalRequests 获取上次快照的总请求数
-func (c *Collector) getLastTotalRequests() int64 {
-	c.timeSeriesMu.RLock()
-	defer c.timeSeriesMu.RUnlock()
-
-	if len(c.timeSeries) == 0 {
-		return 0
-	}
-
-	// 这里简化实现,实际应该保存上次快照
-	return 0
-}
-
 // Reset 重置统计
 func (c *Collector) Reset() {
-	c.totalRequests.Store(0)
-	c.successRequests.Store(0)
-	c.totalErrors.Store(0)
-	c.bytesReceived.Store(0)
-	c.bytesSent.Store(0)
-
-	c.histogramMu.Lock()
-	c.latencyHistogram.Reset()
-	c.histogramMu.Unlock()
-
-	c.errorsMu.Lock()
-	c.errorsByType = make(map[string]*atomic.Int64)
-	c.errorsMu.Unlock()
+	for _, s := range c.shards {
+		s.reset()
+	}
 
-	c.statusMu.Lock()
-	c.statusCodes = make(map[int]*atomic.Int64)
-	c.statusMu.Unlock()
+	c.endpointsMu.Lock()
+	c.endpoints = make(map[string]*endpointCollector)
+	c.endpointsMu.Unlock()
 
 	c.timeSeriesMu.Lock()
 	c.timeSeries = make([]TimePoint, 0)
 	c.timeSeriesMu.Unlock()
 
+	c.sampleMu.Lock()
 	c.startTime = time.Now()
-	c.lastSnapshot = time.Now()
+	c.lastSample = counterSample{timestamp: c.startTime}
+	c.sampleMu.Unlock()
 }
 
 // GetLatencyDistribution 获取延迟分布
 func (c *Collector) GetLatencyDistribution() *hdrhistogram.Snapshot {
-	c.histogramMu.RLock()
-	defer c.histogramMu.RUnlock()
-
-	// 返回副本快照
-	return c.latencyHistogram.Export()
+	return c.latency().Export()
 }
 
 // GetLatencyPercentiles 获取指定百分位的延迟
 func (c *Collector) GetLatencyPercentiles(percentiles []float64) map[float64]time.Duration {
-	c.histogramMu.RLock()
-	defer c.histogramMu.RUnlock()
+	hist := c.latency()
 
 	result := make(map[float64]time.Duration)
 	for _, p := range percentiles {
-		value := c.latencyHistogram.ValueAtQuantile(p)
+		value := hist.ValueAtQuantile(p)
 		result[p] = time.Duration(value) * time.Microsecond
 	}
 
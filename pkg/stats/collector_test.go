@@ -0,0 +1,26 @@
+package stats
+
+import (
+	"testing"
+	"time"
+)
+
+// TestStartSampling 测试第一个采样间隔从开始采样时计算
+func TestStartSampling(t *testing.T) {
+	collector := NewCollectorAt(time.Now().Add(-time.Hour))
+	collector.RecordRequest(time.Millisecond, 100, 10, true)
+
+	collector.StartSampling()
+	collector.RecordRequest(time.Millisecond, 100, 10, false)
+	point := collector.Sample()
+
+	if point.Interval >= time.Minute {
+		t.Errorf("第一个间隔包含了开始采样之前的时间: %v", point.Interval)
+	}
+	if point.Requests != 1 || point.Success != 0 || point.Errors != 1 {
+		t.Errorf("区间计数不匹配: requests=%d success=%d errors=%d", point.Requests, point.Success, point.Errors)
+	}
+	if got := collector.Snapshot().TotalRequests; got != 2 {
+		t.Errorf("开始采样不应重置累计统计: got %d, want 2", got)
+	}
+}
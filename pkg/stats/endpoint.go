@@ -0,0 +1,179 @@
+package stats
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/HdrHistogram/hdrhistogram-go"
+)
+
+// EndpointStats 端点统计
+type EndpointStats struct {
+	TotalRequests   int64
+	SuccessRequests int64
+	FailedRequests  int64
+
+	// 状态码统计, 0 表示未收到响应
+	StatusCodes map[int]int64
+
+	Latency LatencyStats
+}
+
+// HistogramBuckets 延迟直方图的累计分桶
+type HistogramBuckets struct {
+	// Counts[i] 为延迟不超过 Bounds[i] 的样本数
+	Bounds []time.Duration
+	Counts []int64
+
+	Count int64
+	Sum   time.Duration
+}
+
+// endpointCollector 单个端点的统计, 与收集器使用相同的分片编号
+//
+// 分片在该编号第一次记录此端点时创建, 流量只来自少数工作协程的端点不会占用全部分片。
+type endpointCollector struct {
+	shards []atomic.Pointer[endpointShard]
+}
+
+// shard 获取编号对应的分片, 不存在时创建
+func (ep *endpointCollector) shard(i int) *endpointShard {
+	if s := ep.shards[i].Load(); s != nil {
+		return s
+	}
+	ep.shards[i].CompareAndSwap(nil, &endpointShard{})
+	return ep.shards[i].Load()
+}
+
+// each 遍历已创建的分片
+func (ep *endpointCollector) each(fn func(s *endpointShard)) {
+	for i := range ep.shards {
+		if s := ep.shards[i].Load(); s != nil {
+			fn(s)
+		}
+	}
+}
+
+// endpointShard 端点统计分片
+type endpointShard struct {
+	totalRequests   atomic.Int64
+	successRequests atomic.Int64
+	latencySum      atomic.Int64
+
+	statusCodes statusCounter
+
+	// 延迟直方图, 首次记录时创建
+	mu        sync.Mutex
+	histogram *hdrhistogram.Histogram
+}
+
+// record 记录请求结果
+func (s *endpointShard) record(statusCode int, latency time.Duration, success bool) {
+	s.totalRequests.Add(1)
+	if success {
+		s.successRequests.Add(1)
+	}
+	s.latencySum.Add(int64(latency))
+	s.statusCodes.add(statusCode)
+
+	s.mu.Lock()
+	if s.histogram == nil {
+		s.histogram = newLatencyHistogram()
+	}
+	s.histogram.RecordValue(latency.Microseconds())
+	s.mu.Unlock()
+}
+
+// RecordEndpoint 按端点记录请求结果, 工作协程应使用 Recorder 以固定分片
+func (c *Collector) RecordEndpoint(endpoint string, statusCode int, latency time.Duration, success bool) {
+	c.anyRecorder().RecordEndpoint(endpoint, statusCode, latency, success)
+}
+
+// histogram 合并各分片的延迟直方图
+func (ep *endpointCollector) histogram() *hdrhistogram.Histogram {
+	merged := newLatencyHistogram()
+	ep.each(func(s *endpointShard) {
+		s.mu.Lock()
+		if s.histogram != nil {
+			merged.Merge(s.histogram)
+		}
+		s.mu.Unlock()
+	})
+	return merged
+}
+
+// endpoint 获取或创建端点统计
+func (c *Collector) endpoint(name string) *endpointCollector {
+	c.endpointsMu.RLock()
+	ep, exists := c.endpoints[name]
+	c.endpointsMu.RUnlock()
+	if exists {
+		return ep
+	}
+
+	c.endpointsMu.Lock()
+	defer c.endpointsMu.Unlock()
+
+	if ep, exists = c.endpoints[name]; !exists {
+		ep = &endpointCollector{shards: make([]atomic.Pointer[endpointShard], len(c.shards))}
+		c.endpoints[name] = ep
+	}
+	return ep
+}
+
+// endpointStats 复制所有端点统计
+func (c *Collector) endpointStats() map[string]EndpointStats {
+	c.endpointsMu.RLock()
+	defer c.endpointsMu.RUnlock()
+
+	result := make(map[string]EndpointStats, len(c.endpoints))
+	for name, ep := range c.endpoints {
+		es := EndpointStats{
+			StatusCodes: make(map[int]int64),
+		}
+		ep.each(func(s *endpointShard) {
+			es.TotalRequests += s.totalRequests.Load()
+			es.SuccessRequests += s.successRequests.Load()
+			s.statusCodes.collect(es.StatusCodes)
+		})
+		es.FailedRequests = es.TotalRequests - es.SuccessRequests
+		es.Latency = latencyStatsOf(ep.histogram())
+
+		result[name] = es
+	}
+	return result
+}
+
+// EndpointBuckets 按给定边界统计各端点的延迟累计分桶
+func (c *Collector) EndpointBuckets(bounds []time.Duration) map[string]HistogramBuckets {
+	c.endpointsMu.RLock()
+	defer c.endpointsMu.RUnlock()
+
+	result := make(map[string]HistogramBuckets, len(c.endpoints))
+	for name, ep := range c.endpoints {
+		buckets := HistogramBuckets{
+			Bounds: bounds,
+			Counts: make([]int64, len(bounds)),
+		}
+		ep.each(func(s *endpointShard) {
+			buckets.Sum += time.Duration(s.latencySum.Load())
+		})
+
+		hist := ep.histogram()
+		buckets.Count = hist.TotalCount()
+		for _, bar := range hist.Distribution() {
+			if bar.Count == 0 {
+				continue
+			}
+			// 以桶内最大等价值归类,保证不低估延迟
+			upper := time.Duration(bar.To) * time.Microsecond
+			for i := len(bounds) - 1; i >= 0 && upper <= bounds[i]; i-- {
+				buckets.Counts[i] += bar.Count
+			}
+		}
+
+		result[name] = buckets
+	}
+	return result
+}
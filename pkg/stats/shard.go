@@ -0,0 +1,228 @@
+package stats
+
+import (
+	"math/rand"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/HdrHistogram/hdrhistogram-go"
+)
+
+// maxShards 分片数上限, 每个分片的直方图约占 200KB
+const maxShards = 64
+
+// statusCodeSlots 以数组计数的状态码范围 [0, statusCodeSlots), 0 表示未收到响应
+const statusCodeSlots = 600
+
+// shardCount 分片数: 不小于 GOMAXPROCS 的2的幂
+//
+// 分片按工作协程编号选择, 但数量取决于 GOMAXPROCS 而不是并发数: 只有同时运行的协程
+// 写入同一分片才会竞争, 而同时运行的协程最多 GOMAXPROCS 个; 编号连续的工作协程
+// 落在不同分片, 多于分片数的工作协程共用分片也只在恰好同时写入时才等待。
+// 按并发数分片 (可能上千) 不会进一步减少竞争, 却会成倍增加内存和快照合并的开销。
+func shardCount() int {
+	n := 1
+	for n < runtime.GOMAXPROCS(0) && n < maxShards {
+		n <<= 1
+	}
+	return n
+}
+
+// shard 统计分片
+//
+// 计数均为原子操作; 直方图由分片锁保护, 同一分片通常只有一个工作协程写入,
+// 快照逐个分片合并, 只在合并该分片期间持有其锁。
+type shard struct {
+	totalRequests   atomic.Int64
+	successRequests atomic.Int64
+	totalErrors     atomic.Int64
+
+	bytesReceived atomic.Int64
+	bytesSent     atomic.Int64
+
+	statusCodes statusCounter
+	errors      errorCounter
+
+	// 延迟直方图, 首次记录时创建
+	mu        sync.Mutex
+	latency   *hdrhistogram.Histogram
+	intervals *hdrhistogram.Histogram
+}
+
+// recordLatency 记录延迟
+func (s *shard) recordLatency(micros int64) {
+	s.mu.Lock()
+	if s.latency == nil {
+		s.latency = newLatencyHistogram()
+		s.intervals = newLatencyHistogram()
+	}
+	s.latency.RecordValue(micros)
+	s.intervals.RecordValue(micros)
+	s.mu.Unlock()
+}
+
+// reset 重置分片
+func (s *shard) reset() {
+	s.totalRequests.Store(0)
+	s.successRequests.Store(0)
+	s.totalErrors.Store(0)
+	s.bytesReceived.Store(0)
+	s.bytesSent.Store(0)
+	s.statusCodes.reset()
+	s.errors.reset()
+
+	s.mu.Lock()
+	if s.latency != nil {
+		s.latency.Reset()
+		s.intervals.Reset()
+	}
+	s.mu.Unlock()
+}
+
+// statusCounter 状态码计数, 常见状态码使用原子数组, 其余回退到映射
+type statusCounter struct {
+	slots [statusCodeSlots]atomic.Int64
+
+	mu    sync.Mutex
+	other map[int]int64
+}
+
+// add 计数一次
+func (sc *statusCounter) add(code int) {
+	if code >= 0 && code < statusCodeSlots {
+		sc.slots[code].Add(1)
+		return
+	}
+
+	sc.mu.Lock()
+	if sc.other == nil {
+		sc.other = make(map[int]int64)
+	}
+	sc.other[code]++
+	sc.mu.Unlock()
+}
+
+// collect 累加到 result
+func (sc *statusCounter) collect(result map[int]int64) {
+	for code := range sc.slots {
+		if n := sc.slots[code].Load(); n > 0 {
+			result[code] += n
+		}
+	}
+
+	sc.mu.Lock()
+	for code, n := range sc.other {
+		result[code] += n
+	}
+	sc.mu.Unlock()
+}
+
+// reset 清零
+func (sc *statusCounter) reset() {
+	for code := range sc.slots {
+		sc.slots[code].Store(0)
+	}
+
+	sc.mu.Lock()
+	sc.other = nil
+	sc.mu.Unlock()
+}
+
+// errorCounter 错误分类计数
+type errorCounter struct {
+	mu     sync.RWMutex
+	counts map[string]*atomic.Int64
+}
+
+// add 计数一次
+func (ec *errorCounter) add(errorType string) {
+	ec.mu.RLock()
+	counter, exists := ec.counts[errorType]
+	ec.mu.RUnlock()
+
+	if !exists {
+		ec.mu.Lock()
+		if counter, exists = ec.counts[errorType]; !exists {
+			if ec.counts == nil {
+				ec.counts = make(map[string]*atomic.Int64)
+			}
+			counter = &atomic.Int64{}
+			ec.counts[errorType] = counter
+		}
+		ec.mu.Unlock()
+	}
+
+	counter.Add(1)
+}
+
+// collect 累加到 result
+func (ec *errorCounter) collect(result map[string]int64) {
+	ec.mu.RLock()
+	defer ec.mu.RUnlock()
+
+	for errorType, counter := range ec.counts {
+		result[errorType] += counter.Load()
+	}
+}
+
+// reset 清零
+func (ec *errorCounter) reset() {
+	ec.mu.Lock()
+	ec.counts = nil
+	ec.mu.Unlock()
+}
+
+// Recorder 绑定到固定分片的记录器
+//
+// 每个工作协程使用以自身编号获取的记录器, 不同协程写入不同分片而互不竞争。
+type Recorder struct {
+	c *Collector
+	s *shard
+	i int
+}
+
+// Recorder 获取编号对应分片的记录器
+func (c *Collector) Recorder(id int) Recorder {
+	i := int(uint(id) & uint(len(c.shards)-1))
+	return Recorder{c: c, s: c.shards[i], i: i}
+}
+
+// anyRecorder 未指定编号时随机选择分片
+func (c *Collector) anyRecorder() Recorder {
+	return c.Recorder(int(rand.Uint32()))
+}
+
+// RecordRequest 记录请求
+func (r Recorder) RecordRequest(latency time.Duration, bytesReceived, bytesSent int64, success bool) {
+	r.s.totalRequests.Add(1)
+
+	if success {
+		r.s.successRequests.Add(1)
+	} else {
+		r.s.totalErrors.Add(1)
+	}
+
+	r.s.bytesReceived.Add(bytesReceived)
+	r.s.bytesSent.Add(bytesSent)
+
+	// 记录延迟到直方图 (转换为微秒)
+	r.s.recordLatency(latency.Microseconds())
+}
+
+// RecordError 记录错误
+func (r Recorder) RecordError(errorType string, err error) {
+	r.s.totalErrors.Add(1)
+	r.s.errors.add(errorType)
+}
+
+// RecordStatusCode 记录状态码
+func (r Recorder) RecordStatusCode(code int) {
+	r.s.statusCodes.add(code)
+}
+
+// RecordEndpoint 按端点记录请求结果
+func (r Recorder) RecordEndpoint(endpoint string, statusCode int, latency time.Duration, success bool) {
+	r.c.endpoint(endpoint).shard(r.i).record(statusCode, latency, success)
+}
@@ -0,0 +1,210 @@
+package stats
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/HdrHistogram/hdrhistogram-go"
+)
+
+// TestShardedStats 测试并发记录时分片统计的合并结果
+func TestShardedStats(t *testing.T) {
+	collector := NewCollector()
+
+	const workers, perWorker = 8, 500
+	stop := make(chan struct{})
+	snapshotsDone := make(chan struct{})
+	go func() {
+		defer close(snapshotsDone)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				collector.Snapshot()
+				collector.Sample()
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			rec := collector.Recorder(w)
+			for i := 0; i < perWorker; i++ {
+				code := 200
+				switch i % 10 {
+				case 0:
+					code = 0
+					rec.RecordError("network", nil)
+				case 1:
+					code = 999
+				}
+				success := code == 200
+				rec.RecordRequest(time.Duration(i+1)*time.Microsecond, 100, 10, success)
+				rec.RecordEndpoint("GET /", code, time.Millisecond, success)
+				if code > 0 {
+					rec.RecordStatusCode(code)
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(stop)
+	<-snapshotsDone
+	collector.Sample()
+
+	total := int64(workers * perWorker)
+	snapshot := collector.Snapshot()
+	if snapshot.TotalRequests != total || snapshot.SuccessRequests != total*8/10 {
+		t.Errorf("请求数 %d/%d, 期望 %d/%d", snapshot.SuccessRequests, snapshot.TotalRequests, total*8/10, total)
+	}
+	if snapshot.BytesReceived != total*100 || snapshot.BytesSent != total*10 {
+		t.Errorf("字节数 %d/%d", snapshot.BytesReceived, snapshot.BytesSent)
+	}
+	if snapshot.ErrorsByType["network"] != total/10 {
+		t.Errorf("network 错误 %d, 期望 %d", snapshot.ErrorsByType["network"], total/10)
+	}
+	if snapshot.StatusCodes[200] != total*8/10 || snapshot.StatusCodes[999] != total/10 {
+		t.Errorf("状态码统计错误: %v", snapshot.StatusCodes)
+	}
+	if snapshot.Latency.Min != time.Microsecond || snapshot.Latency.Max != perWorker*time.Microsecond {
+		t.Errorf("延迟范围 %v-%v", snapshot.Latency.Min, snapshot.Latency.Max)
+	}
+
+	ep := snapshot.Endpoints["GET /"]
+	if ep.TotalRequests != total || ep.StatusCodes[0] != total/10 || ep.StatusCodes[999] != total/10 {
+		t.Errorf("端点统计错误: %+v", ep)
+	}
+	if buckets := collector.EndpointBuckets([]time.Duration{time.Second})["GET /"]; buckets.Count != total || buckets.Counts[0] != total {
+		t.Errorf("端点分桶错误: %+v", buckets)
+	}
+
+	// 各采样间隔之和等于总数
+	var sampled int64
+	for _, point := range collector.GetTimeSeries() {
+		sampled += point.Requests
+	}
+	if sampled != total {
+		t.Errorf("采样间隔请求数之和 %d, 期望 %d", sampled, total)
+	}
+}
+
+// mutexCollector 分片前的统计方式: 全局直方图锁及状态码映射锁, 作为竞争基准
+type mutexCollector struct {
+	totalRequests atomic.Int64
+	bytesReceived atomic.Int64
+
+	histogram   *hdrhistogram.Histogram
+	histogramMu sync.RWMutex
+
+	statusCodes map[int]*atomic.Int64
+	statusMu    sync.RWMutex
+}
+
+func (c *mutexCollector) record(latency time.Duration, code int) {
+	c.totalRequests.Add(1)
+	c.bytesReceived.Add(1024)
+
+	c.histogramMu.Lock()
+	c.histogram.RecordValue(latency.Microseconds())
+	c.histogramMu.Unlock()
+
+	c.statusMu.Lock()
+	counter, exists := c.statusCodes[code]
+	if !exists {
+		counter = &atomic.Int64{}
+		c.statusCodes[code] = counter
+	}
+	c.statusMu.Unlock()
+	counter.Add(1)
+}
+
+// BenchmarkStatsContention 基准测试并发记录统计时的锁竞争, 使用 -cpu 4,8,16 对比
+func BenchmarkStatsContention(b *testing.B) {
+	b.Run("mutex", func(b *testing.B) {
+		c := &mutexCollector{
+			histogram:   hdrhistogram.New(1, 3600000000, 3),
+			statusCodes: make(map[int]*atomic.Int64),
+		}
+		b.RunParallel(func(pb *testing.PB) {
+			latency := time.Millisecond
+			for pb.Next() {
+				c.record(latency, 200)
+			}
+		})
+	})
+
+	sharded := func(b *testing.B, snapshot bool) {
+		collector := NewCollector()
+		if snapshot {
+			// 持续快照, 检验快照不阻塞记录
+			stop := make(chan struct{})
+			defer close(stop)
+			go func() {
+				for {
+					select {
+					case <-stop:
+						return
+					case <-time.After(10 * time.Millisecond):
+						collector.Snapshot()
+					}
+				}
+			}()
+		}
+
+		var workers atomic.Int64
+		b.RunParallel(func(pb *testing.PB) {
+			rec := collector.Recorder(int(workers.Add(1)))
+			latency := time.Millisecond
+			for pb.Next() {
+				rec.RecordRequest(latency, 1024, 0, true)
+				rec.RecordStatusCode(200)
+			}
+		})
+	}
+	b.Run("sharded", func(b *testing.B) { sharded(b, false) })
+	b.Run("sharded_snapshot", func(b *testing.B) { sharded(b, true) })
+}
+
+func TestShardCount(t *testing.T) {
+	n := shardCount()
+	if n&(n-1) != 0 || n > maxShards || n < min(runtime.GOMAXPROCS(0), maxShards) {
+		t.Errorf("分片数 %d, GOMAXPROCS %d", n, runtime.GOMAXPROCS(0))
+	}
+}
+
+// TestEndpointShardsLazy 端点只为记录过的分片编号创建分片
+func TestEndpointShardsLazy(t *testing.T) {
+	collector := NewCollector()
+	if len(collector.shards) < 2 {
+		t.Skip("只有一个分片")
+	}
+
+	rec := collector.Recorder(1)
+	for i := 0; i < 3; i++ {
+		rec.RecordEndpoint("GET /", 200, time.Millisecond, true)
+	}
+	collector.RecordEndpoint("GET /", 500, time.Millisecond, false)
+
+	ep := collector.endpoints["GET /"]
+	var created int
+	for i := range ep.shards {
+		if ep.shards[i].Load() != nil {
+			created++
+		}
+	}
+	if ep.shards[1].Load() == nil || created > 2 {
+		t.Errorf("创建了 %d 个分片", created)
+	}
+
+	stats := collector.Snapshot().Endpoints["GET /"]
+	if stats.TotalRequests != 4 || stats.SuccessRequests != 3 || stats.StatusCodes[500] != 1 {
+		t.Errorf("端点统计错误: %+v", stats)
+	}
+}
@@ -4,9 +4,12 @@ import (
 	"bytes"
 	"fmt"
 	"math/rand"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"text/template"
+	"text/template/parse"
 	"time"
 
 	"httpbench/pkg/config"
@@ -14,64 +17,192 @@ import (
 
 // Engine 模板引擎
 type Engine struct {
-	config    config.TemplateConfig
-	templates map[string]*template.Template
-	funcMap   template.FuncMap
+	config  config.TemplateConfig
+	funcMap template.FuncMap
+
+	mu        sync.RWMutex
+	templates map[string]*Template
+}
+
+// Template 预解析的模板, 可并发执行
+type Template struct {
+	text   string
+	tmpl   *template.Template
+	static bool
+}
+
+// bufferPool 模板输出缓冲
+var bufferPool = sync.Pool{
+	New: func() interface{} { return new(bytes.Buffer) },
 }
 
 // New 创建模板引擎
 func New(cfg config.TemplateConfig) *Engine {
 	e := &Engine{
 		config:    cfg,
-		templates: make(map[string]*template.Template),
+		templates: make(map[string]*Template),
 		funcMap:   createFuncMap(),
 	}
 
+	// 配置的变量注册为无参数函数,这样既支持 {{.var}} 也支持 {{var}}
+	for k, v := range cfg.Variables {
+		value := v // 捕获变量
+		e.funcMap[k] = func() interface{} {
+			return value
+		}
+	}
+
 	return e
 }
 
+// Compile 预解析模板并缓存
+//
+// names 为执行时通过数据传入的变量, 模板中的 {{name}} 会被改写为 {{.name}},
+// 因此每次执行只需传入新的数据而无需重新解析。
+func (e *Engine) Compile(templateStr string, names ...string) (*Template, error) {
+	key := templateStr
+	if len(names) > 0 {
+		sorted := append([]string(nil), names...)
+		sort.Strings(sorted)
+		key = strings.Join(sorted, ",") + "\x00" + templateStr
+	}
+
+	e.mu.RLock()
+	t, ok := e.templates[key]
+	e.mu.RUnlock()
+	if ok {
+		return t, nil
+	}
+
+	t = &Template{text: templateStr}
+	if !e.config.Enabled || !strings.Contains(templateStr, "{{") {
+		t.static = true
+	} else {
+		// 解析时为数据变量注册占位函数, 随后改写为字段访问
+		funcMap := template.FuncMap{}
+		rewrite := make(map[string]bool, len(names))
+		for _, name := range names {
+			funcMap[name] = func() interface{} { return nil }
+			rewrite[name] = true
+		}
+
+		tmpl, err := template.New("request").Funcs(e.funcMap).Funcs(funcMap).Parse(templateStr)
+		if err != nil {
+			return nil, fmt.Errorf("解析模板失败: %w", err)
+		}
+		rewriteIdentifiers(tmpl.Tree.Root, rewrite)
+		t.tmpl = tmpl
+	}
+
+	e.mu.Lock()
+	e.templates[key] = t
+	e.mu.Unlock()
+
+	return t, nil
+}
+
+// Vars 创建包含配置变量的数据, 调用方可继续添加变量后复用
+func (e *Engine) Vars() map[string]interface{} {
+	vars := make(map[string]interface{}, len(e.config.Variables)+4)
+	for k, v := range e.config.Variables {
+		vars[k] = v
+	}
+	return vars
+}
+
 // Render 渲染模板
 func (e *Engine) Render(templateStr string, vars map[string]interface{}) (string, error) {
 	if !e.config.Enabled {
 		return templateStr, nil
 	}
 
-	// 合并配置的变量
-	allVars := make(map[string]interface{})
-	for k, v := range e.config.Variables {
-		allVars[k] = v
+	names := make([]string, 0, len(vars))
+	for k := range vars {
+		names = append(names, k)
+	}
+	t, err := e.Compile(templateStr, names...)
+	if err != nil {
+		return "", err
 	}
+	if t.static {
+		return templateStr, nil
+	}
+
+	// 合并配置的变量
+	allVars := e.Vars()
 	for k, v := range vars {
 		allVars[k] = v
 	}
 
-	// 创建一个新的函数映射,包含原有函数和变量函数
-	funcMap := make(template.FuncMap)
-	for k, v := range e.funcMap {
-		funcMap[k] = v
+	buf := bufferPool.Get().(*bytes.Buffer)
+	defer bufferPool.Put(buf)
+	buf.Reset()
+	if err := t.Execute(buf, allVars); err != nil {
+		return "", err
 	}
 
-	// 将变量也注册为无参数函数,这样既支持 {{.var}} 也支持 {{var}}
-	for k, v := range allVars {
-		value := v // 捕获变量
-		funcMap[k] = func() interface{} {
-			return value
-		}
-	}
+	return buf.String(), nil
+}
 
-	// 创建并解析模板
-	tmpl, err := template.New("request").Funcs(funcMap).Parse(templateStr)
-	if err != nil {
-		return "", fmt.Errorf("解析模板失败: %w", err)
-	}
+// Static 模板不含动作, 输出恒为原文
+func (t *Template) Static() bool {
+	return t.static
+}
 
-	// 执行模板
-	var buf bytes.Buffer
-	if err := tmpl.Execute(&buf, allVars); err != nil {
-		return "", fmt.Errorf("执行模板失败: %w", err)
+// Execute 将模板输出追加到 buf, data 须包含编译时声明的全部变量
+func (t *Template) Execute(buf *bytes.Buffer, data map[string]interface{}) error {
+	if t.static {
+		buf.WriteString(t.text)
+		return nil
+	}
+	if err := t.tmpl.Execute(buf, data); err != nil {
+		return fmt.Errorf("执行模板失败: %w", err)
 	}
+	return nil
+}
 
-	return buf.String(), nil
+// rewriteIdentifiers 将指定名称的函数调用改写为数据字段访问
+func rewriteIdentifiers(node parse.Node, names map[string]bool) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			rewriteIdentifiers(child, names)
+		}
+	case *parse.ActionNode:
+		rewriteIdentifiers(n.Pipe, names)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, cmd := range n.Cmds {
+			rewriteIdentifiers(cmd, names)
+		}
+	case *parse.CommandNode:
+		for i, arg := range n.Args {
+			if ident, ok := arg.(*parse.IdentifierNode); ok && names[ident.Ident] {
+				n.Args[i] = &parse.FieldNode{NodeType: parse.NodeField, Pos: ident.Pos, Ident: []string{ident.Ident}}
+				continue
+			}
+			rewriteIdentifiers(arg, names)
+		}
+	case *parse.ChainNode:
+		rewriteIdentifiers(n.Node, names)
+	case *parse.IfNode:
+		rewriteIdentifiers(&n.BranchNode, names)
+	case *parse.RangeNode:
+		rewriteIdentifiers(&n.BranchNode, names)
+	case *parse.WithNode:
+		rewriteIdentifiers(&n.BranchNode, names)
+	case *parse.BranchNode:
+		rewriteIdentifiers(n.Pipe, names)
+		rewriteIdentifiers(n.List, names)
+		rewriteIdentifiers(n.ElseList, names)
+	case *parse.TemplateNode:
+		rewriteIdentifiers(n.Pipe, names)
+	}
 }
 
 // RenderBytes 渲染模板为字节数组
@@ -0,0 +1,154 @@
+package template
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"httpbench/pkg/config"
+)
+
+// newTestEngine 创建启用模板的引擎, 配置变量 host 和 user
+func newTestEngine() *Engine {
+	return New(config.TemplateConfig{
+		Enabled:   true,
+		Variables: map[string]string{"host": "example.com", "user": "alice"},
+	})
+}
+
+// TestCompile 测试各类变量和函数的编译与执行
+func TestCompile(t *testing.T) {
+	engine := newTestEngine()
+
+	for _, tc := range []struct {
+		name   string
+		text   string
+		names  []string
+		data   map[string]interface{}
+		static bool
+		want   string
+	}{
+		{"纯文本", `{"id": 1}`, nil, nil, true, `{"id": 1}`},
+		{"单个花括号", `{"a": {"b": 1}}`, []string{"id"}, nil, true, `{"a": {"b": 1}}`},
+		{"配置变量", "http://{{host}}/{{.user}}", nil, nil, false, "http://example.com/alice"},
+		{"数据变量", "/users/{{id}}?n={{.n}}", []string{"id", "n"},
+			map[string]interface{}{"id": 7, "n": "x"}, false, "/users/7?n=x"},
+		{"数据变量覆盖配置变量", "{{user}}", []string{"user"},
+			map[string]interface{}{"user": "bob"}, false, "bob"},
+		{"函数参数中的数据变量", "{{add id 1}}-{{upper name}}", []string{"id", "name"},
+			map[string]interface{}{"id": 41, "name": "ok"}, false, "42-OK"},
+		{"管道中的数据变量", "{{name | printf \"%s!\"}}", []string{"name"},
+			map[string]interface{}{"name": "hi"}, false, "hi!"},
+		{"分支中的数据变量", "{{if flag}}{{id}}{{else}}none{{end}}", []string{"flag", "id"},
+			map[string]interface{}{"flag": true, "id": 3}, false, "3"},
+		{"循环中的数据变量", "{{range $i := seq 1 n}}{{$i}}{{end}}", []string{"n"},
+			map[string]interface{}{"n": 3}, false, "123"},
+		{"内置函数", "{{substr \"abcdef\" 1 3}}{{mod 7 3}}{{ternary true \"y\" \"n\"}}", nil, nil, false, "bcd1y"},
+		{"缺少的数据字段", "[{{.missing}}]", nil, nil, false, "[<no value>]"},
+	} {
+		tmpl, err := engine.Compile(tc.text, tc.names...)
+		if err != nil {
+			t.Errorf("%s: 编译失败: %v", tc.name, err)
+			continue
+		}
+		if tmpl.Static() != tc.static {
+			t.Errorf("%s: Static() = %v, 期望 %v", tc.name, tmpl.Static(), tc.static)
+		}
+
+		data := engine.Vars()
+		for k, v := range tc.data {
+			data[k] = v
+		}
+		var buf bytes.Buffer
+		buf.WriteString(">")
+		if err := tmpl.Execute(&buf, data); err != nil {
+			t.Errorf("%s: 执行失败: %v", tc.name, err)
+			continue
+		}
+		if got := buf.String(); got != ">"+tc.want {
+			t.Errorf("%s: 输出 %q, 期望追加 %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+// TestCompileErrors 测试未知变量和错误的 {{ 语法
+func TestCompileErrors(t *testing.T) {
+	engine := newTestEngine()
+
+	for _, tc := range []struct {
+		name  string
+		text  string
+		names []string
+	}{
+		{"未知变量", "{{unknown}}", nil},
+		{"未声明的数据变量", "{{id}}", []string{"other"}},
+		{"未闭合的动作", "/users/{{id", []string{"id"}},
+		{"空动作", "{{}}", nil},
+		{"未闭合的分支", "{{if .a}}x", nil},
+		{"多余的结束", "x{{end}}", nil},
+		{"非法字符", "{{ @ }}", nil},
+	} {
+		_, err := engine.Compile(tc.text, tc.names...)
+		if err == nil || !strings.Contains(err.Error(), "解析模板失败") {
+			t.Errorf("%s: %q 应返回解析错误, 得到 %v", tc.name, tc.text, err)
+		}
+	}
+
+	// 编译成功但执行时出错
+	tmpl, err := engine.Compile("{{div .a .b}}")
+	if err != nil {
+		t.Fatalf("编译失败: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, map[string]interface{}{"a": "x", "b": 1}); err == nil || !strings.Contains(err.Error(), "执行模板失败") {
+		t.Errorf("参数类型错误应返回执行错误, 得到 %v", err)
+	}
+}
+
+// TestCompileCache 测试相同模板和变量集合复用编译结果
+func TestCompileCache(t *testing.T) {
+	engine := newTestEngine()
+
+	a, _ := engine.Compile("{{a}}{{b}}", "a", "b")
+	b, _ := engine.Compile("{{a}}{{b}}", "b", "a")
+	if a != b {
+		t.Error("变量顺序不同的相同模板应复用编译结果")
+	}
+	if c, _ := engine.Compile("{{a}}{{b}}", "a", "b", "c"); c == a {
+		t.Error("变量集合不同时应重新编译")
+	}
+
+	// 未启用模板时原样输出
+	disabled := New(config.TemplateConfig{Variables: map[string]string{"host": "example.com"}})
+	tmpl, err := disabled.Compile("{{host}}")
+	if err != nil || !tmpl.Static() {
+		t.Fatalf("未启用模板时应为静态模板: %v", err)
+	}
+	if got, _ := disabled.Render("{{host}}", nil); got != "{{host}}" {
+		t.Errorf("未启用模板时渲染结果 %q", got)
+	}
+}
+
+// TestVars 测试 Vars 返回配置变量的独立副本
+func TestVars(t *testing.T) {
+	engine := newTestEngine()
+
+	vars := engine.Vars()
+	if len(vars) != 2 || vars["host"] != "example.com" || vars["user"] != "alice" {
+		t.Fatalf("配置变量 %v", vars)
+	}
+	vars["host"] = "changed"
+	vars["id"] = 1
+	if again := engine.Vars(); len(again) != 2 || again["host"] != "example.com" {
+		t.Errorf("修改返回的数据不应影响引擎: %v", again)
+	}
+
+	if empty := New(config.TemplateConfig{}).Vars(); empty == nil || len(empty) != 0 {
+		t.Errorf("没有配置变量时应返回空的可写数据: %v", empty)
+	}
+
+	got, err := engine.Render("{{host}}/{{id}}", map[string]interface{}{"id": 5})
+	if err != nil || got != "example.com/5" {
+		t.Errorf("Render: %q %v", got, err)
+	}
+}
@@ -0,0 +1,306 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/term"
+
+	"httpbench/pkg/benchmark"
+	"httpbench/pkg/config"
+	"httpbench/pkg/stats"
+)
+
+const (
+	// defaultRefreshInterval 默认刷新间隔
+	defaultRefreshInterval = 500 * time.Millisecond
+
+	// historySize 保留的采样间隔数(用于趋势图)
+	historySize = 120
+
+	// logSize 保留的运行日志行数
+	logSize = 200
+)
+
+// ANSI控制序列
+const (
+	enterAltScreen = "\x1b[?1049h"
+	leaveAltScreen = "\x1b[?1049l"
+	hideCursor     = "\x1b[?25l"
+	showCursor     = "\x1b[?25h"
+	cursorHome     = "\x1b[H"
+	clearLine      = "\x1b[K"
+	clearBelow     = "\x1b[J"
+	reverseVideo   = "\x1b[7m"
+	resetStyle     = "\x1b[0m"
+)
+
+// Options 仪表盘配置
+type Options struct {
+	// 刷新间隔
+	RefreshInterval time.Duration
+
+	// 快照保存目录
+	SnapshotDir string
+
+	// 优雅停止后再次按下停止键时调用 (通常用于取消上下文)
+	OnForceQuit func()
+}
+
+// Dashboard 全屏终端仪表盘
+//
+// 仪表盘独占终端: 运行日志被重定向到日志面板,按键用于暂停、保存快照和停止。
+type Dashboard struct {
+	bench *benchmark.Benchmark
+	cfg   *config.Config
+	opts  Options
+
+	out      *os.File
+	in       *os.File
+	oldState *term.State
+
+	mu       sync.Mutex
+	history  []stats.TimePoint
+	logs     []string
+	partial  string
+	status   string
+	stopping bool
+
+	done    chan struct{}
+	stopped sync.WaitGroup
+	once    sync.Once
+}
+
+// IsTerminal 判断文件是否为终端
+func IsTerminal(f *os.File) bool {
+	return term.IsTerminal(int(f.Fd()))
+}
+
+// New 创建仪表盘,须在基准测试Run之前调用
+func New(bench *benchmark.Benchmark, cfg *config.Config, opts Options) *Dashboard {
+	if opts.RefreshInterval <= 0 {
+		opts.RefreshInterval = defaultRefreshInterval
+	}
+	if opts.SnapshotDir == "" {
+		opts.SnapshotDir = "."
+	}
+
+	d := &Dashboard{
+		bench: bench,
+		cfg:   cfg,
+		opts:  opts,
+		out:   os.Stdout,
+		in:    os.Stdin,
+		done:  make(chan struct{}),
+	}
+
+	bench.OnInterval(d.record)
+	bench.SetLogOutput(d)
+
+	return d
+}
+
+// Start 进入全屏模式并开始刷新
+func (d *Dashboard) Start() error {
+	// 标准输入不是终端时仍可显示,只是不响应按键
+	if IsTerminal(d.in) {
+		state, err := term.MakeRaw(int(d.in.Fd()))
+		if err != nil {
+			return fmt.Errorf("设置终端模式失败: %w", err)
+		}
+		d.oldState = state
+		go d.readKeys()
+	}
+
+	fmt.Fprint(d.out, enterAltScreen+hideCursor)
+
+	d.stopped.Add(1)
+	go d.refreshLoop()
+
+	return nil
+}
+
+// Close 退出全屏模式并恢复终端
+func (d *Dashboard) Close() {
+	d.once.Do(func() {
+		close(d.done)
+		d.stopped.Wait()
+
+		fmt.Fprint(d.out, showCursor+leaveAltScreen)
+		if d.oldState != nil {
+			term.Restore(int(d.in.Fd()), d.oldState)
+		}
+		d.bench.SetLogOutput(nil)
+	})
+}
+
+// Write 接收运行日志 (实现io.Writer)
+func (d *Dashboard) Write(p []byte) (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	lines := strings.Split(d.partial+string(p), "\n")
+	d.partial = lines[len(lines)-1]
+	for _, line := range lines[:len(lines)-1] {
+		line = strings.TrimRight(line, " \r")
+		if line == "" {
+			continue
+		}
+		d.logs = append(d.logs, time.Now().Format("15:04:05")+" "+line)
+	}
+	if len(d.logs) > logSize {
+		d.logs = append(d.logs[:0], d.logs[len(d.logs)-logSize:]...)
+	}
+
+	return len(p), nil
+}
+
+// record 记录采样间隔
+func (d *Dashboard) record(point stats.TimePoint) {
+	d.mu.Lock()
+	d.history = append(d.history, point)
+	if len(d.history) > historySize {
+		d.history = append(d.history[:0], d.history[len(d.history)-historySize:]...)
+	}
+	d.mu.Unlock()
+}
+
+// setStatus 设置状态栏提示
+func (d *Dashboard) setStatus(format string, args ...interface{}) {
+	d.mu.Lock()
+	d.status = fmt.Sprintf(format, args...)
+	d.mu.Unlock()
+}
+
+// refreshLoop 定时刷新画面
+func (d *Dashboard) refreshLoop() {
+	defer d.stopped.Done()
+
+	ticker := time.NewTicker(d.opts.RefreshInterval)
+	defer ticker.Stop()
+
+	d.draw()
+	for {
+		select {
+		case <-d.done:
+			d.draw()
+			return
+		case <-ticker.C:
+			d.draw()
+		}
+	}
+}
+
+// draw 绘制一帧
+func (d *Dashboard) draw() {
+	width, height := d.size()
+	lines := d.frame(width, height)
+
+	var sb strings.Builder
+	sb.WriteString(cursorHome)
+	for i, line := range lines {
+		if i == 0 {
+			// 标题栏反色显示
+			sb.WriteString(reverseVideo + pad(line, width) + resetStyle)
+		} else {
+			sb.WriteString(line)
+		}
+		sb.WriteString(clearLine)
+		if i < len(lines)-1 {
+			sb.WriteString("\r\n")
+		}
+	}
+	sb.WriteString(clearBelow)
+
+	fmt.Fprint(d.out, sb.String())
+}
+
+// size 获取终端尺寸
+func (d *Dashboard) size() (int, int) {
+	width, height, err := term.GetSize(int(d.out.Fd()))
+	if err != nil || width <= 0 || height <= 0 {
+		return 100, 32
+	}
+	return width, height
+}
+
+// readKeys 处理按键
+func (d *Dashboard) readKeys() {
+	buf := make([]byte, 16)
+	for {
+		n, err := d.in.Read(buf)
+		if err != nil {
+			return
+		}
+
+		select {
+		case <-d.done:
+			return
+		default:
+		}
+
+		for _, key := range buf[:n] {
+			switch key {
+			case 'p', 'P', ' ':
+				d.togglePause()
+			case 's', 'S':
+				d.SaveSnapshot()
+			case 'q', 'Q', 3: // 3 = Ctrl-C (原始模式下不会产生SIGINT)
+				d.stop()
+			}
+		}
+	}
+}
+
+// togglePause 暂停/恢复
+func (d *Dashboard) togglePause() {
+	if d.bench.Paused() {
+		d.bench.Resume()
+		d.setStatus("已恢复")
+	} else {
+		d.bench.Pause()
+		d.setStatus("已暂停, 按 p 恢复")
+	}
+}
+
+// stop 第一次优雅停止,第二次强制退出
+func (d *Dashboard) stop() {
+	d.mu.Lock()
+	force := d.stopping
+	d.stopping = true
+	d.mu.Unlock()
+
+	if force {
+		d.setStatus("正在强制退出...")
+		if d.opts.OnForceQuit != nil {
+			d.opts.OnForceQuit()
+		}
+		return
+	}
+
+	d.setStatus("正在停止, 等待进行中的请求完成 (再次按 q 强制退出)")
+	d.bench.Stop()
+}
+
+// SaveSnapshot 将当前画面保存为文本文件
+func (d *Dashboard) SaveSnapshot() {
+	width, height := d.size()
+	lines := d.frame(width, height)
+
+	name := fmt.Sprintf("httpbench-snapshot-%s.txt", time.Now().Format("20060102-150405"))
+	path := filepath.Join(d.opts.SnapshotDir, name)
+
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	content := strings.TrimRight(strings.Join(lines, "\n"), "\n") + "\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		d.setStatus("保存快照失败: %v", err)
+		return
+	}
+	d.setStatus("快照已保存: %s", path)
+}
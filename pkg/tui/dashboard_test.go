@@ -0,0 +1,46 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// TestLogRouting 测试基准测试的运行日志进入日志面板, 关闭后恢复默认输出
+func TestLogRouting(t *testing.T) {
+	d, bench := newTestDashboard(t)
+
+	bench.Pause()
+	bench.SetRate(0)
+	// 不完整的行等到换行后才显示, 空行被忽略
+	d.Write([]byte("分两次"))
+	if len(d.logs) != 2 {
+		t.Fatalf("日志面板 %q", d.logs)
+	}
+	d.Write([]byte("写入\r\n\n"))
+
+	if len(d.logs) != 3 {
+		t.Fatalf("日志面板 %d 行, 期望 3: %q", len(d.logs), d.logs)
+	}
+	for i, want := range []string{"已暂停", "目标速率调整为不限速", "分两次写入"} {
+		// 每行以时间开头
+		if line := d.logs[i]; !strings.HasSuffix(line, want) || line[2] != ':' {
+			t.Errorf("第 %d 行 %q, 期望以时间开头并以 %q 结尾", i, line, want)
+		}
+	}
+
+	// 只保留最近的 logSize 行
+	for i := 0; i < logSize+10; i++ {
+		fmt.Fprintf(d, "line %d\n", i)
+	}
+	if len(d.logs) != logSize || !strings.HasSuffix(d.logs[logSize-1], fmt.Sprintf("line %d", logSize+9)) {
+		t.Errorf("日志面板 %d 行, 最后一行 %q", len(d.logs), d.logs[len(d.logs)-1])
+	}
+
+	d.Close()
+	before := len(d.logs)
+	bench.Resume()
+	if len(d.logs) != before {
+		t.Error("关闭后运行日志不应再进入日志面板")
+	}
+}
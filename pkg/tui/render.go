@@ -0,0 +1,269 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"httpbench/pkg/benchmark"
+	"httpbench/pkg/stats"
+)
+
+// sparkBlocks 趋势图字符
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// frame 生成一帧画面 (纯文本,不含控制序列)
+func (d *Dashboard) frame(width, height int) []string {
+	snapshot := d.bench.Stats().Snapshot()
+
+	d.mu.Lock()
+	history := append([]stats.TimePoint(nil), d.history...)
+	logs := append([]string(nil), d.logs...)
+	status := d.status
+	stopping := d.stopping
+	d.mu.Unlock()
+
+	var last stats.TimePoint
+	if len(history) > 0 {
+		last = history[len(history)-1]
+	}
+
+	var lines []string
+	add := func(format string, args ...interface{}) {
+		lines = append(lines, fmt.Sprintf(format, args...))
+	}
+
+	// 标题栏
+	state := "▶ 运行中"
+	switch {
+	case d.bench.Stage() == benchmark.StageDone:
+		state = "■ 已完成"
+	case stopping:
+		state = "■ 停止中"
+	case d.bench.Paused():
+		state = "⏸ 已暂停"
+	}
+	method := d.cfg.Target.Method
+	if method == "" {
+		method = "GET"
+	}
+	add(" httpbench │ %s %s │ 阶段: %s │ %s", method, d.cfg.Target.URL, d.bench.Stage(), state)
+
+	// 进度
+	elapsed := d.bench.Elapsed()
+	progress := fmt.Sprintf(" 已运行 %s", formatClock(elapsed))
+	if planned := d.bench.PlannedDuration(); planned > 0 {
+		remaining := planned - elapsed
+		if remaining < 0 {
+			remaining = 0
+		}
+		progress += fmt.Sprintf(" / %s  %s  剩余 %s",
+			formatClock(planned), progressBar(float64(elapsed)/float64(planned), 30), formatClock(remaining))
+	}
+	if total := d.cfg.Load.TotalRequests; total > 0 {
+		progress += fmt.Sprintf("  请求 %d/%d", snapshot.TotalRequests, total)
+	}
+	add("%s", progress)
+
+	targetRate := "不限速"
+	if rate := d.bench.TargetRate(); rate > 0 {
+		targetRate = fmt.Sprintf("%.0f req/s", rate)
+	}
+	add(" 活跃工作协程 %-6d 进行中请求 %-6d 目标速率 %s",
+		d.bench.ActiveWorkers(), d.bench.InFlight(), targetRate)
+	add("")
+
+	// 趋势图
+	sparkWidth := width - 32
+	if sparkWidth < 10 {
+		sparkWidth = 10
+	}
+	if sparkWidth > historySize {
+		sparkWidth = historySize
+	}
+	rps := make([]float64, len(history))
+	p99 := make([]float64, len(history))
+	errRate := make([]float64, len(history))
+	for i, point := range history {
+		rps[i] = point.RPS
+		p99[i] = float64(point.P99Latency)
+		errRate[i] = point.ErrorRate
+	}
+	add(" %-10s %14s  %s", "RPS", fmt.Sprintf("%.1f", last.RPS), sparkline(rps, sparkWidth))
+	add(" %-10s %14v  %s", "P99延迟", last.P99Latency, sparkline(p99, sparkWidth))
+	add(" %-10s %14s  %s", "错误率", fmt.Sprintf("%.2f%%", last.ErrorRate*100), sparkline(errRate, sparkWidth))
+	add("")
+
+	// 延迟分位
+	total := snapshot.Latency
+	add(" %-10s %12s %12s", "延迟", "累计", "最近区间")
+	add(" %-10s %12v %12v", "Mean", total.Mean, last.AvgLatency)
+	add(" %-10s %12v %12v", "P50", total.P50, last.P50Latency)
+	add(" %-10s %12v %12v", "P90", total.P90, last.P90Latency)
+	add(" %-10s %12v %12s", "P95", total.P95, "-")
+	add(" %-10s %12v %12v", "P99", total.P99, last.P99Latency)
+	add(" %-10s %12v %12s", "P99.9", total.P999, "-")
+	add(" %-10s %12v %12v", "Max", total.Max, last.MaxLatency)
+	add("")
+
+	// 请求统计、状态码及错误分布
+	successRate := 0.0
+	if snapshot.TotalRequests > 0 {
+		successRate = float64(snapshot.SuccessRequests) / float64(snapshot.TotalRequests) * 100
+	}
+	add(" 请求 %d  成功 %d  失败 %d  成功率 %.2f%%",
+		snapshot.TotalRequests, snapshot.SuccessRequests, snapshot.TotalErrors, successRate)
+
+	codes := statusCodeLines(snapshot.StatusCodes, snapshot.TotalRequests)
+	errs := errorLines(snapshot.ErrorsByType)
+	add(" %-30s %s", "状态码", "错误")
+	for i := 0; i < len(codes) || i < len(errs); i++ {
+		var left, right string
+		if i < len(codes) {
+			left = codes[i]
+		}
+		if i < len(errs) {
+			right = errs[i]
+		}
+		add(" %-30s %s", left, right)
+	}
+	add("")
+
+	// 运行日志占用剩余空间
+	footer := " [p] 暂停/恢复  [s] 保存快照  [q] 停止"
+	if status != "" {
+		footer += "  │ " + status
+	}
+	room := height - len(lines) - 2
+	if room > 0 && len(logs) > 0 {
+		add(" 运行日志")
+		room--
+		if len(logs) > room {
+			logs = logs[len(logs)-room:]
+		}
+		for _, line := range logs {
+			add("   %s", line)
+		}
+	}
+	for len(lines) < height-1 {
+		add("")
+	}
+	add("%s", footer)
+
+	// 裁剪到终端尺寸
+	if len(lines) > height {
+		lines = append(lines[:height-1], lines[len(lines)-1])
+	}
+	for i, line := range lines {
+		lines[i] = truncate(line, width)
+	}
+
+	return lines
+}
+
+// statusCodeLines 状态码分布, 0 表示未收到响应
+func statusCodeLines(codes map[int]int64, total int64) []string {
+	keys := make([]int, 0, len(codes))
+	for code := range codes {
+		keys = append(keys, code)
+	}
+	sort.Ints(keys)
+
+	lines := make([]string, 0, len(keys))
+	for _, code := range keys {
+		share := 0.0
+		if total > 0 {
+			share = float64(codes[code]) / float64(total) * 100
+		}
+		lines = append(lines, fmt.Sprintf("  %-5d %10d (%5.1f%%)", code, codes[code], share))
+	}
+	return lines
+}
+
+// errorLines 错误分布,按数量降序
+func errorLines(errors map[string]int64) []string {
+	keys := make([]string, 0, len(errors))
+	for errType := range errors {
+		keys = append(keys, errType)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if errors[keys[i]] != errors[keys[j]] {
+			return errors[keys[i]] > errors[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+
+	lines := make([]string, 0, len(keys))
+	for _, errType := range keys {
+		lines = append(lines, fmt.Sprintf("%-20s %d", errType, errors[errType]))
+	}
+	return lines
+}
+
+// sparkline 绘制趋势图,只保留最近width个值
+func sparkline(values []float64, width int) string {
+	if len(values) > width {
+		values = values[len(values)-width:]
+	}
+
+	max := 0.0
+	for _, v := range values {
+		if v > max {
+			max = v
+		}
+	}
+
+	var sb strings.Builder
+	for _, v := range values {
+		idx := 0
+		if max > 0 {
+			idx = int(v / max * float64(len(sparkBlocks)-1))
+		}
+		sb.WriteRune(sparkBlocks[idx])
+	}
+	return sb.String()
+}
+
+// progressBar 绘制进度条
+func progressBar(fraction float64, width int) string {
+	if fraction < 0 {
+		fraction = 0
+	}
+	if fraction > 1 {
+		fraction = 1
+	}
+	filled := int(fraction * float64(width))
+	return "[" + strings.Repeat("█", filled) + strings.Repeat("░", width-filled) +
+		fmt.Sprintf("] %3.0f%%", fraction*100)
+}
+
+// formatClock 格式化为 时:分:秒
+func formatClock(d time.Duration) string {
+	d = d.Round(time.Second)
+	h := int(d.Hours())
+	m := int(d.Minutes()) % 60
+	s := int(d.Seconds()) % 60
+	if h > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", h, m, s)
+	}
+	return fmt.Sprintf("%02d:%02d", m, s)
+}
+
+// truncate 按显示宽度截断 (按字符近似)
+func truncate(s string, width int) string {
+	if utf8.RuneCountInString(s) <= width {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:width])
+}
+
+// pad 填充到指定宽度
+func pad(s string, width int) string {
+	if n := utf8.RuneCountInString(s); n < width {
+		return s + strings.Repeat(" ", width-n)
+	}
+	return s
+}
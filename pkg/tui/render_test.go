@@ -0,0 +1,128 @@
+package tui
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"httpbench/pkg/benchmark"
+	"httpbench/pkg/config"
+	"httpbench/pkg/stats"
+)
+
+// newTestDashboard 创建未启动的仪表盘, 画面输出到空设备
+func newTestDashboard(t *testing.T) (*Dashboard, *benchmark.Benchmark) {
+	t.Helper()
+	cfg := &config.Config{
+		Target: config.TargetConfig{URL: "http://127.0.0.1:1/api", Method: "POST"},
+		Load:   config.LoadConfig{Concurrency: 2, Duration: time.Minute, RateLimit: 100},
+	}
+	bench, err := benchmark.New(cfg)
+	if err != nil {
+		t.Fatalf("创建基准测试器失败: %v", err)
+	}
+	t.Cleanup(func() { bench.Close() })
+
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatalf("打开空设备失败: %v", err)
+	}
+	t.Cleanup(func() { devNull.Close() })
+
+	d := New(bench, cfg, Options{})
+	d.out = devNull
+	return d, bench
+}
+
+// TestFrame 测试固定尺寸下各面板的布局
+func TestFrame(t *testing.T) {
+	d, _ := newTestDashboard(t)
+	for i := 1; i <= 3; i++ {
+		d.record(stats.TimePoint{
+			RPS:        float64(100 * i),
+			P99Latency: time.Duration(i) * time.Millisecond,
+			ErrorRate:  0.01,
+		})
+	}
+	d.Write([]byte("第一条日志\n第二条日志\n"))
+	d.setStatus("快照已保存")
+
+	const width, height = 80, 40
+	lines := d.frame(width, height)
+	if len(lines) != height {
+		t.Fatalf("画面 %d 行, 期望 %d", len(lines), height)
+	}
+	for i, line := range lines {
+		if n := utf8.RuneCountInString(line); n > width {
+			t.Errorf("第 %d 行宽 %d, 超过 %d: %q", i, n, width, line)
+		}
+	}
+
+	text := strings.Join(lines, "\n")
+	for _, want := range []string{
+		"POST http://127.0.0.1:1/api",
+		"阶段: " + benchmark.StageIdle,
+		"/ 01:00",
+		"目标速率 100 req/s",
+		"300.0",
+		"▃▅█",
+		"1.00%",
+		"运行日志",
+		"第二条日志",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("画面缺少 %q:\n%s", want, text)
+		}
+	}
+	if footer := lines[height-1]; !strings.HasPrefix(footer, " [p] 暂停/恢复") || !strings.HasSuffix(footer, "│ 快照已保存") {
+		t.Errorf("状态栏 %q", footer)
+	}
+
+	// 高度不足时日志面板让出空间, 状态栏仍在最后一行
+	lines = d.frame(width, 12)
+	if len(lines) != 12 || !strings.HasPrefix(lines[11], " [p]") {
+		t.Errorf("矮终端画面 %d 行, 最后一行 %q", len(lines), lines[len(lines)-1])
+	}
+	if strings.Contains(strings.Join(lines, "\n"), "运行日志") {
+		t.Error("没有剩余空间时不应显示运行日志")
+	}
+}
+
+// TestPanels 测试趋势图、进度条和分布面板的格式
+func TestPanels(t *testing.T) {
+	if got := sparkline([]float64{0, 1, 2, 4, 8}, 4); got != "▁▂▄█" {
+		t.Errorf("趋势图 %q", got)
+	}
+	if got := sparkline([]float64{0, 0}, 10); got != "▁▁" {
+		t.Errorf("全零趋势图 %q", got)
+	}
+	if got := progressBar(0.5, 10); got != "[█████░░░░░]  50%" {
+		t.Errorf("进度条 %q", got)
+	}
+	if got := progressBar(1.5, 4); got != "[████] 100%" {
+		t.Errorf("超出范围的进度条 %q", got)
+	}
+	if got := formatClock(61*time.Second + 400*time.Millisecond); got != "01:01" {
+		t.Errorf("时钟 %q", got)
+	}
+	if got := formatClock(time.Hour + 2*time.Second); got != "1:00:02" {
+		t.Errorf("时钟 %q", got)
+	}
+	if got := truncate("延迟分布", 2); got != "延迟" {
+		t.Errorf("截断 %q", got)
+	}
+	if got := pad("ab", 4); got != "ab  " {
+		t.Errorf("填充 %q", got)
+	}
+
+	codes := statusCodeLines(map[int]int64{503: 1, 0: 1, 200: 2}, 4)
+	if len(codes) != 3 || codes[0] != "  0              1 ( 25.0%)" || !strings.HasPrefix(codes[2], "  503 ") {
+		t.Errorf("状态码分布应按状态码排序: %q", codes)
+	}
+	errs := errorLines(map[string]int64{"timeout": 1, "validation": 3, "connection": 1})
+	if len(errs) != 3 || !strings.HasPrefix(errs[0], "validation") || !strings.HasPrefix(errs[1], "connection") {
+		t.Errorf("错误分布应按数量降序、名称升序: %q", errs)
+	}
+}
@@ -2,6 +2,7 @@ package validator
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"regexp"
@@ -67,6 +68,13 @@ func (v *Validator) Validate(resp *http.Response, body []byte) error {
 		return err
 	}
 
+	// 验证GraphQL错误
+	if v.config.GraphQLErrors {
+		if err := v.validateGraphQL(body); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -172,6 +180,37 @@ func (v *Validator) validateBody(body []byte) error {
 	return nil
 }
 
+// validateGraphQL 验证GraphQL响应, errors 非空即为失败 (即使 HTTP 状态为 200)
+func (v *Validator) validateGraphQL(body []byte) error {
+	var resp struct {
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return &ValidationError{
+			Type:    "graphql",
+			Message: fmt.Sprintf("响应不是有效的JSON: %v", err),
+		}
+	}
+
+	if len(resp.Errors) > 0 {
+		return &ValidationError{
+			Type:    "graphql",
+			Message: fmt.Sprintf("响应包含 %d 个错误: %s", len(resp.Errors), resp.Errors[0].Message),
+		}
+	}
+
+	return nil
+}
+
+// NeedsBody 是否需要读取响应体进行校验
+func (v *Validator) NeedsBody() bool {
+	bv := v.config.BodyValidation
+	return bv.MinSize > 0 || bv.MaxSize > 0 || len(bv.Contains) > 0 ||
+		len(bv.NotContains) > 0 || len(v.contentPatterns) > 0 || v.config.GraphQLErrors
+}
+
 // IsValid 快速检查是否有效
 func (v *Validator) IsValid(resp *http.Response) bool {
 	if len(v.statusCodeMap) == 0 {
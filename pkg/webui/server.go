@@ -0,0 +1,321 @@
+package webui
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"httpbench/pkg/benchmark"
+	"httpbench/pkg/control"
+	"httpbench/pkg/stats"
+)
+
+//go:embed static
+var staticFiles embed.FS
+
+const (
+	// historySize 新连接回放的采样间隔数
+	historySize = 3600
+
+	// clientBuffer 每个浏览器连接的事件缓冲,写满时丢弃
+	clientBuffer = 64
+
+	// heartbeatInterval SSE心跳间隔,防止代理断开空闲连接
+	heartbeatInterval = 15 * time.Second
+)
+
+// Point 采样间隔数据 (延迟单位: 毫秒)
+type Point struct {
+	Timestamp     int64   `json:"timestamp"`
+	Elapsed       float64 `json:"elapsed_s"`
+	Requests      int64   `json:"requests"`
+	Success       int64   `json:"success"`
+	Errors        int64   `json:"errors"`
+	RPS           float64 `json:"rps"`
+	ErrorRate     float64 `json:"error_rate"`
+	Throughput    float64 `json:"throughput_bps"`
+	AvgLatency    float64 `json:"avg_ms"`
+	P50Latency    float64 `json:"p50_ms"`
+	P90Latency    float64 `json:"p90_ms"`
+	P99Latency    float64 `json:"p99_ms"`
+	MaxLatency    float64 `json:"max_ms"`
+	BytesReceived int64   `json:"bytes_received"`
+}
+
+// Status 运行状态, 在控制API的状态上增加P99延迟
+type Status struct {
+	control.Status
+	P99Latency float64 `json:"p99_ms"`
+}
+
+// Server 浏览器实时仪表盘服务
+//
+// 通过Server-Sent Events推送与realtimeMonitor相同的区间数据,静态资源内嵌,可离线使用。
+type Server struct {
+	addr     string
+	bench    *benchmark.Benchmark
+	server   *http.Server
+	listener net.Listener
+
+	mu      sync.Mutex
+	history []Point
+	clients map[chan []byte]struct{}
+	closed  bool
+
+	done chan struct{}
+}
+
+// NewServer 创建仪表盘服务,须在基准测试Run之前调用
+func NewServer(addr string, bench *benchmark.Benchmark) *Server {
+	s := &Server{
+		addr:    addr,
+		bench:   bench,
+		clients: make(map[chan []byte]struct{}),
+		done:    make(chan struct{}),
+	}
+
+	static, _ := fs.Sub(staticFiles, "static")
+
+	mux := http.NewServeMux()
+	mux.Handle("/", http.FileServer(http.FS(static)))
+	mux.HandleFunc("/api/events", s.handleEvents)
+	mux.Handle("/api/v1/", s.handleControl(http.StripPrefix("/api", control.NewHandler(bench))))
+	s.server = &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	bench.OnInterval(s.publish)
+
+	return s
+}
+
+// Start 启动仪表盘服务(非阻塞)
+func (s *Server) Start() error {
+	lis, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return fmt.Errorf("监听仪表盘地址失败: %w", err)
+	}
+	s.listener = lis
+
+	go func() {
+		if err := s.server.Serve(lis); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fmt.Printf("⚠️  仪表盘服务异常退出: %v\n", err)
+		}
+	}()
+
+	return nil
+}
+
+// Addr 获取实际监听地址
+func (s *Server) Addr() string {
+	if s.listener == nil {
+		return s.addr
+	}
+	return s.listener.Addr().String()
+}
+
+// Close 通知浏览器测试结束并关闭服务
+func (s *Server) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.broadcastLocked("done", s.status())
+	s.closed = true
+	s.mu.Unlock()
+
+	close(s.done)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return s.server.Shutdown(ctx)
+}
+
+// publish 推送采样间隔 (IntervalHandler)
+func (s *Server) publish(point stats.TimePoint) {
+	p := s.point(point)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.history = append(s.history, p)
+	if len(s.history) > historySize {
+		s.history = append(s.history[:0], s.history[len(s.history)-historySize:]...)
+	}
+
+	s.broadcastLocked("interval", map[string]interface{}{
+		"point":  p,
+		"status": s.status(),
+	})
+}
+
+// broadcastLocked 向所有连接发送事件,调用方须持有锁
+func (s *Server) broadcastLocked(event string, data interface{}) {
+	if s.closed {
+		return
+	}
+
+	msg, err := encodeEvent(event, data)
+	if err != nil {
+		return
+	}
+	for client := range s.clients {
+		select {
+		case client <- msg:
+		default:
+			// 浏览器处理过慢,丢弃该事件
+		}
+	}
+}
+
+// point 转换采样数据
+func (s *Server) point(point stats.TimePoint) Point {
+	// 相对测试开始的时间
+	start := time.Now().Add(-s.bench.Elapsed())
+	elapsed := point.Timestamp.Sub(start).Seconds()
+
+	return Point{
+		Timestamp:     point.Timestamp.UnixMilli(),
+		Elapsed:       elapsed,
+		Requests:      point.Requests,
+		Success:       point.Success,
+		Errors:        point.Errors,
+		RPS:           point.RPS,
+		ErrorRate:     point.ErrorRate,
+		Throughput:    point.Throughput,
+		AvgLatency:    millis(point.AvgLatency),
+		P50Latency:    millis(point.P50Latency),
+		P90Latency:    millis(point.P90Latency),
+		P99Latency:    millis(point.P99Latency),
+		MaxLatency:    millis(point.MaxLatency),
+		BytesReceived: point.BytesReceived,
+	}
+}
+
+// status 获取当前运行状态
+func (s *Server) status() Status {
+	return Status{
+		Status:     control.CurrentStatus(s.bench),
+		P99Latency: millis(s.bench.Stats().Snapshot().Latency.P99),
+	}
+}
+
+// handleEvents SSE事件流: 先回放历史,再推送新的区间数据
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	client := make(chan []byte, clientBuffer)
+
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		http.Error(w, "benchmark finished", http.StatusServiceUnavailable)
+		return
+	}
+	history, err := encodeEvent("history", s.history)
+	if err != nil {
+		s.mu.Unlock()
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	s.clients[client] = struct{}{}
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.clients, client)
+		s.mu.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+
+	w.Write(history)
+	if msg, err := encodeEvent("status", s.status()); err == nil {
+		w.Write(msg)
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case msg := <-client:
+			w.Write(msg)
+			flusher.Flush()
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": ping\n\n")
+			flusher.Flush()
+		case <-s.done:
+			// 发送缓冲中剩余的事件 (包括done)
+			for {
+				select {
+				case msg := <-client:
+					w.Write(msg)
+				default:
+					flusher.Flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+// handleControl 挂载控制API (/api/v1/...), 操作成功后向所有页面推送新的状态
+func (s *Server) handleControl(api http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := &statusRecorder{ResponseWriter: w, code: http.StatusOK}
+		api.ServeHTTP(rec, r)
+		if r.Method != http.MethodPost || rec.code < 200 || rec.code >= 300 {
+			return
+		}
+
+		s.mu.Lock()
+		s.broadcastLocked("status", s.status())
+		s.mu.Unlock()
+	})
+}
+
+// statusRecorder 记录控制API响应的状态码
+type statusRecorder struct {
+	http.ResponseWriter
+	code int
+}
+
+// WriteHeader 记录并写出状态码
+func (r *statusRecorder) WriteHeader(code int) {
+	r.code = code
+	r.ResponseWriter.WriteHeader(code)
+}
+
+// encodeEvent 编码SSE事件
+func encodeEvent(event string, data interface{}) ([]byte, error) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(fmt.Sprintf("event: %s\ndata: %s\n\n", event, payload)), nil
+}
+
+// millis 转换为毫秒
+func millis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
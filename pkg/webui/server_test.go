@@ -0,0 +1,198 @@
+package webui
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"httpbench/pkg/benchmark"
+	"httpbench/pkg/config"
+	"httpbench/pkg/stats"
+)
+
+// sseEvent 收到的SSE事件
+type sseEvent struct {
+	name string
+	data string
+}
+
+// subscribe 订阅事件流, 返回按顺序收到的事件, 连接结束时通道关闭
+func subscribe(t *testing.T, url string) <-chan sseEvent {
+	t.Helper()
+	resp, err := http.Get(url + "/api/events")
+	if err != nil {
+		t.Fatalf("订阅事件流失败: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		resp.Body.Close()
+		t.Fatalf("事件流响应: %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+
+	events := make(chan sseEvent, 16)
+	go func() {
+		defer close(events)
+		defer resp.Body.Close()
+		reader := bufio.NewReader(resp.Body)
+		var ev sseEvent
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimSuffix(line, "\n")
+			switch {
+			case line == "":
+				if ev.name != "" {
+					events <- ev
+				}
+				ev = sseEvent{}
+			case strings.HasPrefix(line, "event: "):
+				ev.name = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				ev.data = strings.TrimPrefix(line, "data: ")
+			}
+		}
+	}()
+	return events
+}
+
+// next 等待下一个事件, 并检查事件名
+func next(t *testing.T, events <-chan sseEvent, name string, v interface{}) {
+	t.Helper()
+	select {
+	case ev, ok := <-events:
+		if !ok {
+			t.Fatalf("等待 %s 事件时连接已结束", name)
+		}
+		if ev.name != name {
+			t.Fatalf("收到 %s 事件, 期望 %s: %s", ev.name, name, ev.data)
+		}
+		if err := json.Unmarshal([]byte(ev.data), v); err != nil {
+			t.Fatalf("无效的 %s 事件数据 %q: %v", name, ev.data, err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("等待 %s 事件超时", name)
+	}
+}
+
+// post 通过仪表盘挂载的控制API发送操作, 返回状态码
+func post(t *testing.T, url, path, contentType string) int {
+	t.Helper()
+	resp, err := http.Post(url+path, contentType, strings.NewReader("{}"))
+	if err != nil {
+		t.Fatalf("请求 %s 失败: %v", path, err)
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+// TestEvents 测试历史回放、区间和状态推送、控制API挂载及结束通知
+func TestEvents(t *testing.T) {
+	bench, err := benchmark.New(&config.Config{
+		Target: config.TargetConfig{URL: "http://127.0.0.1:1/"},
+		Load:   config.LoadConfig{Concurrency: 2, Duration: time.Minute},
+	})
+	if err != nil {
+		t.Fatalf("创建基准测试器失败: %v", err)
+	}
+	bench.SetLogOutput(io.Discard)
+	defer bench.Close()
+
+	s := NewServer("127.0.0.1:0", bench)
+	server := httptest.NewServer(s.server.Handler)
+	defer server.Close()
+	defer s.Close()
+
+	// 订阅前的区间在连接时回放
+	for i := 1; i <= 2; i++ {
+		s.publish(stats.TimePoint{
+			Timestamp:  time.Now(),
+			Requests:   int64(10 * i),
+			RPS:        float64(10 * i),
+			P99Latency: time.Duration(i) * time.Millisecond,
+		})
+	}
+	events := subscribe(t, server.URL)
+
+	var history []Point
+	next(t, events, "history", &history)
+	if len(history) != 2 || history[0].Requests != 10 || history[1].P99Latency != 2 {
+		t.Errorf("回放的历史 %+v", history)
+	}
+	var status Status
+	next(t, events, "status", &status)
+	if status.Stage != benchmark.StageIdle || status.Planned != 60 {
+		t.Errorf("初始状态 %+v", status)
+	}
+
+	s.publish(stats.TimePoint{Timestamp: time.Now(), Requests: 30, RPS: 30})
+	var interval struct {
+		Point  Point  `json:"point"`
+		Status Status `json:"status"`
+	}
+	next(t, events, "interval", &interval)
+	if interval.Point.Requests != 30 || interval.Point.RPS != 30 {
+		t.Errorf("区间数据 %+v", interval.Point)
+	}
+
+	// 控制API挂载在 /api/v1/ 下, 成功的操作推送新状态
+	if code := post(t, server.URL, "/api/v1/pause", "application/json"); code != http.StatusOK {
+		t.Fatalf("暂停: 状态码 %d", code)
+	}
+	next(t, events, "status", &status)
+	if !status.Paused {
+		t.Errorf("暂停后的状态 %+v", status)
+	}
+	// 失败的操作不推送状态: 下一个事件来自恢复操作
+	if code := post(t, server.URL, "/api/v1/resume", "text/plain"); code != http.StatusUnsupportedMediaType {
+		t.Errorf("错误的 Content-Type: 状态码 %d", code)
+	}
+	if code := post(t, server.URL, "/api/v1/concurrency", "application/json"); code != http.StatusBadRequest {
+		t.Errorf("无效并发: 状态码 %d", code)
+	}
+	if code := post(t, server.URL, "/api/v1/resume", "application/json"); code != http.StatusOK {
+		t.Fatalf("恢复: 状态码 %d", code)
+	}
+	next(t, events, "status", &status)
+	if status.Paused {
+		t.Errorf("恢复后的状态 %+v", status)
+	}
+
+	resp, err := http.Get(server.URL + "/api/v1/status")
+	if err != nil {
+		t.Fatalf("获取状态失败: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("GET /api/v1/status: 状态码 %d", resp.StatusCode)
+	}
+
+	// 关闭时推送结束事件并结束连接
+	if err := s.Close(); err != nil {
+		t.Fatalf("关闭仪表盘失败: %v", err)
+	}
+	next(t, events, "done", &status)
+	select {
+	case ev, ok := <-events:
+		if ok {
+			t.Errorf("结束后仍收到 %s 事件", ev.name)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("结束后连接未关闭")
+	}
+
+	resp, err = http.Get(server.URL + "/api/events")
+	if err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("结束后订阅: 状态码 %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,237 @@
+// httpbench 实时仪表盘: 通过SSE接收区间数据并用canvas绘制折线图, 无外部依赖
+(function () {
+  "use strict";
+
+  var MAX_POINTS = 600;
+  var points = [];
+
+  var charts = [
+    {
+      id: "chart-rps",
+      series: [{ key: "rps", color: "#4aa3ff", label: "RPS" }]
+    },
+    {
+      id: "chart-latency",
+      series: [
+        { key: "avg_ms", color: "#8a93a3", label: "avg" },
+        { key: "p50_ms", color: "#3ecf8e", label: "p50" },
+        { key: "p90_ms", color: "#f5b83d", label: "p90" },
+        { key: "p99_ms", color: "#ff5c5c", label: "p99" }
+      ]
+    },
+    {
+      id: "chart-errors",
+      series: [{ key: "error_pct", color: "#ff5c5c", label: "错误率" }]
+    },
+    {
+      id: "chart-throughput",
+      series: [{ key: "throughput_kbs", color: "#b28dff", label: "KB/s" }]
+    }
+  ];
+
+  function $(id) { return document.getElementById(id); }
+
+  function normalize(p) {
+    p.error_pct = p.error_rate * 100;
+    p.throughput_kbs = p.throughput_bps / 1024;
+    return p;
+  }
+
+  function addPoints(list) {
+    for (var i = 0; i < list.length; i++) {
+      points.push(normalize(list[i]));
+    }
+    if (points.length > MAX_POINTS) {
+      points = points.slice(points.length - MAX_POINTS);
+    }
+  }
+
+  function formatClock(seconds) {
+    seconds = Math.max(0, Math.round(seconds));
+    var h = Math.floor(seconds / 3600);
+    var m = Math.floor(seconds % 3600 / 60);
+    var s = seconds % 60;
+    var mm = (m < 10 ? "0" : "") + m;
+    var ss = (s < 10 ? "0" : "") + s;
+    return h > 0 ? h + ":" + mm + ":" + ss : mm + ":" + ss;
+  }
+
+  function formatNumber(v, digits) {
+    if (v >= 1e6) return (v / 1e6).toFixed(2) + "M";
+    if (v >= 1e4) return (v / 1e3).toFixed(1) + "k";
+    return v.toFixed(digits);
+  }
+
+  function drawChart(chart) {
+    var canvas = $(chart.id);
+    var ratio = window.devicePixelRatio || 1;
+    var width = canvas.clientWidth;
+    var height = canvas.clientHeight;
+    if (canvas.width !== width * ratio || canvas.height !== height * ratio) {
+      canvas.width = width * ratio;
+      canvas.height = height * ratio;
+    }
+
+    var ctx = canvas.getContext("2d");
+    ctx.setTransform(ratio, 0, 0, ratio, 0, 0);
+    ctx.clearRect(0, 0, width, height);
+
+    var left = 48, right = 8, top = 18, bottom = 20;
+    var plotW = width - left - right;
+    var plotH = height - top - bottom;
+
+    var max = 0;
+    points.forEach(function (p) {
+      chart.series.forEach(function (s) { max = Math.max(max, p[s.key] || 0); });
+    });
+    max = max > 0 ? max * 1.1 : 1;
+
+    // 网格与纵轴刻度
+    ctx.strokeStyle = "#2c3542";
+    ctx.fillStyle = "#8a93a3";
+    ctx.font = "11px sans-serif";
+    ctx.lineWidth = 1;
+    for (var i = 0; i <= 4; i++) {
+      var y = top + plotH - plotH * i / 4;
+      ctx.beginPath();
+      ctx.moveTo(left, y);
+      ctx.lineTo(left + plotW, y);
+      ctx.stroke();
+      ctx.fillText(formatNumber(max * i / 4, max < 10 ? 2 : 0), 4, y + 4);
+    }
+
+    // 横轴: 首尾时间
+    if (points.length > 0) {
+      ctx.fillText(formatClock(points[0].elapsed_s), left, height - 4);
+      var lastLabel = formatClock(points[points.length - 1].elapsed_s);
+      ctx.fillText(lastLabel, left + plotW - ctx.measureText(lastLabel).width, height - 4);
+    }
+
+    // 图例
+    var legendX = left;
+    chart.series.forEach(function (s) {
+      ctx.fillStyle = s.color;
+      ctx.fillRect(legendX, 4, 10, 10);
+      ctx.fillStyle = "#e6e9ef";
+      ctx.fillText(s.label, legendX + 14, 13);
+      legendX += ctx.measureText(s.label).width + 32;
+    });
+
+    if (points.length < 2) return;
+
+    var step = plotW / (points.length - 1);
+    chart.series.forEach(function (s) {
+      ctx.strokeStyle = s.color;
+      ctx.lineWidth = 1.5;
+      ctx.beginPath();
+      points.forEach(function (p, i) {
+        var x = left + i * step;
+        var y = top + plotH - (p[s.key] || 0) / max * plotH;
+        if (i === 0) ctx.moveTo(x, y); else ctx.lineTo(x, y);
+      });
+      ctx.stroke();
+    });
+  }
+
+  function drawAll() {
+    charts.forEach(drawChart);
+  }
+
+  function updateStatus(status) {
+    $("endpoint").textContent = status.endpoint;
+    $("stage").textContent = status.stage;
+
+    var elapsed = formatClock(status.elapsed_s);
+    if (status.planned_s > 0) elapsed += " / " + formatClock(status.planned_s);
+    $("elapsed").textContent = elapsed;
+
+    $("total").textContent = status.total_requests;
+    var rate = status.total_requests > 0 ? status.success_requests / status.total_requests * 100 : 0;
+    $("success-rate").textContent = rate.toFixed(2) + "%";
+    $("p99").textContent = status.p99_ms.toFixed(2) + " ms";
+    $("workers").textContent = status.active_workers;
+    $("inflight").textContent = status.in_flight;
+    $("target-rate").textContent = status.target_rate > 0 ? status.target_rate + " req/s" : "不限速";
+
+    var state = $("state");
+    if (status.done) {
+      state.textContent = "已结束";
+      state.className = "badge done";
+    } else if (status.paused) {
+      state.textContent = "已暂停";
+      state.className = "badge paused";
+    } else {
+      state.textContent = "运行中";
+      state.className = "badge running";
+    }
+
+    $("btn-pause").disabled = status.done || status.paused;
+    $("btn-resume").disabled = status.done || !status.paused;
+    $("btn-stop").disabled = status.done;
+  }
+
+  // 控制API与 -control-addr 相同; 操作后的状态通过事件流推送
+  function control(action, body) {
+    return fetch("api/v1/" + action, {
+      method: "POST",
+      headers: { "Content-Type": "application/json" },
+      body: JSON.stringify(body || {})
+    }).then(function (resp) {
+      if (!resp.ok) {
+        return resp.json().then(function (data) { throw new Error(data.error); });
+      }
+      $("message").textContent = "";
+    }).catch(function (err) {
+      $("message").textContent = "操作失败: " + err.message;
+    });
+  }
+
+  document.querySelectorAll("button[data-action]").forEach(function (btn) {
+    btn.addEventListener("click", function () {
+      if (btn.dataset.action === "stop" && !confirm("停止测试? 进行中的请求会完成后结束。")) return;
+      control(btn.dataset.action);
+    });
+  });
+
+  $("rate-form").addEventListener("submit", function (e) {
+    e.preventDefault();
+    control("rate", { rate: parseInt($("rate").value, 10) || 0 });
+  });
+
+  var source = new EventSource("api/events");
+
+  source.addEventListener("history", function (e) {
+    points = [];
+    addPoints(JSON.parse(e.data) || []);
+    drawAll();
+  });
+
+  source.addEventListener("status", function (e) {
+    updateStatus(JSON.parse(e.data));
+  });
+
+  source.addEventListener("interval", function (e) {
+    var msg = JSON.parse(e.data);
+    addPoints([msg.point]);
+    $("rps").textContent = msg.point.rps.toFixed(1);
+    updateStatus(msg.status);
+    drawAll();
+  });
+
+  source.addEventListener("done", function (e) {
+    updateStatus(JSON.parse(e.data));
+    source.close();
+  });
+
+  source.onerror = function () {
+    if (source.readyState === EventSource.CLOSED) {
+      var state = $("state");
+      if (state.className.indexOf("done") < 0) {
+        state.textContent = "已断开";
+        state.className = "badge offline";
+      }
+    }
+  };
+
+  window.addEventListener("resize", drawAll);
+})();
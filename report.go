@@ -0,0 +1,60 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"httpbench/pkg/benchmark"
+	"httpbench/pkg/rawlog"
+	"httpbench/pkg/reporter"
+)
+
+// runReport 根据原始结果文件重新生成报告
+//
+//	httpbench report -output json -report report.json results.bin
+func runReport(args []string) error {
+	fs := flag.NewFlagSet("report", flag.ExitOnError)
+	input := fs.String("input", "", "原始结果文件 (csv, ndjson 或 binary)")
+	format := fs.String("output", "console", "输出格式: console, json, csv, junit, markdown")
+	reportPath := fs.String("report", "", "报告输出文件")
+	interval := fs.Duration("interval", time.Second, "重建时间序列的采样间隔(0表示不重建)")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "用法: httpbench report [选项] <原始结果文件>\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	path := *input
+	if path == "" {
+		path = fs.Arg(0)
+	}
+	if path == "" {
+		fs.Usage()
+		return fmt.Errorf("缺少原始结果文件")
+	}
+
+	reader, err := rawlog.Open(path)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	collector, duration, err := rawlog.Rebuild(reader, *interval)
+	if err != nil {
+		return fmt.Errorf("重建统计失败: %w", err)
+	}
+	results := benchmark.ResultsFromCollector(collector, duration)
+
+	fmt.Printf("📂 已加载 %s (%s, %d 条记录)\n\n", path, reader.Format(), results.TotalRequests)
+
+	rep := reporter.New(*format)
+	if err := rep.Generate(results, *reportPath); err != nil {
+		return fmt.Errorf("生成报告失败: %w", err)
+	}
+	if *format == "console" || *reportPath != "" {
+		printSummary(results)
+	}
+
+	return nil
+}
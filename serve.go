@@ -0,0 +1,116 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"httpbench/pkg/mockserver"
+)
+
+// runServe 启动内置模拟目标服务
+//
+//	httpbench serve -addr :8080 -latency 20ms -error-rate 0.01
+//	httpbench serve -config mock.yaml
+func runServe(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	configPath := fs.String("config", "", "路由配置文件(YAML)")
+	addr := fs.String("addr", ":8080", "HTTP/1.1 和 h2c 监听地址(空表示不监听)")
+	tlsAddr := fs.String("tls-addr", "", "HTTP/1.1 和 HTTP/2 over TLS 监听地址(如 :8443)")
+	http3Addr := fs.String("http3-addr", "", "HTTP/3 监听地址(UDP, 如 :8443)")
+	certFile := fs.String("cert", "", "证书文件(为空时生成自签名证书)")
+	keyFile := fs.String("key", "", "私钥文件")
+	latency := fs.Duration("latency", 0, "默认路由的固定延迟")
+	status := fs.Int("status", 200, "默认路由的状态码")
+	size := fs.Int("size", 0, "默认路由的响应体大小(字节)")
+	errorRate := fs.Float64("error-rate", 0, "默认路由返回500的比例(0-1)")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "用法: httpbench serve [选项]\n\n")
+		fmt.Fprintf(fs.Output(), "未指定 -config 时提供单个 \"/\" 路由, 行为由 -latency/-status/-size/-error-rate 决定。\n")
+		fmt.Fprintf(fs.Output(), "任意路由均支持查询参数临时覆盖: ?latency=50ms&status=503&size=1024\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	var cfg *mockserver.Config
+	if *configPath != "" {
+		var err error
+		cfg, err = mockserver.LoadFromFile(*configPath)
+		if err != nil {
+			return err
+		}
+	} else {
+		cfg = mockserver.NewDefault()
+		route := &cfg.Routes[0]
+		route.Latency.Mean = *latency
+		route.Status = *status
+		route.Size = *size
+		if *errorRate > 0 {
+			route.Errors = []mockserver.ErrorRate{{Status: 500, Rate: *errorRate}}
+		}
+	}
+
+	// 显式指定的监听选项覆盖配置文件
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "addr":
+			cfg.Addr = *addr
+		case "tls-addr":
+			cfg.TLSAddr = *tlsAddr
+		case "http3-addr":
+			cfg.HTTP3Addr = *http3Addr
+		case "cert":
+			cfg.CertFile = *certFile
+		case "key":
+			cfg.KeyFile = *keyFile
+		}
+	})
+
+	server := mockserver.NewServer(cfg)
+	if err := server.Start(); err != nil {
+		return err
+	}
+
+	fmt.Printf("🎯 模拟目标服务已启动\n")
+	fmt.Printf("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+	if cfg.Addr != "" {
+		fmt.Printf("HTTP/1.1 + h2c:   http://%s\n", server.Addr())
+	}
+	if cfg.TLSAddr != "" {
+		fmt.Printf("HTTPS (HTTP/2):   https://%s\n", server.TLSAddr())
+	}
+	if cfg.HTTP3Addr != "" {
+		fmt.Printf("HTTP/3 (QUIC):    https://%s\n", server.HTTP3Addr())
+	}
+	if cfg.CertFile == "" && (cfg.TLSAddr != "" || cfg.HTTP3Addr != "") {
+		fmt.Printf("⚠️  使用自签名证书, 客户端需设置 tls.insecure_skip_verify\n")
+	}
+	for _, route := range cfg.Routes {
+		fmt.Printf("  %-6s %s\n", routeMethod(route.Method), route.Path)
+	}
+	fmt.Printf("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	start := time.Now()
+	<-sigChan
+
+	fmt.Printf("\n📊 运行 %v, 各路由请求统计:\n", time.Since(start).Round(time.Second))
+	for _, rs := range server.Stats() {
+		fmt.Printf("  %-6s %-30s 请求 %d, 错误 %d, 断开 %d\n",
+			routeMethod(rs.Method), rs.Path, rs.Requests, rs.Errors, rs.Drops)
+	}
+
+	return server.Close()
+}
+
+// routeMethod 路由方法显示名称
+func routeMethod(method string) string {
+	if method == "" {
+		return "*"
+	}
+	return method
+}
@@ -0,0 +1,11 @@
+//go:build !windows
+
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+// snapshotSignals 触发中间快照的信号
+var snapshotSignals = []os.Signal{syscall.SIGUSR1}
@@ -0,0 +1,8 @@
+//go:build windows
+
+package main
+
+import "os"
+
+// snapshotSignals 触发中间快照的信号 (Windows 不支持 SIGUSR1)
+var snapshotSignals []os.Signal